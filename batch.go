@@ -0,0 +1,121 @@
+package drum
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// BatchOptions configures DecodeAll and DecodeDir
+type BatchOptions struct {
+	// Concurrency is the number of files decoded at the same time, the
+	// number of CPUs if 0
+	Concurrency int
+}
+
+// BatchResult is the result of decoding a single file with DecodeAll
+type BatchResult struct {
+	Path    string
+	Pattern *Pattern
+	Err     error
+}
+
+// DecodeAll decodes the files at paths in a pool of workers and sends the
+// results on the returned channel, in the order the files are done. The
+// channel is closed when all files are decoded or ctx is done.
+func DecodeAll(ctx context.Context, paths []string, opts BatchOptions) <-chan BatchResult {
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan string)
+	results := make(chan BatchResult)
+	go func() {
+		defer close(jobs)
+		for _, path := range paths {
+			select {
+			case jobs <- path:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				pattern, err := DecodeFile(path)
+				select {
+				case results <- BatchResult{path, pattern, err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// DecodeDir decodes all .splice files in the directory at path and its
+// subdirectories, see DecodeDirContext.
+func DecodeDir(path string, opts BatchOptions) ([]*Pattern, []error) {
+	return DecodeDirContext(context.Background(), path, opts)
+}
+
+// DecodeDirContext decodes all .splice files in the directory at path and
+// its subdirectories using DecodeAll. The patterns are returned in the
+// lexical order of their paths. Files which can't be decoded don't stop the
+// others, their errors are returned instead in the same order. If ctx is
+// done before all files are decoded, ctx.Err() is part of the errors.
+func DecodeDirContext(ctx context.Context, path string, opts BatchOptions) ([]*Pattern, []error) {
+	var paths []string
+	var errs []error
+	err := filepath.WalkDir(path, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(path), ".splice") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	results := make(map[string]BatchResult)
+	for result := range DecodeAll(ctx, paths, opts) {
+		results[result.Path] = result
+	}
+
+	var patterns []*Pattern
+	for _, path := range paths {
+		result, ok := results[path]
+		switch {
+		case !ok:
+		case result.Err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", path, result.Err))
+		default:
+			patterns = append(patterns, result.Pattern)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return patterns, errs
+}
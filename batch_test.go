@@ -0,0 +1,75 @@
+package drum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecodeDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, pattern := range fixtures(t) {
+		if err := EncodeFile(filepath.Join(dir, name), pattern); err != nil {
+			t.Fatalf("something went wrong encoding %s - %v", name, err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "broken"), 0755); err != nil {
+		t.Fatalf("something went wrong creating a directory - %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken", "corrupt.splice"), []byte("SPLISE"), 0644); err != nil {
+		t.Fatalf("something went wrong writing - %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a pattern"), 0644); err != nil {
+		t.Fatalf("something went wrong writing - %v", err)
+	}
+
+	for _, concurrency := range []int{0, 1, 3} {
+		patterns, errs := DecodeDir(dir, BatchOptions{Concurrency: concurrency})
+		if len(patterns) != 5 {
+			t.Fatalf("expected 5 patterns, got %d", len(patterns))
+		}
+		if len(errs) != 1 || !errors.Is(errs[0], ErrBadHeader) {
+			t.Fatalf("expected an ErrBadHeader error, got %v", errs)
+		}
+
+		// broken/corrupt.splice sorts first, so the patterns are in fixture order
+		expected := fixtures(t)
+		for i, pattern := range patterns {
+			name := fmt.Sprintf("pattern_%d.splice", i+1)
+			if pattern.String() != expected[name].String() {
+				t.Fatalf("expected %s at position %d, got:\n%s", name, i, pattern)
+			}
+		}
+	}
+}
+
+func TestDecodeDirCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, errs := DecodeDirContext(ctx, "fixtures", BatchOptions{})
+	if len(errs) == 0 || !errors.Is(errs[len(errs)-1], context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", errs)
+	}
+}
+
+func TestDecodeAll(t *testing.T) {
+	paths := []string{
+		filepath.Join("fixtures", "pattern_1.splice"),
+		filepath.Join("fixtures", "missing.splice"),
+	}
+
+	results := make(map[string]BatchResult)
+	for result := range DecodeAll(context.Background(), paths, BatchOptions{Concurrency: 2}) {
+		results[result.Path] = result
+	}
+	if results[paths[0]].Err != nil || results[paths[0]].Pattern.Tempo != 120 {
+		t.Fatalf("unexpected result %+v", results[paths[0]])
+	}
+	if !errors.Is(results[paths[1]].Err, os.ErrNotExist) {
+		t.Fatalf("expected os.ErrNotExist, got %v", results[paths[1]].Err)
+	}
+}
@@ -0,0 +1,146 @@
+package drum
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PatternBuilder constructs a pattern using method chaining. Errors are
+// collected along the way and returned by Build.
+type PatternBuilder struct {
+	version string
+	tempo   float32
+	tracks  []*Track
+	errs    []error
+}
+
+// NewPattern returns a PatternBuilder for an empty pattern
+func NewPattern() *PatternBuilder {
+	return &PatternBuilder{}
+}
+
+// WithVersion sets the version of the pattern
+func (b *PatternBuilder) WithVersion(version string) *PatternBuilder {
+	b.version = version
+	return b
+}
+
+// WithTempo sets the tempo of the pattern
+func (b *PatternBuilder) WithTempo(tempo float32) *PatternBuilder {
+	b.tempo = tempo
+	return b
+}
+
+// AddTrack appends a track to the pattern
+func (b *PatternBuilder) AddTrack(track *Track) *PatternBuilder {
+	if track == nil {
+		b.errs = append(b.errs, ErrNilTrack)
+		return b
+	}
+	for _, existing := range b.tracks {
+		if existing.ID == track.ID {
+			b.errs = append(b.errs, fmt.Errorf("%w: %d", ErrDuplicateTrackID, track.ID))
+			return b
+		}
+	}
+
+	b.tracks = append(b.tracks, track)
+	return b
+}
+
+// WithTrack appends a track with the given name and the lowest ID which is
+// not used by the tracks before it. The steps are parsed from a string like
+// "x---x---x---x---" holding 16, 32 or 64 steps.
+func (b *PatternBuilder) WithTrack(name, steps string) *PatternBuilder {
+	values, err := parseStepSlice(steps)
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+
+	track := &Track{ID: b.nextID(), Name: name}
+	if err := track.SetLength(len(values)); err != nil {
+		b.errs = append(b.errs, fmt.Errorf("%w: track %q", err, name))
+		return b
+	}
+	track.setAllSteps(values)
+
+	return b.AddTrack(track)
+}
+
+// nextID returns the lowest ID which is not used by the tracks
+func (b *PatternBuilder) nextID() int {
+	ids := make(map[int]bool)
+	for _, track := range b.tracks {
+		ids[track.ID] = true
+	}
+
+	return freeID(ids)
+}
+
+// AddBuiltTrack builds the track of tb and appends it to the pattern
+func (b *PatternBuilder) AddBuiltTrack(tb *TrackBuilder) *PatternBuilder {
+	track, err := tb.Build()
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+
+	return b.AddTrack(track)
+}
+
+// Build returns the constructed pattern, or the errors which occurred while
+// building it.
+func (b *PatternBuilder) Build() (*Pattern, error) {
+	if len(b.errs) > 0 {
+		return nil, errors.Join(b.errs...)
+	}
+
+	return NewPatternFromTracks(b.version, b.tempo, b.tracks...)
+}
+
+// TrackBuilder constructs a track using method chaining. Errors are collected
+// along the way and returned by Build.
+type TrackBuilder struct {
+	track Track
+	errs  []error
+}
+
+// NewTrackBuilder returns a TrackBuilder for a track with the given ID and
+// name and no active steps.
+func NewTrackBuilder(id int, name string) *TrackBuilder {
+	return &TrackBuilder{track: Track{ID: id, Name: name}}
+}
+
+// WithStep sets the step at index i
+func (b *TrackBuilder) WithStep(i int, on bool) *TrackBuilder {
+	if i < 0 || i >= len(b.track.Steps) {
+		b.errs = append(b.errs, fmt.Errorf("%w: %d", ErrStepOutOfRange, i))
+		return b
+	}
+
+	b.track.Steps[i] = on
+	return b
+}
+
+// WithStepsFromString sets all steps from a string like "x---x---x---x---"
+func (b *TrackBuilder) WithStepsFromString(s string) *TrackBuilder {
+	steps, err := parseSteps(s)
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+
+	b.track.Steps = steps
+	return b
+}
+
+// Build returns the constructed track, or the errors which occurred while
+// building it.
+func (b *TrackBuilder) Build() (*Track, error) {
+	if len(b.errs) > 0 {
+		return nil, errors.Join(b.errs...)
+	}
+
+	return NewTrack(b.track.ID, b.track.Name, b.track.Steps)
+}
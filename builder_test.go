@@ -0,0 +1,79 @@
+package drum
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPatternBuilder(t *testing.T) {
+	kick := &Track{ID: 1, Name: "kick", Steps: [16]bool{0: true, 8: true}}
+	snare := &Track{ID: 2, Name: "snare", Steps: [16]bool{4: true, 12: true}}
+
+	built, err := NewPattern().WithVersion("0.808-alpha").WithTempo(120).AddTrack(kick).AddTrack(snare).Build()
+	if err != nil {
+		t.Fatalf("something went wrong building - %v", err)
+	}
+	expected, err := NewPatternFromTracks("0.808-alpha", 120, kick, snare)
+	if err != nil {
+		t.Fatalf("something went wrong creating the pattern - %v", err)
+	}
+	if built.String() != expected.String() {
+		t.Fatalf("built pattern doesn't match.\nGot:\n%s\nExpected:\n%s", built, expected)
+	}
+
+	_, err = NewPattern().WithVersion("0.808-alpha").WithTempo(120).AddTrack(kick).AddTrack(kick).AddTrack(nil).Build()
+	if !errors.Is(err, ErrDuplicateTrackID) || !errors.Is(err, ErrNilTrack) {
+		t.Fatalf("expected ErrDuplicateTrackID and ErrNilTrack, got %v", err)
+	}
+}
+
+func TestPatternBuilderWithTrack(t *testing.T) {
+	pattern, err := NewPattern().WithVersion("0.808-alpha").WithTempo(120).
+		WithTrack("kick", "x---x---x---x---").
+		AddTrack(&Track{ID: 1, Name: "snare", Steps: [16]bool{4: true, 12: true}}).
+		WithTrack("hh-open", "--x---x---x---x-").
+		Build()
+	if err != nil {
+		t.Fatalf("something went wrong building - %v", err)
+	}
+	expected := "Saved with HW Version: 0.808-alpha\nTempo: 120\n" +
+		"(0) kick\t|x---|x---|x---|x---|\n" +
+		"(1) snare\t|----|x---|----|x---|\n" +
+		"(2) hh-open\t|--x-|--x-|--x-|--x-|\n"
+	if pattern.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, pattern)
+	}
+
+	_, err = NewPattern().WithTempo(120).WithTrack("kick", "x---x---").WithTrack("snare", "x?").Build()
+	if !errors.Is(err, ErrInvalidStepCount) || !errors.Is(err, ErrInvalidSteps) {
+		t.Fatalf("expected ErrInvalidStepCount and ErrInvalidSteps, got %v", err)
+	}
+}
+
+func TestTrackBuilder(t *testing.T) {
+	track, err := NewTrackBuilder(1, "kick").WithStepsFromString("x---|x---|x---|x---").WithStep(2, true).WithStep(4, false).Build()
+	if err != nil {
+		t.Fatalf("something went wrong building - %v", err)
+	}
+	if expected := [16]bool{0: true, 2: true, 8: true, 12: true}; track.Steps != expected {
+		t.Fatalf("expected steps %v, got %v", expected, track.Steps)
+	}
+
+	if _, err := NewTrackBuilder(1, "kick").WithStep(16, true).Build(); !errors.Is(err, ErrStepOutOfRange) {
+		t.Fatalf("expected ErrStepOutOfRange, got %v", err)
+	}
+	if _, err := NewTrackBuilder(1, "kick").WithStepsFromString("x---").Build(); !errors.Is(err, ErrInvalidSteps) {
+		t.Fatalf("expected ErrInvalidSteps, got %v", err)
+	}
+	if _, err := NewTrackBuilder(-1, "kick").Build(); err != ErrInvalidTrackID {
+		t.Fatalf("expected ErrInvalidTrackID, got %v", err)
+	}
+
+	pattern, err := NewPattern().WithVersion("0.808-alpha").WithTempo(120).
+		AddBuiltTrack(NewTrackBuilder(1, "kick").WithStepsFromString("x---x---x---x---")).
+		AddBuiltTrack(NewTrackBuilder(2, "").WithStep(4, true)).
+		Build()
+	if !errors.Is(err, ErrEmptyTrackName) {
+		t.Fatalf("expected ErrEmptyTrackName, got %v (%v)", err, pattern)
+	}
+}
@@ -0,0 +1,264 @@
+// Command splicectl inspects, converts, merges, plays and compiles .splice
+// drum machine files.
+//
+// Usage:
+//
+//	splicectl show pattern.splice
+//	splicectl convert -to json|midi [-o output] pattern.splice
+//	splicectl merge [-o output] a.splice b.splice...
+//	splicectl play [-bars n] pattern.splice
+//	splicectl compile [-o output] pattern.txt
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	drum "github.com/arjandepooter/gochallenge-1"
+)
+
+const usage = `usage: splicectl <command> [flags] files...
+
+commands:
+  show     print the patterns in the familiar text rendering
+  convert  convert a pattern to json or midi
+  merge    merge the tracks of several patterns into one .splice file
+  play     play a pattern in the terminal
+  compile  compile a text pattern into a .splice file
+`
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	err := run(os.Args[1:], os.Stdout)
+	if errors.Is(err, errUsage) {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "splicectl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command in args, writing its output to stdout
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	commands := map[string]func([]string, io.Writer) error{
+		"show":    show,
+		"convert": convert,
+		"merge":   merge,
+		"play":    play,
+		"compile": compile,
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+
+	return command(args[1:], stdout)
+}
+
+// parseFlags parses the flags of a command and checks the number of files,
+// maxFiles 0 means unlimited.
+func parseFlags(flags *flag.FlagSet, args []string, minFiles, maxFiles int) error {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	n := flags.NArg()
+	if n < minFiles {
+		return fmt.Errorf("%w: %s expects at least %d files, got %d", errUsage, flags.Name(), minFiles, n)
+	}
+	if maxFiles > 0 && n > maxFiles {
+		return fmt.Errorf("%w: %s expects at most %d files, got %d", errUsage, flags.Name(), maxFiles, n)
+	}
+
+	return nil
+}
+
+// output opens the file at path for writing, or returns stdout if path is
+// empty.
+func output(path string, stdout io.Writer) (io.Writer, func() error, error) {
+	if path == "" {
+		return stdout, func() error { return nil }, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+func show(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("show", flag.ContinueOnError)
+	if err := parseFlags(flags, args, 1, 0); err != nil {
+		return err
+	}
+
+	for i, path := range flags.Args() {
+		pattern, err := drum.DecodeFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		fmt.Fprint(stdout, pattern)
+	}
+
+	return nil
+}
+
+func convert(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	to := flags.String("to", "json", "output format, json or midi")
+	path := flags.String("o", "", "output file, stdout if empty")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	pattern, err := drum.DecodeFile(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%s: %w", flags.Arg(0), err)
+	}
+
+	var data []byte
+	switch strings.ToLower(*to) {
+	case "json":
+		data, err = json.MarshalIndent(pattern, "", "  ")
+		data = append(data, '\n')
+	case "midi":
+		buf := new(bytes.Buffer)
+		err = pattern.ExportMIDI(buf, drum.MIDIOptions{Format: 1})
+		data = buf.Bytes()
+	default:
+		return fmt.Errorf("%w: unknown format %q", errUsage, *to)
+	}
+	if err != nil {
+		return err
+	}
+
+	w, closeOutput, err := output(*path, stdout)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		closeOutput()
+		return err
+	}
+	return closeOutput()
+}
+
+func merge(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("merge", flag.ContinueOnError)
+	path := flags.String("o", "", "output file, stdout if empty")
+	if err := parseFlags(flags, args, 2, 0); err != nil {
+		return err
+	}
+
+	var patterns []*drum.Pattern
+	for _, path := range flags.Args() {
+		pattern, err := drum.DecodeFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	merged, err := drum.Merge(patterns...)
+	if err != nil {
+		return err
+	}
+
+	w, closeOutput, err := output(*path, stdout)
+	if err != nil {
+		return err
+	}
+	if err := drum.Encode(merged, w); err != nil {
+		closeOutput()
+		return err
+	}
+	return closeOutput()
+}
+
+func play(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("play", flag.ContinueOnError)
+	bars := flags.Int("bars", 4, "number of bars to play")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+	if *bars < 1 {
+		return fmt.Errorf("%w: bars must be positive", errUsage)
+	}
+
+	pattern, err := drum.DecodeFile(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%s: %w", flags.Arg(0), err)
+	}
+	fmt.Fprint(stdout, pattern)
+
+	// Without an audio backend every step is printed with the tracks it
+	// triggers
+	steps := pattern.Length()
+	played := 0
+	done := make(chan struct{})
+	player := drum.NewPlayer(pattern)
+	player.OnStep = func(step int) {
+		if played == *bars*steps {
+			return
+		}
+		var names []string
+		for _, track := range pattern.Tracks {
+			if track.AllSteps()[step] {
+				names = append(names, track.Name)
+			}
+		}
+		fmt.Fprintln(stdout, strings.TrimSpace(fmt.Sprintf("%02d %s", step+1, strings.Join(names, " "))))
+
+		played++
+		if played == *bars*steps {
+			close(done)
+		}
+	}
+
+	if err := player.Start(); err != nil {
+		return err
+	}
+	<-done
+	player.Stop()
+
+	return nil
+}
+
+func compile(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("compile", flag.ContinueOnError)
+	path := flags.String("o", "", "output file, stdout if empty")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	pattern, err := drum.ParseTextFile(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%s: %w", flags.Arg(0), err)
+	}
+
+	w, closeOutput, err := output(*path, stdout)
+	if err != nil {
+		return err
+	}
+	if err := drum.Encode(pattern, w); err != nil {
+		closeOutput()
+		return err
+	}
+	return closeOutput()
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	drum "github.com/arjandepooter/gochallenge-1"
+)
+
+func fixture(name string) string {
+	return path.Join("..", "..", "fixtures", name)
+}
+
+func TestShow(t *testing.T) {
+	var buf bytes.Buffer
+	if err := run([]string{"show", fixture("pattern_1.splice")}, &buf); err != nil {
+		t.Fatalf("something went wrong showing - %v", err)
+	}
+
+	pattern, err := drum.DecodeFile(fixture("pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding - %v", err)
+	}
+	if buf.String() != pattern.String() {
+		t.Fatalf("expected:\n%s\ngot:\n%s", pattern, buf.String())
+	}
+}
+
+func TestConvert(t *testing.T) {
+	var buf bytes.Buffer
+	if err := run([]string{"convert", "-to", "json", fixture("pattern_2.splice")}, &buf); err != nil {
+		t.Fatalf("something went wrong converting - %v", err)
+	}
+	var pattern drum.Pattern
+	if err := json.Unmarshal(buf.Bytes(), &pattern); err != nil {
+		t.Fatalf("invalid JSON output - %v", err)
+	}
+	if pattern.Tempo != 98.4 || len(pattern.Tracks) != 4 {
+		t.Fatalf("unexpected pattern:\n%s", &pattern)
+	}
+
+	buf.Reset()
+	if err := run([]string{"convert", "-to", "midi", fixture("pattern_2.splice")}, &buf); err != nil {
+		t.Fatalf("something went wrong converting - %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("MThd")) {
+		t.Fatalf("expected a MIDI file, got %q", buf.Bytes()[:4])
+	}
+
+	if err := run([]string{"convert", "-to", "wav", fixture("pattern_2.splice")}, &buf); !errors.Is(err, errUsage) {
+		t.Fatalf("expected errUsage, got %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	var buf bytes.Buffer
+	if err := run([]string{"merge", fixture("pattern_2.splice"), fixture("pattern_4.splice")}, &buf); err != nil {
+		t.Fatalf("something went wrong merging - %v", err)
+	}
+
+	merged, err := drum.Decode(&buf)
+	if err != nil {
+		t.Fatalf("something went wrong decoding the merged pattern - %v", err)
+	}
+	if merged.Tempo != 98.4 || merged.FindTrackByID(255) == nil {
+		t.Fatalf("unexpected merged pattern:\n%s", merged)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	var buf bytes.Buffer
+	if err := run([]string{"play", "-bars", "1", fixture("pattern_4.splice")}, &buf); err != nil {
+		t.Fatalf("something went wrong playing - %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if last := lines[len(lines)-1]; !strings.HasPrefix(last, "16") {
+		t.Fatalf("expected the last line to be step 16, got %q", last)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	pattern, err := drum.DecodeFile(fixture("pattern_3.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding - %v", err)
+	}
+	source := path.Join(t.TempDir(), "pattern.txt")
+	if err := os.WriteFile(source, []byte(pattern.String()), 0644); err != nil {
+		t.Fatalf("something went wrong writing - %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := run([]string{"compile", source}, &buf); err != nil {
+		t.Fatalf("something went wrong compiling - %v", err)
+	}
+	compiled, err := drum.Decode(&buf)
+	if err != nil {
+		t.Fatalf("something went wrong decoding the compiled pattern - %v", err)
+	}
+	if !drum.Equal(pattern, compiled) {
+		t.Fatalf("compiled pattern differs:\n%s", drum.Diff(pattern, compiled))
+	}
+}
+
+func TestUsage(t *testing.T) {
+	for _, args := range [][]string{nil, {"bogus"}, {"show"}, {"merge", "a.splice"}, {"play", "-bars", "0", "a.splice"}, {"compile"}} {
+		if err := run(args, &bytes.Buffer{}); !errors.Is(err, errUsage) {
+			t.Fatalf("run(%q): expected errUsage, got %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+// Codec reads and writes the tracks of the .splice files with some versions.
+// The header, content size, version and tempo are the same for all versions.
+type Codec interface {
+	// Name identifies the codec, like "classic"
+	Name() string
+	// Match returns whether the codec handles patterns with the version
+	Match(version string) bool
+	// ReadTracks reads the tracks from r, which holds size bytes of content
+	// after the tempo
+	ReadTracks(r io.Reader, size int64) ([]*Track, error)
+	// WriteTracks writes the tracks of the pattern
+	WriteTracks(w io.Writer, pattern *Pattern) error
+}
+
+var codecs struct {
+	mu         sync.RWMutex
+	registered []Codec
+}
+
+// RegisterCodec registers a codec for the versions it matches. Codecs
+// registered later take precedence over earlier ones and over the built-in
+// classic and extended codecs, so a vendor specific variant can be
+// supported without changing the decoder. It is meant to be called from an
+// init function and panics if codec is nil.
+func RegisterCodec(codec Codec) {
+	if codec == nil {
+		panic("drum: RegisterCodec codec is nil")
+	}
+
+	codecs.mu.Lock()
+	defer codecs.mu.Unlock()
+
+	codecs.registered = append(codecs.registered, codec)
+}
+
+// CodecFor returns the codec used for patterns with the version. Extended
+// versions use the extended codec and all other versions the classic one,
+// unless a registered codec matches.
+func CodecFor(version string) Codec {
+	codecs.mu.RLock()
+	defer codecs.mu.RUnlock()
+
+	for i := len(codecs.registered) - 1; i >= 0; i-- {
+		if codec := codecs.registered[i]; codec.Match(version) {
+			return codec
+		}
+	}
+	if isExtendedVersion(version) {
+		return builtinCodec{extended: true}
+	}
+
+	return builtinCodec{}
+}
+
+// isBuiltinCodec returns whether codec is the classic or extended codec,
+// which the Decoder and DecodeHeader read without the codec.
+func isBuiltinCodec(codec Codec) bool {
+	_, ok := codec.(builtinCodec)
+	return ok
+}
+
+// builtinCodec is the codec of the classic format with a byte per step, or
+// of the extended format with the velocity and probability of the steps.
+type builtinCodec struct {
+	extended bool
+}
+
+func (codec builtinCodec) Name() string {
+	if codec.extended {
+		return "extended"
+	}
+
+	return "classic"
+}
+
+func (codec builtinCodec) Match(version string) bool {
+	return isExtendedVersion(version) == codec.extended
+}
+
+func (codec builtinCodec) ReadTracks(r io.Reader, size int64) ([]*Track, error) {
+	return readTracks(r, size, codec.extended)
+}
+
+func (codec builtinCodec) WriteTracks(w io.Writer, pattern *Pattern) error {
+	buf := new(bytes.Buffer)
+	for _, track := range pattern.Tracks {
+		binary.Write(buf, binary.LittleEndian, int32(track.ID))
+		binary.Write(buf, binary.LittleEndian, int8(len(track.Name)))
+		buf.WriteString(track.Name)
+		for i, step := range track.AllSteps() {
+			switch {
+			case codec.extended:
+				step := track.extendedStep(i)
+				buf.Write(step[:])
+			case step:
+				binary.Write(buf, binary.LittleEndian, int8(1))
+			default:
+				binary.Write(buf, binary.LittleEndian, int8(0))
+			}
+		}
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,137 @@
+package drum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var errVendorTrackID = errors.New("vendor track IDs are at most 255")
+
+// vendorCodec stores a track as a byte ID, a null terminated name and a
+// bitmask of the 16 steps
+type vendorCodec struct{}
+
+func (vendorCodec) Name() string {
+	return "vendor"
+}
+
+func (vendorCodec) Match(version string) bool {
+	return strings.HasPrefix(version, "test-vendor")
+}
+
+func (vendorCodec) ReadTracks(r io.Reader, size int64) ([]*Track, error) {
+	br := bufio.NewReader(io.LimitReader(r, size))
+	var tracks []*Track
+	for {
+		id, err := br.ReadByte()
+		if err == io.EOF {
+			return tracks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		name, err := br.ReadString(0)
+		if err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+		var mask uint16
+		if err := binary.Read(br, binary.BigEndian, &mask); err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		track := &Track{ID: int(id), Name: strings.TrimSuffix(name, "\x00")}
+		for i := range track.Steps {
+			track.Steps[i] = mask&(1<<i) != 0
+		}
+		tracks = append(tracks, track)
+	}
+}
+
+func (vendorCodec) WriteTracks(w io.Writer, pattern *Pattern) error {
+	for _, track := range pattern.Tracks {
+		if track.ID > 255 {
+			return errVendorTrackID
+		}
+		var mask uint16
+		for i, step := range track.Steps {
+			if step {
+				mask |= 1 << i
+			}
+		}
+		w.Write(append([]byte{byte(track.ID)}, track.Name+"\x00"...))
+		binary.Write(w, binary.BigEndian, mask)
+	}
+
+	return nil
+}
+
+func init() {
+	RegisterCodec(vendorCodec{})
+}
+
+func TestCodecFor(t *testing.T) {
+	tData := map[string]string{
+		"0.708":         "classic",
+		"0.808-alpha":   "classic",
+		"0.909":         "classic",
+		"0.909-ext":     "extended",
+		"test-vendor-2": "vendor",
+	}
+	for version, name := range tData {
+		if codec := CodecFor(version); codec.Name() != name {
+			t.Fatalf("%s: expected the %s codec, got %s", version, name, codec.Name())
+		}
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	pattern := testPattern()
+	pattern.Version = "test-vendor-2"
+	data := pattern.Bytes()
+	if expected := 50 + (1 + 5 + 2) + (1 + 6 + 2) + (1 + 8 + 2) + (1 + 8 + 2); len(data) != expected {
+		t.Fatalf("expected %d bytes in the vendor format, got %d", expected, len(data))
+	}
+
+	decoded, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("something went wrong decoding - %v", err)
+	}
+	if !Equal(pattern, decoded) {
+		t.Fatalf("decoded pattern differs:\n%s", Diff(pattern, decoded))
+	}
+	decoded, err = NewDecoder(bytes.NewReader(data), DecoderOptions{Strict: true}).Decode()
+	if err != nil || !Equal(pattern, decoded) {
+		t.Fatalf("something went wrong decoding strictly - %v", err)
+	}
+	if _, err := NewDecoder(bytes.NewReader(data), DecoderOptions{MaxTracks: 2}).Decode(); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+
+	info, err := DecodeHeader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("something went wrong decoding the header - %v", err)
+	}
+	if len(info.Tracks) != 4 || info.Tracks[2].Name != "hh-open" {
+		t.Fatalf("unexpected tracks %+v", info.Tracks)
+	}
+	if track, err := info.LoadTrack(2); err != nil || track.String() != pattern.Tracks[2].String() {
+		t.Fatalf("expected %s, got %s (%v)", pattern.Tracks[2], track, err)
+	}
+
+	pattern.Tracks[3].ID = 256
+	if err := Encode(pattern, io.Discard); !errors.Is(err, errVendorTrackID) {
+		t.Fatalf("expected the codec error, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected RegisterCodec(nil) to panic")
+		}
+	}()
+	RegisterCodec(nil)
+}
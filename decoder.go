@@ -1,7 +1,10 @@
 package drum
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
 )
 
@@ -18,22 +21,58 @@ import (
 // 0, 4: ID int32
 // 4, 1: length of track name int8
 // 5, length: track name string
-// 5 + length, 16: steps 00 or 01
+// 5 + length, 16, 32 or 64: steps 00 or 01
+// Extended patterns, with a version ending in -ext, store two bytes per step:
+// the velocity (00 for inactive steps) and the probability.
+// The content can be followed by a checksum trailer, see ChecksumTrailer.
 func DecodeFile(path string) (*Pattern, error) {
-	p := &Pattern{}
-
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
+	return Decode(f)
+}
+
+// DecodeFileWithChecksum decodes the drum machine file found at the provided
+// path and verifies the checksum of the decoded pattern against expected.
+func DecodeFileWithChecksum(path string, expected uint32) (*Pattern, error) {
+	pattern, err := DecodeFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum, err := pattern.Checksum()
+	if err != nil {
+		return nil, err
+	}
+	if checksum != expected {
+		return nil, fmt.Errorf("%w: expected %08x, got %08x", ErrChecksumMismatch, expected, checksum)
+	}
+
+	return pattern, nil
+}
+
+// DecodeReader decodes a drum machine pattern from f.
+//
+// Deprecated: use Decode.
+func DecodeReader(f io.Reader) (*Pattern, error) {
+	return Decode(f)
+}
+
+// Decode decodes a drum machine pattern from r, which doesn't need to be
+// seekable. Data after the content size stored in the file is not read. The
+// tracks are read by the codec of the version, see CodecFor.
+func Decode(f io.Reader) (*Pattern, error) {
+	p := &Pattern{}
+
 	header, err := readHeader(f)
 	if err != nil {
 		return nil, err
 	}
 	if header != "SPLICE" {
-		return nil, fmt.Errorf("Invalid file header, expected SPLICE, got %s", header)
+		return nil, fmt.Errorf("%w, expected SPLICE, got %s", ErrBadHeader, header)
 	}
 
 	size, err := readContentSize(f)
@@ -55,17 +94,52 @@ func DecodeFile(path string) (*Pattern, error) {
 	p.Tempo = tempo
 	size -= 4
 
-	var tracks []*Track
-	for size > 0 {
-		track, err := readTrack(f, &size)
-
-		if err != nil {
-			return nil, err
-		}
-
-		tracks = append(tracks, track)
+	tracks, err := CodecFor(version).ReadTracks(f, size)
+	if err != nil {
+		return nil, err
 	}
 	p.Tracks = tracks
 
 	return p, nil
 }
+
+// ReadFrom decodes a pattern from r into the receiver. It implements
+// io.ReaderFrom and returns the number of bytes consumed.
+func (pattern *Pattern) ReadFrom(r io.Reader) (int64, error) {
+	counter := &countingReader{r: r}
+	decoded, err := Decode(counter)
+	if err != nil {
+		return counter.n, err
+	}
+	*pattern = *decoded
+
+	return counter.n, nil
+}
+
+// UnmarshalBinary decodes the .splice binary format into the receiver. It
+// implements encoding.BinaryUnmarshaler.
+func (pattern *Pattern) UnmarshalBinary(data []byte) error {
+	_, err := pattern.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+// DecodeBase64 decodes a pattern encoded with EncodeBase64 into the receiver
+func (pattern *Pattern) DecodeBase64(s string) error {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+
+	return pattern.UnmarshalBinary(data)
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
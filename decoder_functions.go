@@ -3,12 +3,13 @@ package drum
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"io"
 )
 
 func readHeader(file io.Reader) (string, error) {
 	buf := make([]byte, 6)
-	_, err := file.Read(buf)
+	_, err := io.ReadFull(file, buf)
 
 	if err != nil {
 		return "", err
@@ -30,7 +31,7 @@ func readContentSize(file io.Reader) (int64, error) {
 
 func readVersion(file io.Reader) (string, error) {
 	buf := make([]byte, 32)
-	_, err := file.Read(buf)
+	_, err := io.ReadFull(file, buf)
 
 	if err != nil {
 		return "", err
@@ -67,9 +68,14 @@ func readTrack(file io.Reader, size *int64) (*Track, error) {
 		return nil, err
 	}
 	*size--
+	if nameLength < 0 {
+		return nil, fmt.Errorf("%w: negative track name length %d", ErrInvalidFormat, nameLength)
+	}
 
 	buf := make([]byte, nameLength)
-	file.Read(buf)
+	if _, err := io.ReadFull(file, buf); err != nil {
+		return nil, err
+	}
 	track.Name = string(buf)
 	*size -= int64(nameLength)
 
@@ -88,3 +94,78 @@ func readTrack(file io.Reader, size *int64) (*Track, error) {
 
 	return track, nil
 }
+
+// readTracks reads the tracks stored in the remaining size bytes of the
+// content. The step count is not stored in the file, the first of
+// SupportedStepCounts for which the tracks exactly fill the content is used.
+// If none does, 16 step tracks are read until the content size is used up.
+// Extended tracks have to fill the content.
+func readTracks(file io.Reader, size int64, extended bool) ([]*Track, error) {
+	data, err := io.ReadAll(io.LimitReader(file, size))
+	if err != nil {
+		return nil, err
+	}
+	for _, steps := range SupportedStepCounts {
+		if tracks, ok := parseTracks(data, steps, extended); ok {
+			return tracks, nil
+		}
+	}
+	if extended {
+		return nil, fmt.Errorf("%w: extended tracks don't fill the content", ErrInvalidFormat)
+	}
+
+	file = io.MultiReader(bytes.NewReader(data), file)
+	var tracks []*Track
+	for size > 0 {
+		track, err := readTrack(file, &size)
+		if err != nil {
+			return nil, err
+		}
+
+		tracks = append(tracks, track)
+	}
+
+	return tracks, nil
+}
+
+// parseTracks parses data as tracks with the given number of steps. It
+// returns false unless data holds complete tracks with a name and steps of
+// 00 or 01, or a velocity of at most 127 for extended tracks.
+func parseTracks(data []byte, steps int, extended bool) ([]*Track, bool) {
+	stepSize := 1
+	if extended {
+		stepSize = 2
+	}
+
+	var tracks []*Track
+	for len(data) > 0 {
+		if len(data) < 5 {
+			return nil, false
+		}
+		nameLength := int(int8(data[4]))
+		end := 5 + nameLength + steps*stepSize
+		if nameLength <= 0 || len(data) < end {
+			return nil, false
+		}
+
+		track := &Track{ID: int(int32(binary.LittleEndian.Uint32(data))), Name: string(data[5 : 5+nameLength])}
+		track.SetLength(steps)
+		stepData := data[5+nameLength : end]
+		for i := 0; i < steps; i++ {
+			value := stepData[i*stepSize]
+			switch {
+			case extended && value <= 127:
+				track.setExtendedStep(i, value, stepData[i*stepSize+1])
+			case !extended && value <= 1:
+				track.SetStep(i, value == 1)
+			default:
+				return nil, false
+			}
+		}
+
+		tracks = append(tracks, track)
+		data = data[end:]
+	}
+
+	return tracks, true
+}
@@ -0,0 +1,302 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// Default limits of a Decoder, large enough for any pattern created by the
+// drum machine
+const (
+	DefaultMaxContentSize = 1 << 20
+	DefaultMaxTracks      = 1024
+)
+
+// DecoderOptions configures a Decoder. Exceeding a limit is an error in both
+// modes, so untrusted files can be decoded safely.
+type DecoderOptions struct {
+	// Strict fails on trailing data, truncated tracks, step bytes other than
+	// 00 and 01 and a content size which doesn't match the file. Otherwise
+	// the decoder recovers what it can and records the problems as warnings.
+	Strict bool
+	// MaxContentSize limits the bytes read after the content size field,
+	// DefaultMaxContentSize if 0
+	MaxContentSize int64
+	// MaxTracks limits the number of tracks, DefaultMaxTracks if 0
+	MaxTracks int
+	// MaxNameLength limits the length of the track names, 127 (the maximum
+	// of the format) if 0
+	MaxNameLength int
+	// VerifyChecksum fails with ErrChecksumMismatch if the checksum trailer
+	// doesn't match the file. Files without a trailer decode as usual.
+	VerifyChecksum bool
+}
+
+// DecodeError is a problem found by a Decoder at a byte offset in the file
+type DecodeError struct {
+	Offset int64
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%v at offset %d", e.Err, e.Offset)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// TruncatedTrackError is returned for a track which ends after the content
+// or the file. Offset is the position of the track in the file, ID is -1 if
+// the track ends before its name length.
+type TruncatedTrackError struct {
+	Offset int64
+	ID     int
+}
+
+func (e *TruncatedTrackError) Error() string {
+	return fmt.Sprintf("Truncated track %d at offset %d", e.ID, e.Offset)
+}
+
+func (e *TruncatedTrackError) Unwrap() error {
+	return io.ErrUnexpectedEOF
+}
+
+// Decoder decodes .splice files with DecoderOptions. Unlike Decode it reads
+// the whole input, to check for data after the content.
+type Decoder struct {
+	r        io.Reader
+	opts     DecoderOptions
+	warnings []error
+}
+
+// NewDecoder creates a decoder reading from r
+func NewDecoder(r io.Reader, opts DecoderOptions) *Decoder {
+	if opts.MaxContentSize == 0 {
+		opts.MaxContentSize = DefaultMaxContentSize
+	}
+	if opts.MaxTracks == 0 {
+		opts.MaxTracks = DefaultMaxTracks
+	}
+	if opts.MaxNameLength == 0 {
+		opts.MaxNameLength = math.MaxInt8
+	}
+
+	return &Decoder{r: r, opts: opts}
+}
+
+// Warnings returns the problems recovered from by the last call to Decode
+// in lenient mode.
+func (d *Decoder) Warnings() []error {
+	return d.warnings
+}
+
+// problem returns err in strict mode and records it as a warning otherwise
+func (d *Decoder) problem(err error) error {
+	if d.opts.Strict {
+		return err
+	}
+	d.warnings = append(d.warnings, err)
+
+	return nil
+}
+
+// Decode reads and decodes a pattern. A missing header, version or tempo
+// and extended tracks which don't fill the content are errors in both modes.
+func (d *Decoder) Decode() (*Pattern, error) {
+	d.warnings = nil
+
+	// Read one byte more than allowed to detect files exceeding the limit
+	data, err := io.ReadAll(io.LimitReader(d.r, 14+d.opts.MaxContentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > 14+d.opts.MaxContentSize {
+		return nil, &DecodeError{14 + d.opts.MaxContentSize, fmt.Errorf("%w: content is longer than %d bytes", ErrLimitExceeded, d.opts.MaxContentSize)}
+	}
+	if len(data) < 6 || string(data[:6]) != "SPLICE" {
+		return nil, &DecodeError{0, ErrBadHeader}
+	}
+	if len(data) < 50 {
+		return nil, &DecodeError{int64(len(data)), fmt.Errorf("%w: file ends before the tempo", io.ErrUnexpectedEOF)}
+	}
+
+	pattern := &Pattern{
+		Version: string(bytes.Trim(data[14:46], "\x00")),
+		Tempo:   math.Float32frombits(binary.LittleEndian.Uint32(data[46:])),
+	}
+
+	size := int64(binary.BigEndian.Uint64(data[6:]))
+	end := 14 + size
+	switch {
+	case size < 36 || size > int64(len(data))-14:
+		err := &DecodeError{6, fmt.Errorf("%w: content size %d, file holds %d bytes", ErrContentSizeMismatch, size, len(data)-14)}
+		if err := d.problem(err); err != nil {
+			return nil, err
+		}
+		end = int64(len(data))
+	case end < int64(len(data)):
+		if err := d.checkTrailer(data, end); err != nil {
+			return nil, err
+		}
+	}
+
+	if !isBuiltinCodec(CodecFor(pattern.Version)) {
+		return d.decodeWithCodec(pattern, data[50:end])
+	}
+
+	for _, steps := range SupportedStepCounts {
+		if tracks, ok := parseTracks(data[50:end], steps, pattern.Extended()); ok {
+			if err := d.checkLimits(tracks, steps, pattern.Extended()); err != nil {
+				return nil, err
+			}
+			pattern.Tracks = tracks
+			return pattern, nil
+		}
+	}
+	if pattern.Extended() {
+		return nil, &DecodeError{50, fmt.Errorf("%w: extended tracks don't fill the content", ErrInvalidFormat)}
+	}
+
+	// Read 16 step tracks, reporting the problems
+	offset := int64(50)
+	for offset < end {
+		if len(pattern.Tracks) == d.opts.MaxTracks {
+			return nil, &DecodeError{offset, fmt.Errorf("%w: more than %d tracks", ErrLimitExceeded, d.opts.MaxTracks)}
+		}
+		track, next, err := d.decodeTrack(data, offset, end)
+		if err != nil {
+			return nil, err
+		}
+		if track == nil {
+			break
+		}
+		pattern.Tracks = append(pattern.Tracks, track)
+		offset = next
+	}
+
+	return pattern, nil
+}
+
+// checkTrailer checks the data after the content at end, which is either a
+// checksum trailer or trailing data.
+func (d *Decoder) checkTrailer(data []byte, end int64) error {
+	trailer := data[end:]
+	if len(trailer) != checksumTrailerSize || string(trailer[:len(ChecksumTrailer)]) != ChecksumTrailer {
+		return d.problem(&DecodeError{end, fmt.Errorf("%w: %d bytes", ErrTrailingData, len(trailer))})
+	}
+	if !d.opts.VerifyChecksum {
+		return nil
+	}
+
+	expected := binary.BigEndian.Uint32(trailer[len(ChecksumTrailer):])
+	if checksum := crc32.ChecksumIEEE(data[:end]); checksum != expected {
+		return &DecodeError{end, fmt.Errorf("%w: expected %08x, got %08x", ErrChecksumMismatch, expected, checksum)}
+	}
+
+	return nil
+}
+
+// decodeWithCodec reads the tracks of a pattern with a version of a
+// registered codec from the content after the tempo. Only the limits are
+// checked, the codec handles the problems of its format.
+func (d *Decoder) decodeWithCodec(pattern *Pattern, content []byte) (*Pattern, error) {
+	tracks, err := CodecFor(pattern.Version).ReadTracks(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, &DecodeError{50, err}
+	}
+	if len(tracks) > d.opts.MaxTracks {
+		return nil, &DecodeError{50, fmt.Errorf("%w: more than %d tracks", ErrLimitExceeded, d.opts.MaxTracks)}
+	}
+	for _, track := range tracks {
+		if len(track.Name) > d.opts.MaxNameLength {
+			return nil, &DecodeError{50, fmt.Errorf("%w: track name of %d bytes, at most %d allowed", ErrLimitExceeded, len(track.Name), d.opts.MaxNameLength)}
+		}
+	}
+	pattern.Tracks = tracks
+
+	return pattern, nil
+}
+
+// checkLimits checks the number of tracks and the length of their names,
+// for tracks with the given number of steps parsed from the content.
+func (d *Decoder) checkLimits(tracks []*Track, steps int, extended bool) error {
+	stepSize := 1
+	if extended {
+		stepSize = 2
+	}
+
+	offset := int64(50)
+	for i, track := range tracks {
+		if i == d.opts.MaxTracks {
+			return &DecodeError{offset, fmt.Errorf("%w: more than %d tracks", ErrLimitExceeded, d.opts.MaxTracks)}
+		}
+		if err := d.checkNameLength(offset, len(track.Name)); err != nil {
+			return err
+		}
+		offset += int64(5 + len(track.Name) + steps*stepSize)
+	}
+
+	return nil
+}
+
+// checkNameLength checks the name length of the track at offset
+func (d *Decoder) checkNameLength(offset int64, length int) error {
+	if length > d.opts.MaxNameLength {
+		return &DecodeError{offset + 4, fmt.Errorf("%w: track name of %d bytes, at most %d allowed", ErrLimitExceeded, length, d.opts.MaxNameLength)}
+	}
+
+	return nil
+}
+
+// decodeTrack decodes the 16 step track at offset. In lenient mode a track
+// continuing after end is read from the following data if possible, nil is
+// returned for a track which can't be recovered.
+func (d *Decoder) decodeTrack(data []byte, offset, end int64) (*Track, int64, error) {
+	truncated := func(id int) (*Track, int64, error) {
+		return nil, 0, d.problem(&TruncatedTrackError{offset, id})
+	}
+
+	if offset+5 > end {
+		return truncated(-1)
+	}
+	track := &Track{ID: int(int32(binary.LittleEndian.Uint32(data[offset:])))}
+	nameLength := int64(int8(data[offset+4]))
+	if nameLength < 0 {
+		err := &DecodeError{offset + 4, fmt.Errorf("%w: negative track name length %d", ErrInvalidFormat, nameLength)}
+		return nil, 0, d.problem(err)
+	}
+	if err := d.checkNameLength(offset, int(nameLength)); err != nil {
+		return nil, 0, err
+	}
+
+	next := offset + 5 + nameLength + StepCount
+	if next > end {
+		if next > int64(len(data)) {
+			return truncated(track.ID)
+		}
+		if err := d.problem(&TruncatedTrackError{offset, track.ID}); err != nil {
+			return nil, 0, err
+		}
+	}
+	track.Name = string(data[offset+5 : offset+5+nameLength])
+
+	steps := offset + 5 + nameLength
+	for i := range track.Steps {
+		value := data[steps+int64(i)]
+		if value > 1 {
+			err := &DecodeError{steps + int64(i), fmt.Errorf("%w: %02x", ErrInvalidStep, value)}
+			if err := d.problem(err); err != nil {
+				return nil, 0, err
+			}
+		}
+		// Same as Decode, which reads the steps as int8
+		track.Steps[i] = int8(value) > 0
+	}
+
+	return track, next, nil
+}
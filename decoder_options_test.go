@@ -0,0 +1,212 @@
+package drum
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestDecoderFixtures(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		data, err := os.ReadFile(path.Join("fixtures", name))
+		if err != nil {
+			t.Fatalf("something went wrong reading %s - %v", name, err)
+		}
+
+		decoder := NewDecoder(bytes.NewReader(data), DecoderOptions{})
+		decoded, err := decoder.Decode()
+		if err != nil {
+			t.Fatalf("something went wrong decoding %s - %v", name, err)
+		}
+		if decoded.String() != pattern.String() {
+			t.Fatalf("%s: lenient decoding differs from Decode, got:\n%s", name, decoded)
+		}
+
+		_, err = NewDecoder(bytes.NewReader(data), DecoderOptions{Strict: true}).Decode()
+		if name != "pattern_5.splice" {
+			if err != nil || len(decoder.Warnings()) != 0 {
+				t.Fatalf("%s: expected no problems, got %v and warnings %v", name, err, decoder.Warnings())
+			}
+			continue
+		}
+
+		// pattern_5 has another pattern header after its content
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) || !errors.Is(err, ErrTrailingData) || decodeErr.Offset != 101 {
+			t.Fatalf("expected trailing data at offset 101, got %v", err)
+		}
+		if warnings := decoder.Warnings(); len(warnings) != 1 || !errors.Is(warnings[0], ErrTrailingData) {
+			t.Fatalf("expected a trailing data warning, got %v", warnings)
+		}
+	}
+}
+
+func TestDecoderProblems(t *testing.T) {
+	pattern := testPattern()
+	valid := pattern.Bytes()
+
+	invalidStep := append([]byte(nil), valid...)
+	// The first step of the kick track, after its ID, name length and name
+	invalidStep[50+4+1+4] = 2
+
+	truncated := valid[:len(valid)-3]
+
+	tData := []struct {
+		name     string
+		data     []byte
+		err      error
+		offset   int64
+		recovers int
+	}{
+		{"invalid step", invalidStep, ErrInvalidStep, 59, 4},
+		{"truncated", truncated, ErrContentSizeMismatch, 6, 3},
+		{"bad header", append([]byte("SPLISE"), valid[6:]...), ErrBadHeader, 0, -1},
+	}
+
+	for _, exp := range tData {
+		_, err := NewDecoder(bytes.NewReader(exp.data), DecoderOptions{Strict: true}).Decode()
+		var decodeErr *DecodeError
+		if !errors.Is(err, exp.err) || !errors.As(err, &decodeErr) || decodeErr.Offset != exp.offset {
+			t.Fatalf("%s: expected %v at offset %d, got %v", exp.name, exp.err, exp.offset, err)
+		}
+
+		decoder := NewDecoder(bytes.NewReader(exp.data), DecoderOptions{})
+		decoded, err := decoder.Decode()
+		if exp.recovers < 0 {
+			if err == nil {
+				t.Fatalf("%s: expected an error in lenient mode", exp.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: something went wrong decoding - %v", exp.name, err)
+		}
+		if len(decoded.Tracks) != exp.recovers {
+			t.Fatalf("%s: expected %d recovered tracks, got:\n%s", exp.name, exp.recovers, decoded)
+		}
+		if len(decoder.Warnings()) == 0 || !errors.Is(decoder.Warnings()[0], exp.err) {
+			t.Fatalf("%s: expected a %v warning, got %v", exp.name, exp.err, decoder.Warnings())
+		}
+	}
+}
+
+func TestDecoderTruncatedTrack(t *testing.T) {
+	data := testPattern().Bytes()
+	// Cut the content size as well, so only the last track is affected
+	data = data[:len(data)-3]
+	data[13] -= 3
+
+	_, err := NewDecoder(bytes.NewReader(data), DecoderOptions{Strict: true}).Decode()
+	var truncated *TruncatedTrackError
+	if !errors.As(err, &truncated) || truncated.ID != 5 {
+		t.Fatalf("expected truncated track 5, got %v", err)
+	}
+	if truncated.Offset != int64(len(data)-(4+1+7+13)) {
+		t.Fatalf("unexpected offset %d", truncated.Offset)
+	}
+}
+
+func TestDecoderLimits(t *testing.T) {
+	data := testPattern().Bytes()
+
+	tData := []struct {
+		opts   DecoderOptions
+		offset int64
+	}{
+		{DecoderOptions{MaxContentSize: 100}, 114},
+		{DecoderOptions{MaxTracks: 3}, int64(len(data) - (4 + 1 + 7 + 16))},
+		{DecoderOptions{MaxNameLength: 6}, 50 + 25 + 26 + 4},
+	}
+
+	for _, exp := range tData {
+		for _, strict := range []bool{false, true} {
+			exp.opts.Strict = strict
+			_, err := NewDecoder(bytes.NewReader(data), exp.opts).Decode()
+			var decodeErr *DecodeError
+			if !errors.Is(err, ErrLimitExceeded) || !errors.As(err, &decodeErr) || decodeErr.Offset != exp.offset {
+				t.Fatalf("%+v: expected ErrLimitExceeded at offset %d, got %v", exp.opts, exp.offset, err)
+			}
+		}
+	}
+
+	// The limits also apply to tracks which only decode as 16 step tracks
+	broken := append([]byte(nil), data...)
+	broken[50+4+1+4] = 2
+	if _, err := NewDecoder(bytes.NewReader(broken), DecoderOptions{MaxTracks: 3}).Decode(); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+}
+
+func TestDecoderChecksum(t *testing.T) {
+	pattern := testPattern()
+	buf := new(bytes.Buffer)
+	if err := EncodeWithChecksum(pattern, buf); err != nil {
+		t.Fatalf("something went wrong encoding - %v", err)
+	}
+	data := buf.Bytes()
+	if !bytes.Equal(data, pattern.BytesWithChecksum()) || !bytes.HasPrefix(data, pattern.Bytes()) {
+		t.Fatalf("expected the encoded pattern followed by a trailer")
+	}
+
+	if decoded, err := Decode(bytes.NewReader(data)); err != nil || !Equal(pattern, decoded) {
+		t.Fatalf("expected Decode to ignore the trailer, got %v", err)
+	}
+	for _, opts := range []DecoderOptions{{Strict: true}, {Strict: true, VerifyChecksum: true}} {
+		decoded, err := NewDecoder(bytes.NewReader(data), opts).Decode()
+		if err != nil || !Equal(pattern, decoded) {
+			t.Fatalf("%+v: something went wrong decoding - %v", opts, err)
+		}
+	}
+
+	// Files without a trailer don't have to be verified
+	if _, err := NewDecoder(bytes.NewReader(pattern.Bytes()), DecoderOptions{VerifyChecksum: true}).Decode(); err != nil {
+		t.Fatalf("something went wrong decoding without a trailer - %v", err)
+	}
+
+	corrupted := append([]byte(nil), data...)
+	corrupted[60] ^= 1
+	if _, err := NewDecoder(bytes.NewReader(corrupted), DecoderOptions{}).Decode(); err != nil {
+		t.Fatalf("expected the checksum to be ignored, got %v", err)
+	}
+	_, err := NewDecoder(bytes.NewReader(corrupted), DecoderOptions{VerifyChecksum: true}).Decode()
+	var decodeErr *DecodeError
+	if !errors.Is(err, ErrChecksumMismatch) || !errors.As(err, &decodeErr) || decodeErr.Offset != int64(len(pattern.Bytes())) {
+		t.Fatalf("expected ErrChecksumMismatch after the content, got %v", err)
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, pattern := range fixtures(&testing.T{}) {
+		f.Add(pattern.Bytes())
+	}
+	extended := testPattern()
+	extended.Version = "0.909-ext"
+	f.Add(extended.Bytes())
+	long := testPattern()
+	long.SetLength(64)
+	f.Add(long.Bytes())
+	f.Add(testPattern().BytesWithChecksum())
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		Decode(bytes.NewReader(data))
+		NewDecoder(bytes.NewReader(data), DecoderOptions{}).Decode()
+		NewDecoder(bytes.NewReader(data), DecoderOptions{VerifyChecksum: true}).Decode()
+		if info, err := DecodeHeader(bytes.NewReader(data)); err == nil {
+			info.Load()
+		}
+
+		pattern, err := NewDecoder(bytes.NewReader(data), DecoderOptions{Strict: true}).Decode()
+		if err != nil || pattern.Validate() != nil {
+			return
+		}
+		decoded, err := Decode(bytes.NewReader(pattern.Bytes()))
+		if err != nil {
+			t.Fatalf("something went wrong decoding the encoded pattern - %v", err)
+		}
+		if !Equal(pattern, decoded) {
+			t.Fatalf("pattern changed when encoded:\n%s", Diff(pattern, decoded))
+		}
+	})
+}
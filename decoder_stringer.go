@@ -2,27 +2,74 @@ package drum
 
 import (
 	"fmt"
+	"io"
+	"strings"
 )
 
+// StepGlyphs are the strings used to render steps, empty fields use the
+// defaults of String.
+type StepGlyphs struct {
+	// Active is used for active steps, x by default
+	Active string
+	// Inactive is used for inactive steps, - by default
+	Inactive string
+	// Separator separates the beats, | by default
+	Separator string
+}
+
+// withDefaults returns the glyphs with the empty fields set to the defaults
+func (glyphs StepGlyphs) withDefaults() StepGlyphs {
+	if glyphs.Active == "" {
+		glyphs.Active = "x"
+	}
+	if glyphs.Inactive == "" {
+		glyphs.Inactive = "-"
+	}
+	if glyphs.Separator == "" {
+		glyphs.Separator = "|"
+	}
+
+	return glyphs
+}
+
+// String returns the classic text rendering of the pattern:
+//
+//	Saved with HW Version: 0.808-alpha
+//	Tempo: 120
+//	(0) kick	|x---|x---|x---|x---|
 func (pattern *Pattern) String() string {
+	return pattern.StringWithGlyphs(StepGlyphs{})
+}
+
+// StringWithGlyphs returns the text rendering of String using glyphs for the
+// steps.
+func (pattern *Pattern) StringWithGlyphs(glyphs StepGlyphs) string {
 	output := fmt.Sprintf("Saved with HW Version: %s\nTempo: %g\n", pattern.Version, pattern.Tempo)
 	for _, track := range pattern.Tracks {
-		output += fmt.Sprintf("%s\n", track)
+		output += track.StringWithGlyphs(glyphs) + "\n"
 	}
 
 	return output
 }
 
+// String returns the track as a line of the text rendering of the pattern
 func (track *Track) String() string {
-	steps := "|"
-	for i, step := range track.Steps {
+	return track.StringWithGlyphs(StepGlyphs{})
+}
+
+// StringWithGlyphs returns the track as a line of the text rendering of the
+// pattern using glyphs for the steps.
+func (track *Track) StringWithGlyphs(glyphs StepGlyphs) string {
+	glyphs = glyphs.withDefaults()
+	steps := glyphs.Separator
+	for i, step := range track.AllSteps() {
 		if step {
-			steps += "x"
+			steps += glyphs.Active
 		} else {
-			steps += "-"
+			steps += glyphs.Inactive
 		}
-		if i%4 == 3 {
-			steps += "|"
+		if i%StepsPerBeat == StepsPerBeat-1 {
+			steps += glyphs.Separator
 		}
 	}
 
@@ -30,3 +77,93 @@ func (track *Track) String() string {
 
 	return output
 }
+
+// PrettyPrintOptions configures the output of PrettyPrint
+type PrettyPrintOptions struct {
+	// UseColor colors active and inactive steps with ANSI escape codes
+	UseColor bool
+	// ShowGrid separates the beats with a |
+	ShowGrid bool
+	// ShowDensity appends the percentage of active steps to every track
+	ShowDensity bool
+	// Width limits the width of the track lines by truncating the track
+	// names, 0 means unlimited
+	Width int
+}
+
+// ANSI escape codes used by PrettyPrint
+const (
+	ansiActive   = "\x1b[1;32m"
+	ansiInactive = "\x1b[90m"
+	ansiReset    = "\x1b[0m"
+)
+
+// PrettyPrint writes a human readable representation of the pattern to w.
+// Track names are aligned in a column.
+func (pattern *Pattern) PrettyPrint(w io.Writer, opts PrettyPrintOptions) error {
+	idWidth, nameWidth := 0, 0
+	for _, track := range pattern.Tracks {
+		if n := len(fmt.Sprintf("(%d)", track.ID)); n > idWidth {
+			idWidth = n
+		}
+		if n := len(track.Name); n > nameWidth {
+			nameWidth = n
+		}
+	}
+
+	if opts.Width > 0 {
+		fixed := idWidth + 2 + pattern.Length()
+		if opts.ShowGrid {
+			fixed += pattern.Length()/StepsPerBeat + 1
+		}
+		if opts.ShowDensity {
+			fixed += 5
+		}
+		if available := opts.Width - fixed; available < nameWidth {
+			nameWidth = available
+		}
+		if nameWidth < 1 {
+			nameWidth = 1
+		}
+	}
+
+	output := fmt.Sprintf("Saved with HW Version: %s\nTempo: %g\n", pattern.Version, pattern.Tempo)
+	for _, track := range pattern.Tracks {
+		name := track.Name
+		if len(name) > nameWidth {
+			name = name[:nameWidth]
+		}
+		output += fmt.Sprintf("%-*s %-*s %s", idWidth, fmt.Sprintf("(%d)", track.ID), nameWidth, name, prettySteps(track, opts))
+		if opts.ShowDensity {
+			output += fmt.Sprintf(" %3.0f%%", track.Density()*100)
+		}
+		output += "\n"
+	}
+
+	_, err := io.WriteString(w, output)
+	return err
+}
+
+func prettySteps(track *Track, opts PrettyPrintOptions) string {
+	var steps strings.Builder
+	if opts.ShowGrid {
+		steps.WriteString("|")
+	}
+	for i, step := range track.AllSteps() {
+		switch {
+		case step && opts.UseColor:
+			steps.WriteString(ansiActive + "x" + ansiReset)
+		case step:
+			steps.WriteString("x")
+		case opts.UseColor:
+			steps.WriteString(ansiInactive + "-" + ansiReset)
+		default:
+			steps.WriteString("-")
+		}
+		if opts.ShowGrid && i%StepsPerBeat == StepsPerBeat-1 {
+			steps.WriteString("|")
+		}
+	}
+
+	return steps.String()
+}
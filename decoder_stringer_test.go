@@ -0,0 +1,124 @@
+package drum
+
+import (
+	"bytes"
+	"path"
+	"testing"
+)
+
+func TestPrettyPrint(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_4.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	tData := []struct {
+		opts   PrettyPrintOptions
+		output string
+	}{
+		{PrettyPrintOptions{},
+			`Saved with HW Version: 0.909
+Tempo: 240
+(0)   SubKick   ----------------
+(1)   Kick      x-------x-------
+(99)  Maracas   x-x-x-x-x-x-x-x-
+(255) Low Conga ----x-------x---
+`,
+		},
+		{PrettyPrintOptions{ShowGrid: true, ShowDensity: true},
+			`Saved with HW Version: 0.909
+Tempo: 240
+(0)   SubKick   |----|----|----|----|   0%
+(1)   Kick      |x---|----|x---|----|  12%
+(99)  Maracas   |x-x-|x-x-|x-x-|x-x-|  50%
+(255) Low Conga |----|x---|----|x---|  12%
+`,
+		},
+		{PrettyPrintOptions{ShowGrid: true, Width: 33},
+			`Saved with HW Version: 0.909
+Tempo: 240
+(0)   SubKi |----|----|----|----|
+(1)   Kick  |x---|----|x---|----|
+(99)  Marac |x-x-|x-x-|x-x-|x-x-|
+(255) Low C |----|x---|----|x---|
+`,
+		},
+	}
+
+	for _, exp := range tData {
+		var buf bytes.Buffer
+		if err := pattern.PrettyPrint(&buf, exp.opts); err != nil {
+			t.Fatalf("something went wrong printing %v", err)
+		}
+		if buf.String() != exp.output {
+			t.Fatalf("unexpected output for %+v.\nGot:\n%s\nExpected:\n%s", exp.opts, buf.String(), exp.output)
+		}
+	}
+}
+
+func TestPrettyPrintLongPattern(t *testing.T) {
+	track := &Track{ID: 1, Name: "Kick", Steps: [16]bool{0: true}}
+	track.SetLength(32)
+	track.SetStep(20, true)
+	pattern := &Pattern{Version: "0.909", Tempo: 120, Tracks: []*Track{track}}
+
+	var buf bytes.Buffer
+	if err := pattern.PrettyPrint(&buf, PrettyPrintOptions{ShowGrid: true, ShowDensity: true, Width: 54}); err != nil {
+		t.Fatalf("something went wrong printing %v", err)
+	}
+	expected := `Saved with HW Version: 0.909
+Tempo: 120
+(1) Kic |x---|----|----|----|----|x---|----|----|   6%
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output.\nGot:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestString(t *testing.T) {
+	tData := []struct {
+		tempo float32
+		line  string
+	}{
+		{120, "Tempo: 120\n"},
+		{98.4, "Tempo: 98.4\n"},
+		{120.5, "Tempo: 120.5\n"},
+		{999, "Tempo: 999\n"},
+		{20.25, "Tempo: 20.25\n"},
+	}
+
+	for _, exp := range tData {
+		pattern := &Pattern{Version: "0.808-alpha", Tempo: exp.tempo, Tracks: []*Track{
+			&Track{ID: 0, Name: "kick", Steps: [16]bool{0: true, 4: true, 8: true, 12: true}},
+		}}
+		expected := "Saved with HW Version: 0.808-alpha\n" + exp.line + "(0) kick\t|x---|x---|x---|x---|\n"
+		if pattern.String() != expected {
+			t.Fatalf("tempo %v: expected:\n%s\ngot:\n%s", exp.tempo, expected, pattern)
+		}
+	}
+}
+
+func TestStringWithGlyphs(t *testing.T) {
+	track := &Track{ID: 1, Name: "snare", Steps: [16]bool{4: true, 12: true}}
+
+	tData := []struct {
+		glyphs StepGlyphs
+		output string
+	}{
+		{StepGlyphs{}, "(1) snare\t|----|x---|----|x---|"},
+		{StepGlyphs{Active: "X", Inactive: "."}, "(1) snare\t|....|X...|....|X...|"},
+		{StepGlyphs{Active: "●", Inactive: "○", Separator: " "}, "(1) snare\t ○○○○ ●○○○ ○○○○ ●○○○ "},
+	}
+
+	for _, exp := range tData {
+		if output := track.StringWithGlyphs(exp.glyphs); output != exp.output {
+			t.Fatalf("%+v: expected %q, got %q", exp.glyphs, exp.output, output)
+		}
+	}
+
+	pattern := &Pattern{Version: "0.909", Tempo: 240, Tracks: []*Track{track}}
+	expected := "Saved with HW Version: 0.909\nTempo: 240\n(1) snare\t|....|X...|....|X...|\n"
+	if output := pattern.StringWithGlyphs(StepGlyphs{Active: "X", Inactive: "."}); output != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
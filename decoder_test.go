@@ -1,9 +1,14 @@
 package drum
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path"
 	"testing"
+	"testing/iotest"
 )
 
 func TestDecodeFile(t *testing.T) {
@@ -73,3 +78,121 @@ Tempo: 999
 		}
 	}
 }
+
+func TestBeatCount(t *testing.T) {
+	for i := 1; i <= 5; i++ {
+		name := fmt.Sprintf("pattern_%d.splice", i)
+		decoded, err := DecodeFile(path.Join("fixtures", name))
+		if err != nil {
+			t.Fatalf("something went wrong decoding %s - %v", name, err)
+		}
+		if decoded.BeatCount() != 4 {
+			t.Fatalf("%s: expected 4 beats, got %d", name, decoded.BeatCount())
+		}
+	}
+}
+
+func TestReadFrom(t *testing.T) {
+	for i := 1; i <= 5; i++ {
+		name := path.Join("fixtures", fmt.Sprintf("pattern_%d.splice", i))
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("something went wrong reading %s - %v", name, err)
+		}
+		decoded, err := DecodeFile(name)
+		if err != nil {
+			t.Fatalf("something went wrong decoding %s - %v", name, err)
+		}
+
+		var pattern Pattern
+		n, err := pattern.ReadFrom(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("something went wrong reading from %s - %v", name, err)
+		}
+		if fmt.Sprint(&pattern) != fmt.Sprint(decoded) {
+			t.Fatalf("%s: pattern read doesn't match.\nGot:\n%s\nExpected:\n%s", name, &pattern, decoded)
+		}
+		if n != int64(len(decoded.Bytes())) {
+			t.Fatalf("%s: expected %d bytes consumed, got %d", name, len(decoded.Bytes()), n)
+		}
+	}
+}
+
+func TestDecodeFileWithChecksum(t *testing.T) {
+	name := path.Join("fixtures", "pattern_2.splice")
+	pattern, err := DecodeFile(name)
+	if err != nil {
+		t.Fatalf("something went wrong decoding %s - %v", name, err)
+	}
+	checksum, err := pattern.Checksum()
+	if err != nil {
+		t.Fatalf("something went wrong computing the checksum - %v", err)
+	}
+
+	if _, err := DecodeFileWithChecksum(name, checksum); err != nil {
+		t.Fatalf("something went wrong decoding with checksum - %v", err)
+	}
+
+	pattern.Tracks[1].Steps[3] = !pattern.Tracks[1].Steps[3]
+	modified, err := pattern.Checksum()
+	if err != nil {
+		t.Fatalf("something went wrong computing the checksum - %v", err)
+	}
+	if modified == checksum {
+		t.Fatalf("expected modifying a step to change checksum %08x", checksum)
+	}
+	if _, err := DecodeFileWithChecksum(name, modified); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for i := 1; i <= 5; i++ {
+		name := path.Join("fixtures", fmt.Sprintf("pattern_%d.splice", i))
+		expected, err := DecodeFile(name)
+		if err != nil {
+			t.Fatalf("something went wrong decoding %s - %v", name, err)
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("something went wrong reading %s - %v", name, err)
+		}
+
+		// A reader returning a byte at a time requires io.ReadFull semantics
+		decoded, err := Decode(iotest.OneByteReader(bytes.NewReader(data)))
+		if err != nil {
+			t.Fatalf("something went wrong decoding %s one byte at a time - %v", name, err)
+		}
+		if decoded.String() != expected.String() {
+			t.Fatalf("%s decoded differently.\nGot:\n%s\nExpected:\n%s", name, decoded, expected)
+		}
+	}
+
+	data := append([]byte("SPLICE\x00\x00\x00\x00\x00\x00\x00\x40"), make([]byte, 36)...)
+	data = append(data, []byte("\x01\x00\x00\x00\x04ki")...)
+	if _, err := Decode(bytes.NewReader(data)); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected io.ErrUnexpectedEOF for a truncated name, got %v", err)
+	}
+}
+
+func TestDecodeLongPattern(t *testing.T) {
+	for _, length := range SupportedStepCounts {
+		pattern := testPattern()
+		if err := pattern.SetLength(length); err != nil {
+			t.Fatalf("something went wrong setting the length to %d - %v", length, err)
+		}
+		pattern.Tracks[0].SetStep(length-1, true)
+		pattern.Tracks[3].SetStep(length-4, true)
+
+		decoded, err := Decode(bytes.NewReader(pattern.Bytes()))
+		if err != nil {
+			t.Fatalf("something went wrong decoding %d steps - %v", length, err)
+		}
+		if decoded.Length() != length {
+			t.Fatalf("expected %d steps, got %d", length, decoded.Length())
+		}
+		if decoded.String() != pattern.String() {
+			t.Fatalf("expected:\n%s\ngot:\n%s", pattern, decoded)
+		}
+	}
+}
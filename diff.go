@@ -0,0 +1,222 @@
+package drum
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PatternDiff describes the changes from pattern A to pattern B. Tracks are
+// matched by ID.
+type PatternDiff struct {
+	OldVersion string  `json:"oldVersion"`
+	NewVersion string  `json:"newVersion"`
+	OldTempo   float32 `json:"oldTempo"`
+	NewTempo   float32 `json:"newTempo"`
+	// Added and Removed hold the tracks which are only in B or only in A
+	Added   []*Track    `json:"added"`
+	Removed []*Track    `json:"removed"`
+	Changed []TrackDiff `json:"changed"`
+	// Reordered is set if the tracks in both patterns are in a different
+	// order in B
+	Reordered bool `json:"reordered"`
+}
+
+// TrackDiff describes the changes to a track which is in both patterns
+type TrackDiff struct {
+	ID        int    `json:"id"`
+	OldName   string `json:"oldName"`
+	NewName   string `json:"newName"`
+	OldLength int    `json:"oldLength"`
+	NewLength int    `json:"newLength"`
+	// Activated and Silenced hold the indices of the steps which are only
+	// active in B or only active in A
+	Activated []int `json:"activated"`
+	Silenced  []int `json:"silenced"`
+	// Velocities and Probabilities hold the indices of the steps with a
+	// different velocity or probability
+	Velocities    []int `json:"velocities"`
+	Probabilities []int `json:"probabilities"`
+}
+
+// Diff compares pattern a with pattern b. A nil pattern is compared as a
+// pattern without version, tempo and tracks. Null padding at the end of the
+// versions is ignored, like it is when decoding.
+func Diff(a, b *Pattern) *PatternDiff {
+	if a == nil {
+		a = &Pattern{}
+	}
+	if b == nil {
+		b = &Pattern{}
+	}
+
+	diff := &PatternDiff{
+		OldVersion: strings.TrimRight(a.Version, "\x00"),
+		NewVersion: strings.TrimRight(b.Version, "\x00"),
+		OldTempo:   a.Tempo,
+		NewTempo:   b.Tempo,
+		Added:      []*Track{},
+		Removed:    []*Track{},
+		Changed:    []TrackDiff{},
+	}
+	for _, old := range a.Tracks {
+		if b.FindTrackByID(old.ID) == nil {
+			diff.Removed = append(diff.Removed, old)
+		}
+	}
+	for _, track := range b.Tracks {
+		old := a.FindTrackByID(track.ID)
+		if old == nil {
+			diff.Added = append(diff.Added, track)
+			continue
+		}
+		if trackDiff, changed := diffTrack(old, track); changed {
+			diff.Changed = append(diff.Changed, trackDiff)
+		}
+	}
+	diff.Reordered = reordered(a, b)
+
+	return diff
+}
+
+// reordered returns whether the tracks which are in both patterns are in a
+// different order in b
+func reordered(a, b *Pattern) bool {
+	var order []int
+	for _, track := range a.Tracks {
+		if b.FindTrackByID(track.ID) != nil {
+			order = append(order, track.ID)
+		}
+	}
+
+	i := 0
+	for _, track := range b.Tracks {
+		if a.FindTrackByID(track.ID) == nil {
+			continue
+		}
+		if order[i] != track.ID {
+			return true
+		}
+		i++
+	}
+
+	return false
+}
+
+// diffTrack compares all steps of two tracks with the same ID. Steps past
+// the end of the shorter track count as inactive.
+func diffTrack(a, b *Track) (TrackDiff, bool) {
+	diff := TrackDiff{
+		ID:            b.ID,
+		OldName:       a.Name,
+		NewName:       b.Name,
+		OldLength:     a.Length(),
+		NewLength:     b.Length(),
+		Activated:     []int{},
+		Silenced:      []int{},
+		Velocities:    []int{},
+		Probabilities: []int{},
+	}
+	steps, other := a.AllSteps(), b.AllSteps()
+	for len(steps) < len(other) {
+		steps = append(steps, false)
+	}
+	for len(other) < len(steps) {
+		other = append(other, false)
+	}
+
+	for i := range steps {
+		switch {
+		case !steps[i] && other[i]:
+			diff.Activated = append(diff.Activated, i)
+		case steps[i] && !other[i]:
+			diff.Silenced = append(diff.Silenced, i)
+		}
+	}
+	for i := range a.Velocities {
+		if a.Velocities[i] != b.Velocities[i] {
+			diff.Velocities = append(diff.Velocities, i)
+		}
+		if a.ProbabilitySteps[i] != b.ProbabilitySteps[i] {
+			diff.Probabilities = append(diff.Probabilities, i)
+		}
+	}
+
+	changed := diff.OldName != diff.NewName || diff.OldLength != diff.NewLength ||
+		len(diff.Activated) > 0 || len(diff.Silenced) > 0 ||
+		len(diff.Velocities) > 0 || len(diff.Probabilities) > 0
+	return diff, changed
+}
+
+// Empty returns whether the patterns of the diff are the same
+func (diff *PatternDiff) Empty() bool {
+	return diff.OldVersion == diff.NewVersion && diff.OldTempo == diff.NewTempo &&
+		len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0 && !diff.Reordered
+}
+
+// String returns the diff in a human readable form, like:
+//
+//	Tempo: 120 -> 98.4
+//	+ (7) rim	|----|----|----|---x|
+//	- (1) snare	|----|x---|----|x---|
+//	~ (0) kick: activated 2 6; silenced 4
+//	Track order changed
+func (diff *PatternDiff) String() string {
+	var output strings.Builder
+	if diff.OldVersion != diff.NewVersion {
+		fmt.Fprintf(&output, "Saved with HW Version: %s -> %s\n", diff.OldVersion, diff.NewVersion)
+	}
+	if diff.OldTempo != diff.NewTempo {
+		fmt.Fprintf(&output, "Tempo: %g -> %g\n", diff.OldTempo, diff.NewTempo)
+	}
+	for _, track := range diff.Added {
+		fmt.Fprintf(&output, "+ %s\n", track)
+	}
+	for _, track := range diff.Removed {
+		fmt.Fprintf(&output, "- %s\n", track)
+	}
+	for _, track := range diff.Changed {
+		var changes []string
+		if track.OldName != track.NewName {
+			changes = append(changes, "renamed from "+track.OldName)
+		}
+		if track.OldLength != track.NewLength {
+			changes = append(changes, fmt.Sprintf("length %d -> %d", track.OldLength, track.NewLength))
+		}
+		if len(track.Activated) > 0 {
+			changes = append(changes, "activated "+joinInts(track.Activated))
+		}
+		if len(track.Silenced) > 0 {
+			changes = append(changes, "silenced "+joinInts(track.Silenced))
+		}
+		if len(track.Velocities) > 0 {
+			changes = append(changes, "velocities "+joinInts(track.Velocities))
+		}
+		if len(track.Probabilities) > 0 {
+			changes = append(changes, "probabilities "+joinInts(track.Probabilities))
+		}
+		fmt.Fprintf(&output, "~ (%d) %s: %s\n", track.ID, track.NewName, strings.Join(changes, "; "))
+	}
+	if diff.Reordered {
+		output.WriteString("Track order changed\n")
+	}
+
+	return output.String()
+}
+
+// joinInts joins the values with spaces
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = fmt.Sprint(v)
+	}
+
+	return strings.Join(s, " ")
+}
+
+// Equal returns whether the patterns have the same version, tempo and
+// tracks in the same order, including the velocities and probabilities of
+// the steps. Equal patterns have the same Checksum, but unlike comparing the
+// encoded files, null padding of the version doesn't matter.
+func Equal(a, b *Pattern) bool {
+	return Diff(a, b).Empty()
+}
@@ -0,0 +1,99 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := testPattern()
+	b := testPattern()
+	b.Tempo = 98.4
+	b.RemoveTrack(1)
+	b.Tracks[0].SetStep(2, true)
+	b.Tracks[0].SetStep(4, false)
+	b.Tracks[1].Name = "hh-closed"
+	b.Tracks = append(b.Tracks, &Track{ID: 7, Name: "rim", Steps: [16]bool{15: true}})
+
+	diff := Diff(a, b)
+	if diff.Empty() {
+		t.Fatalf("expected a non-empty diff")
+	}
+	if diff.OldTempo != 120 || diff.NewTempo != 98.4 {
+		t.Fatalf("unexpected tempos %v -> %v", diff.OldTempo, diff.NewTempo)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].ID != 7 || len(diff.Removed) != 1 || diff.Removed[0].ID != 1 {
+		t.Fatalf("unexpected added %v and removed %v tracks", diff.Added, diff.Removed)
+	}
+	if len(diff.Changed) != 2 {
+		t.Fatalf("expected 2 changed tracks, got %+v", diff.Changed)
+	}
+
+	expected := `Tempo: 120 -> 98.4
++ (7) rim	|----|----|----|---x|
+- (1) snare	|----|x---|----|x---|
+~ (0) kick: activated 2; silenced 4
+~ (3) hh-closed: renamed from hh-open
+`
+	if diff.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, diff)
+	}
+
+	data, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatalf("something went wrong marshalling - %v", err)
+	}
+	if !bytes.Contains(data, []byte(`"activated":[2],"silenced":[4]`)) {
+		t.Fatalf("unexpected JSON %s", data)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	pattern := testPattern()
+	if !Equal(pattern, testPattern()) {
+		t.Fatalf("expected equal patterns")
+	}
+
+	// Decoding trims the null padding of the version
+	decoded, err := Decode(bytes.NewReader(pattern.Bytes()))
+	if err != nil {
+		t.Fatalf("something went wrong decoding - %v", err)
+	}
+	if !Equal(pattern, decoded) {
+		t.Fatalf("expected the decoded pattern to be equal:\n%s", Diff(pattern, decoded))
+	}
+
+	padded := testPattern()
+	padded.Version += "\x00\x00"
+	if !Equal(pattern, padded) {
+		t.Fatalf("expected the version padding to be ignored")
+	}
+
+	reordered := testPattern()
+	reordered.Tracks[0], reordered.Tracks[1] = reordered.Tracks[1], reordered.Tracks[0]
+	if Equal(pattern, reordered) {
+		t.Fatalf("expected patterns with a different track order to differ")
+	}
+	if diff := Diff(pattern, reordered); diff.String() != "Track order changed\n" {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+
+	accented := testPattern()
+	accented.Tracks[0].SetStepValue(4, Step{On: true, Velocity: 64, Probability: 0.5})
+	if Equal(pattern, accented) {
+		t.Fatalf("expected patterns with different velocities to differ")
+	}
+	if diff := Diff(pattern, accented); diff.String() != "~ (0) kick: velocities 4; probabilities 4\n" {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+
+	longer := testPattern()
+	longer.SetLength(32)
+	if Equal(pattern, longer) {
+		t.Fatalf("expected patterns of different length to differ")
+	}
+	if Equal(pattern, nil) || !Equal(nil, nil) {
+		t.Fatalf("unexpected result comparing nil patterns")
+	}
+}
@@ -2,12 +2,41 @@ package drum
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/binary"
+	"hash/crc32"
 	"io"
+	"os"
 )
 
+// ChecksumTrailer starts the optional trailer after the content of a .splice
+// file. It is followed by the big endian IEEE CRC32 of the file before the
+// trailer.
+const ChecksumTrailer = "CRC32"
+
+// checksumTrailerSize is the size of the trailer including the checksum
+const checksumTrailerSize = len(ChecksumTrailer) + 4
+
 // Encode a pattern into binary data
 func (pattern *Pattern) Encode() io.Reader {
+	return bytes.NewBuffer(pattern.Bytes())
+}
+
+// Bytes returns the pattern encoded in the .splice binary format. The tracks
+// are written by the codec of the pattern version, see CodecFor. Errors are
+// ignored, use MarshalBinary to get them. Versions longer than 32 bytes
+// can't be encoded, Bytes returns nil for them.
+func (pattern *Pattern) Bytes() []byte {
+	data, _ := pattern.encode()
+	return data
+}
+
+// encode returns the pattern in the .splice binary format
+func (pattern *Pattern) encode() ([]byte, error) {
+	if len(pattern.Version) > 32 {
+		return nil, ErrVersionTooLong
+	}
+
 	buf := new(bytes.Buffer)
 	buf.WriteString("SPLICE")
 
@@ -21,22 +50,123 @@ func (pattern *Pattern) Encode() io.Reader {
 	binary.Write(contentbuf, binary.LittleEndian, pattern.Tempo)
 
 	// Write tracks
-	for _, track := range pattern.Tracks {
-		binary.Write(contentbuf, binary.LittleEndian, int32(track.ID))
-		binary.Write(contentbuf, binary.LittleEndian, int8(len(track.Name)))
-		contentbuf.WriteString(track.Name)
-		for _, step := range track.Steps {
-			if step {
-				binary.Write(contentbuf, binary.LittleEndian, int8(1))
-			} else {
-				binary.Write(contentbuf, binary.LittleEndian, int8(0))
-			}
-		}
-	}
+	err := CodecFor(pattern.Version).WriteTracks(contentbuf, pattern)
 
 	// Write contentlength and content to buffer
 	binary.Write(buf, binary.BigEndian, int64(contentbuf.Len()))
 	contentbuf.WriteTo(buf)
 
-	return buf
+	return buf.Bytes(), err
+}
+
+// WriteTo writes the pattern in the .splice binary format to w. It implements
+// io.WriterTo.
+func (pattern *Pattern) WriteTo(w io.Writer) (int64, error) {
+	data, err := pattern.encode()
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// Encode validates the pattern and writes it to w in the .splice binary
+// format.
+func Encode(pattern *Pattern, w io.Writer) error {
+	if err := pattern.Validate(); err != nil {
+		return err
+	}
+
+	data, err := pattern.encode()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// EncodeFile validates the pattern and writes it to the file at path in the
+// .splice binary format.
+func EncodeFile(path string, pattern *Pattern) error {
+	if err := pattern.Validate(); err != nil {
+		return err
+	}
+
+	data, err := pattern.encode()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// WriteToFile writes the pattern to the file at path. This is the idiomatic
+// way to persist a pattern; it is equivalent to EncodeFile(path, pattern).
+func (pattern *Pattern) WriteToFile(path string) error {
+	return EncodeFile(path, pattern)
+}
+
+// MarshalBinary validates the pattern and returns it in the .splice binary
+// format. It implements encoding.BinaryMarshaler.
+func (pattern *Pattern) MarshalBinary() ([]byte, error) {
+	if err := pattern.Validate(); err != nil {
+		return nil, err
+	}
+
+	return pattern.encode()
+}
+
+// EncodeBase64 returns the .splice binary format of the pattern encoded as
+// standard base64.
+func (pattern *Pattern) EncodeBase64() (string, error) {
+	data, err := pattern.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Checksum returns the IEEE CRC32 of the .splice binary format of the
+// pattern, patterns with the same content have the same checksum.
+func (pattern *Pattern) Checksum() (uint32, error) {
+	data, err := pattern.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+
+	return crc32.ChecksumIEEE(data), nil
+}
+
+// BytesWithChecksum returns the pattern encoded like Bytes, followed by a
+// checksum trailer. Decode ignores the trailer, a Decoder verifies it with
+// VerifyChecksum.
+func (pattern *Pattern) BytesWithChecksum() []byte {
+	return appendChecksum(pattern.Bytes())
+}
+
+// appendChecksum appends the checksum trailer of the encoded pattern in data
+func appendChecksum(data []byte) []byte {
+	checksum := crc32.ChecksumIEEE(data)
+	data = append(data, ChecksumTrailer...)
+
+	return binary.BigEndian.AppendUint32(data, checksum)
+}
+
+// EncodeWithChecksum validates the pattern and writes it to w like Encode,
+// with a checksum trailer.
+func EncodeWithChecksum(pattern *Pattern, w io.Writer) error {
+	if err := pattern.Validate(); err != nil {
+		return err
+	}
+
+	data, err := pattern.encode()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(appendChecksum(data))
+	return err
 }
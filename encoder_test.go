@@ -2,6 +2,11 @@ package drum
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
 	"testing"
 )
 
@@ -51,3 +56,127 @@ func TestEncodePattern(t *testing.T) {
 		t.Fatalf("Encoded data not valid")
 	}
 }
+
+func TestWriteTo(t *testing.T) {
+	pattern := &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   98.4,
+		Tracks: []*Track{
+			&Track{ID: 0, Name: "kick", Steps: [16]bool{0: true, 8: true}},
+		},
+	}
+
+	var buf bytes.Buffer
+	n, err := pattern.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("something went wrong writing %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Fatalf("expected %d bytes written, got %d", buf.Len(), n)
+	}
+	if !bytes.Equal(buf.Bytes(), pattern.Bytes()) {
+		t.Fatalf("written data doesn't match Bytes()")
+	}
+
+	// The version is stored in 32 bytes
+	pattern.Version = strings.Repeat("v", 33)
+	if _, err := pattern.WriteTo(&buf); !errors.Is(err, ErrVersionTooLong) {
+		t.Fatalf("expected ErrVersionTooLong, got %v", err)
+	}
+	if data := pattern.Bytes(); data != nil {
+		t.Fatalf("expected no data for a version of 33 bytes, got %d bytes", len(data))
+	}
+}
+
+func TestWriteToFile(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_2.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	filename := path.Join(t.TempDir(), "pattern.splice")
+	if err := pattern.WriteToFile(filename); err != nil {
+		t.Fatalf("something went wrong writing %v", err)
+	}
+	decoded, err := DecodeFile(filename)
+	if err != nil {
+		t.Fatalf("something went wrong decoding the written file %v", err)
+	}
+	if decoded.String() != pattern.String() {
+		t.Fatalf("written pattern doesn't match.\nGot:\n%s\nExpected:\n%s", decoded, pattern)
+	}
+}
+
+func TestBase64RoundTrip(t *testing.T) {
+	for i := 1; i <= 5; i++ {
+		name := path.Join("fixtures", fmt.Sprintf("pattern_%d.splice", i))
+		pattern, err := DecodeFile(name)
+		if err != nil {
+			t.Fatalf("something went wrong decoding %s - %v", name, err)
+		}
+
+		encoded, err := pattern.EncodeBase64()
+		if err != nil {
+			t.Fatalf("something went wrong encoding %s - %v", name, err)
+		}
+		var decoded Pattern
+		if err := decoded.DecodeBase64(encoded); err != nil {
+			t.Fatalf("something went wrong decoding base64 of %s - %v", name, err)
+		}
+		if decoded.String() != pattern.String() {
+			t.Fatalf("%s didn't survive the round trip.\nGot:\n%s\nExpected:\n%s", name, &decoded, pattern)
+		}
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding - %v", err)
+	}
+
+	checksum, err := pattern.Checksum()
+	if err != nil {
+		t.Fatalf("something went wrong computing the checksum - %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if again, _ := pattern.Checksum(); again != checksum {
+			t.Fatalf("checksum changed from %08x to %08x", checksum, again)
+		}
+	}
+	if clone, _ := pattern.Clone().Checksum(); clone != checksum {
+		t.Fatalf("expected clone checksum %08x, got %08x", checksum, clone)
+	}
+
+	pattern.Tracks[0].Steps[1] = !pattern.Tracks[0].Steps[1]
+	if changed, _ := pattern.Checksum(); changed == checksum {
+		t.Fatalf("expected checksum to change after changing a step")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	for i := 1; i <= 5; i++ {
+		name := path.Join("fixtures", fmt.Sprintf("pattern_%d.splice", i))
+		pattern, err := DecodeFile(name)
+		if err != nil {
+			t.Fatalf("something went wrong decoding %s - %v", name, err)
+		}
+		pattern.Tracks[0].Steps[0] = !pattern.Tracks[0].Steps[0]
+
+		var buf bytes.Buffer
+		if err := Encode(pattern, &buf); err != nil {
+			t.Fatalf("something went wrong encoding %s - %v", name, err)
+		}
+		decoded, err := Decode(&buf)
+		if err != nil {
+			t.Fatalf("something went wrong decoding the encoded %s - %v", name, err)
+		}
+		if decoded.String() != pattern.String() {
+			t.Fatalf("%s didn't survive the round trip.\nGot:\n%s\nExpected:\n%s", name, decoded, pattern)
+		}
+	}
+
+	if err := Encode(&Pattern{Version: "0.808-alpha", Tempo: 0}, io.Discard); !errors.Is(err, ErrInvalidTempo) {
+		t.Fatalf("expected ErrInvalidTempo, got %v", err)
+	}
+}
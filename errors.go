@@ -0,0 +1,38 @@
+package drum
+
+import "errors"
+
+// Errors returned when a pattern or track does not fit the .splice format.
+var (
+	ErrDuplicateTrackID     = errors.New("Duplicate track ID")
+	ErrInvalidTrackID       = errors.New("Track ID out of range")
+	ErrEmptyTrackName       = errors.New("Track name is empty")
+	ErrTrackNameTooLong     = errors.New("Track name is longer than 127 bytes")
+	ErrVersionTooLong       = errors.New("Version is longer than 32 bytes")
+	ErrInvalidVersionString = errors.New("Version contains null bytes")
+	ErrInvalidTempo         = errors.New("Tempo out of range")
+	ErrNilTrack             = errors.New("Track is nil")
+	ErrTrackNotFound        = errors.New("Track not found")
+	ErrNilPattern           = errors.New("Pattern is nil")
+	ErrStepOutOfRange       = errors.New("Step index out of range")
+	ErrInvalidSteps         = errors.New("Invalid step string")
+	ErrStepNotFound         = errors.New("Step not found")
+	ErrMIDINoteNotSet       = errors.New("MIDI note not set")
+	ErrInvalidFormat        = errors.New("Invalid format")
+	ErrChecksumMismatch     = errors.New("Checksum mismatch")
+	ErrEmptyScale           = errors.New("Scale is empty")
+	ErrInvalidStepCount     = errors.New("Unsupported step count")
+	ErrStepCountMismatch    = errors.New("Tracks have different step counts")
+	ErrBadHeader            = errors.New("Invalid file header")
+	ErrContentSizeMismatch  = errors.New("Content size does not match the file")
+	ErrTrailingData         = errors.New("Trailing data after the content")
+	ErrInvalidStep          = errors.New("Invalid step value")
+	ErrTempoMismatch        = errors.New("Patterns have different tempos")
+	ErrInvalidVelocity      = errors.New("Velocity out of range")
+	ErrInvalidProbability   = errors.New("Probability out of range")
+	ErrLimitExceeded        = errors.New("Decoder limit exceeded")
+	ErrPlayerSynced         = errors.New("Player is synced to a clock source")
+	ErrInvalidOSC           = errors.New("Invalid OSC message")
+	ErrSyntax               = errors.New("Syntax error")
+	ErrInvalidDestination   = errors.New("Invalid destination")
+)
@@ -0,0 +1,227 @@
+package drum
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// EncodeFileGzip validates the pattern and writes it to the file at path in
+// the .splice binary format, compressed with gzip. Such files typically use
+// the .splice.gz extension.
+func EncodeFileGzip(path string, pattern *Pattern) error {
+	if err := pattern.Validate(); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := gzip.NewWriter(f)
+	if _, err := pattern.WriteTo(zw); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// DecodeFileGzip decodes the gzip compressed drum machine file found at the
+// provided path. Files which are not compressed are decoded as is.
+func DecodeFileGzip(path string) (*Pattern, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := decompress(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return Decode(r)
+}
+
+// decompress returns a reader decompressing r if it starts with the gzip
+// magic bytes, or a reader returning the data as is otherwise.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil || !bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		return br, nil
+	}
+
+	return gzip.NewReader(br)
+}
+
+// spliceExt is the extension of the archive entries holding patterns
+const spliceExt = ".splice"
+
+// ExportZip writes the named patterns to a zip archive at path, storing every
+// pattern as a .splice file named after its key.
+func ExportZip(path string, patterns map[string]*Pattern) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for _, name := range sortedNames(patterns) {
+		data, err := patterns[name].MarshalBinary()
+		if err != nil {
+			return err
+		}
+		entry, err := zw.Create(name + spliceExt)
+		if err != nil {
+			return err
+		}
+		if _, err := entry.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// ImportZip reads all .splice files in the zip archive at path, keyed by
+// their name without the extension.
+func ImportZip(path string) (map[string]*Pattern, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	patterns := make(map[string]*Pattern)
+	for _, file := range zr.File {
+		if !strings.HasSuffix(file.Name, spliceExt) {
+			continue
+		}
+
+		r, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		pattern, err := Decode(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		patterns[strings.TrimSuffix(file.Name, spliceExt)] = pattern
+	}
+
+	return patterns, nil
+}
+
+// sortedNames returns the keys of patterns in sorted order
+func sortedNames(patterns map[string]*Pattern) []string {
+	names := make([]string, 0, len(patterns))
+	for name := range patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ExportTar writes the named patterns to a tar archive at path, storing every
+// pattern as a .splice file named after its key. The archive is compressed
+// with gzip if path ends with .gz or .tgz.
+func ExportTar(path string, patterns map[string]*Pattern) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var w io.Writer = f
+	var zw *gzip.Writer
+	if strings.HasSuffix(path, ".gz") || strings.HasSuffix(path, ".tgz") {
+		zw = gzip.NewWriter(f)
+		w = zw
+	}
+
+	tw := tar.NewWriter(w)
+	for _, name := range sortedNames(patterns) {
+		data, err := patterns[name].MarshalBinary()
+		if err != nil {
+			return err
+		}
+		header := &tar.Header{
+			Name: name + spliceExt,
+			Mode: 0644,
+			Size: int64(len(data)),
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return err
+		}
+	}
+
+	return f.Close()
+}
+
+// ImportTar reads all .splice files in the tar archive at path, keyed by
+// their name without the extension. Gzip compressed archives are
+// decompressed transparently.
+func ImportTar(path string) (map[string]*Pattern, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := decompress(f)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := make(map[string]*Pattern)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg || !strings.HasSuffix(header.Name, spliceExt) {
+			continue
+		}
+
+		pattern, err := Decode(tr)
+		if err != nil {
+			return nil, err
+		}
+		patterns[strings.TrimSuffix(header.Name, spliceExt)] = pattern
+	}
+
+	return patterns, nil
+}
@@ -0,0 +1,114 @@
+package drum
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncodeFileGzip(t *testing.T) {
+	dir := t.TempDir()
+	for name, pattern := range fixtures(t) {
+		p := filepath.Join(dir, name+".gz")
+		if err := EncodeFileGzip(p, pattern); err != nil {
+			t.Fatalf("something went wrong encoding %s - %v", name, err)
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("something went wrong reading %s - %v", p, err)
+		}
+		if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+			t.Fatalf("%s is not a gzip stream, it starts with % x", p, data[:2])
+		}
+
+		decoded, err := DecodeFileGzip(p)
+		if err != nil {
+			t.Fatalf("something went wrong decoding %s - %v", p, err)
+		}
+		if decoded.String() != pattern.String() {
+			t.Fatalf("%s didn't survive the round trip.\nGot:\n%s\nExpected:\n%s", name, decoded, pattern)
+		}
+	}
+
+	// Uncompressed files are decoded as well
+	if _, err := DecodeFileGzip(filepath.Join("fixtures", "pattern_1.splice")); err != nil {
+		t.Fatalf("something went wrong decoding an uncompressed file - %v", err)
+	}
+}
+
+func TestExportZip(t *testing.T) {
+	all := fixtures(t)
+	patterns := map[string]*Pattern{
+		"first":       all["pattern_1.splice"],
+		"live/second": all["pattern_2.splice"],
+	}
+
+	p := filepath.Join(t.TempDir(), "library.zip")
+	if err := ExportZip(p, patterns); err != nil {
+		t.Fatalf("something went wrong exporting - %v", err)
+	}
+	imported, err := ImportZip(p)
+	if err != nil {
+		t.Fatalf("something went wrong importing - %v", err)
+	}
+
+	if len(imported) != len(patterns) {
+		t.Fatalf("expected %d patterns, got %d", len(patterns), len(imported))
+	}
+	for name, pattern := range patterns {
+		if imported[name] == nil || imported[name].String() != pattern.String() {
+			t.Fatalf("%s didn't survive the round trip.\nGot:\n%v\nExpected:\n%s", name, imported[name], pattern)
+		}
+	}
+}
+
+func TestExportTar(t *testing.T) {
+	all := fixtures(t)
+	patterns := map[string]*Pattern{
+		"first":       all["pattern_1.splice"],
+		"live/second": all["pattern_2.splice"],
+	}
+
+	for _, name := range []string{"library.tar", "library.tar.gz"} {
+		p := filepath.Join(t.TempDir(), name)
+		if err := ExportTar(p, patterns); err != nil {
+			t.Fatalf("something went wrong exporting %s - %v", name, err)
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			t.Fatalf("something went wrong opening %s - %v", name, err)
+		}
+		r, err := decompress(f)
+		if err != nil {
+			t.Fatalf("something went wrong decompressing %s - %v", name, err)
+		}
+		tr := tar.NewReader(r)
+		for _, expected := range []string{"first.splice", "live/second.splice"} {
+			header, err := tr.Next()
+			if err != nil {
+				t.Fatalf("something went wrong reading %s - %v", name, err)
+			}
+			if header.Name != expected {
+				t.Fatalf("expected entry %s in %s, got %s", expected, name, header.Name)
+			}
+		}
+		f.Close()
+
+		imported, err := ImportTar(p)
+		if err != nil {
+			t.Fatalf("something went wrong importing %s - %v", name, err)
+		}
+		if len(imported) != len(patterns) {
+			t.Fatalf("expected %d patterns in %s, got %d", len(patterns), name, len(imported))
+		}
+		for key, pattern := range patterns {
+			if imported[key] == nil || imported[key].String() != pattern.String() {
+				t.Fatalf("%s didn't survive the round trip.\nGot:\n%v\nExpected:\n%s", key, imported[key], pattern)
+			}
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExportSOUL writes the pattern as SOUL source code: a processor emitting a
+// MIDI note on channel 10 for every active step, looping at the pattern
+// tempo. The steps of every track are stored in a constant bool array.
+func (pattern *Pattern) ExportSOUL(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "// Drum pattern, saved with HW version %s\n", pattern.Version)
+	buf.WriteString("processor DrumPattern\n{\n")
+	buf.WriteString("    output event soul::midi::Message midiOut;\n\n")
+	fmt.Fprintf(buf, "    let tempo = %sf;\n", formatFloat(pattern.Tempo))
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "    let %s = bool[%d] (%s);\n",
+			trackIdentifier(track), pattern.Length(), joinSteps(track, "true", "false", ", "))
+	}
+
+	buf.WriteString("\n    void run()\n    {\n")
+	fmt.Fprintf(buf, "        let samplesPerStep = int (processor.frequency * 60.0 / (tempo * %d.0));\n", StepsPerBeat)
+	fmt.Fprintf(buf, "        wrap<%d> step;\n\n", pattern.Length())
+	buf.WriteString("        loop\n        {\n")
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "            if (%s[step]) midiOut << soul::midi::createMessage (0x99, %d, 100);\n",
+			trackIdentifier(track), trackNote(track))
+	}
+	buf.WriteString("\n            loop (samplesPerStep)\n                advance();\n\n")
+	buf.WriteString("            step++;\n        }\n    }\n}\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// trackIdentifier returns an identifier for the track which is valid in most
+// programming languages, like track0_hh_open.
+func trackIdentifier(track *Track) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, track.Name)
+
+	return fmt.Sprintf("track%d_%s", track.ID, name)
+}
+
+// joinSteps joins all steps of the track using on and off for active and
+// inactive steps.
+func joinSteps(track *Track, on, off, sep string) string {
+	values := make([]string, track.Length())
+	for i, step := range track.AllSteps() {
+		if step {
+			values[i] = on
+		} else {
+			values[i] = off
+		}
+	}
+
+	return strings.Join(values, sep)
+}
+
+// formatFloat formats the value with at least one decimal, like 120.0
+func formatFloat(v float32) string {
+	s := fmt.Sprintf("%g", v)
+	if !strings.ContainsAny(s, ".eEn") {
+		s += ".0"
+	}
+
+	return s
+}
+
+// ExportTidalCycles writes the pattern as TidalCycles code: a stack of sound
+// patterns in mini-notation, one per track, using the track names as sample
+// names. The cycle length is set to the length of the pattern at its tempo.
+func (pattern *Pattern) ExportTidalCycles(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "setcps (%g/60/%d)\n\n", pattern.Tempo, pattern.BeatCount())
+	buf.WriteString("d1 $ stack [\n")
+	for i, track := range pattern.Tracks {
+		name := strings.Join(strings.Fields(track.Name), "_")
+		fmt.Fprintf(buf, "  sound \"%s\"", joinSteps(track, name, "~", " "))
+		if i < len(pattern.Tracks)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ExportPdPatch writes the pattern as a Pure Data patch. A metro drives a
+// step counter which is matched against the active steps of every track,
+// sending the track note to noteout on channel 10.
+func (pattern *Pattern) ExportPdPatch(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	var connections []string
+	objects := 0
+	object := func(format string, args ...interface{}) int {
+		fmt.Fprintf(buf, format+";\n", args...)
+		objects++
+		return objects - 1
+	}
+	connect := func(from, outlet, to, inlet int) {
+		connections = append(connections, fmt.Sprintf("#X connect %d %d %d %d;\n", from, outlet, to, inlet))
+	}
+
+	buf.WriteString("#N canvas 0 50 800 600 12;\n")
+	loadbang := object("#X obj 10 10 loadbang")
+	metro := object("#X obj 10 40 metro %g", 60000/(pattern.Tempo*StepsPerBeat))
+	counter := object("#X obj 10 70 f")
+	increment := object("#X obj 60 70 + 1")
+	wrap := object("#X obj 60 100 mod %d", pattern.Length())
+	connect(loadbang, 0, metro, 0)
+	connect(metro, 0, counter, 0)
+	connect(counter, 0, increment, 0)
+	connect(increment, 0, wrap, 0)
+	connect(wrap, 0, counter, 1)
+
+	x := 10
+	for _, track := range pattern.Tracks {
+		var active []string
+		for i, step := range track.AllSteps() {
+			if step {
+				active = append(active, fmt.Sprint(i))
+			}
+		}
+		if len(active) == 0 {
+			continue
+		}
+
+		object("#X text %d 140 %s", x, pdEscape(track.Name))
+		sel := object("#X obj %d 170 sel %s", x, strings.Join(active, " "))
+		msg := object("#X msg %d 200 %d 100", x, trackNote(track))
+		out := object("#X obj %d 230 noteout 10", x)
+		connect(counter, 0, sel, 0)
+		for i := range active {
+			connect(sel, i, msg, 0)
+		}
+		connect(msg, 0, out, 0)
+		x += 120
+	}
+
+	buf.WriteString(strings.Join(connections, ""))
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// pdEscape escapes the characters with a special meaning in Pd messages
+func pdEscape(s string) string {
+	return strings.NewReplacer(";", "\\;", ",", "\\,", "$", "\\$").Replace(s)
+}
+
+type maxPatcher struct {
+	Patcher maxPatcherContent `json:"patcher"`
+}
+
+type maxPatcherContent struct {
+	FileVersion int            `json:"fileversion"`
+	AppVersion  map[string]int `json:"appversion"`
+	Rect        [4]int         `json:"rect"`
+	Boxes       []maxBoxEntry  `json:"boxes"`
+	Lines       []maxLineEntry `json:"lines"`
+}
+
+type maxBoxEntry struct {
+	Box maxBox `json:"box"`
+}
+
+type maxBox struct {
+	ID           string   `json:"id"`
+	MaxClass     string   `json:"maxclass"`
+	Text         string   `json:"text,omitempty"`
+	NumInlets    int      `json:"numinlets"`
+	NumOutlets   int      `json:"numoutlets"`
+	OutletType   []string `json:"outlettype"`
+	PatchingRect [4]int   `json:"patching_rect"`
+	Columns      int      `json:"columns,omitempty"`
+	Rows         int      `json:"rows,omitempty"`
+}
+
+type maxLineEntry struct {
+	PatchLine maxPatchLine `json:"patchline"`
+}
+
+type maxPatchLine struct {
+	Source      [2]interface{} `json:"source"`
+	Destination [2]interface{} `json:"destination"`
+}
+
+// ExportMAX writes the pattern as a Max/MSP patcher. A matrixctrl with a row
+// per track and a column per step is loaded with the active steps, and a
+// metro running at the pattern tempo steps through its columns.
+func (pattern *Pattern) ExportMAX(w io.Writer) error {
+	rows := len(pattern.Tracks)
+	if rows == 0 {
+		rows = 1
+	}
+
+	var cells []string
+	for row, track := range pattern.Tracks {
+		for column, step := range track.AllSteps() {
+			if step {
+				cells = append(cells, fmt.Sprintf("%d %d 1", column, row))
+			}
+		}
+	}
+
+	boxes := []maxBox{
+		{ID: "obj-1", MaxClass: "toggle", NumInlets: 1, NumOutlets: 1, OutletType: []string{"int"}, PatchingRect: [4]int{20, 20, 24, 24}},
+		{ID: "obj-2", MaxClass: "newobj", Text: fmt.Sprintf("metro %g", 60000/(pattern.Tempo*StepsPerBeat)), NumInlets: 2, NumOutlets: 1, OutletType: []string{"bang"}, PatchingRect: [4]int{20, 60, 80, 22}},
+		{ID: "obj-3", MaxClass: "newobj", Text: fmt.Sprintf("counter 0 %d", pattern.Length()-1), NumInlets: 5, NumOutlets: 4, OutletType: []string{"int", "", "", "int"}, PatchingRect: [4]int{20, 100, 80, 22}},
+		{ID: "obj-4", MaxClass: "newobj", Text: "prepend getcolumn", NumInlets: 1, NumOutlets: 1, OutletType: []string{""}, PatchingRect: [4]int{20, 140, 110, 22}},
+		{ID: "obj-5", MaxClass: "newobj", Text: "loadbang", NumInlets: 1, NumOutlets: 1, OutletType: []string{"bang"}, PatchingRect: [4]int{200, 20, 60, 22}},
+		{ID: "obj-6", MaxClass: "message", Text: strings.Join(append([]string{"clear"}, cells...), ", "), NumInlets: 2, NumOutlets: 1, OutletType: []string{""}, PatchingRect: [4]int{200, 60, 300, 22}},
+		{ID: "obj-7", MaxClass: "matrixctrl", NumInlets: 1, NumOutlets: 2, OutletType: []string{"list", "list"}, PatchingRect: [4]int{20, 180, 16 * pattern.Length(), 16 * rows}, Columns: pattern.Length(), Rows: rows},
+	}
+	links := [][4]interface{}{
+		{"obj-1", 0, "obj-2", 0},
+		{"obj-2", 0, "obj-3", 0},
+		{"obj-3", 0, "obj-4", 0},
+		{"obj-4", 0, "obj-7", 0},
+		{"obj-5", 0, "obj-6", 0},
+		{"obj-6", 0, "obj-7", 0},
+	}
+
+	patcher := maxPatcher{maxPatcherContent{
+		FileVersion: 1,
+		AppVersion:  map[string]int{"major": 8, "minor": 0, "revision": 0},
+		Rect:        [4]int{100, 100, 640, 480},
+	}}
+	for _, box := range boxes {
+		patcher.Patcher.Boxes = append(patcher.Patcher.Boxes, maxBoxEntry{box})
+	}
+	for _, link := range links {
+		patcher.Patcher.Lines = append(patcher.Patcher.Lines, maxLineEntry{maxPatchLine{
+			Source:      [2]interface{}{link[0], link[1]},
+			Destination: [2]interface{}{link[2], link[3]},
+		}})
+	}
+
+	data, err := json.MarshalIndent(patcher, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// ExportFaust writes the pattern as FAUST code. Every track becomes an
+// output producing an impulse on each of its active steps at the pattern
+// tempo, ready to drive a drum synthesizer or sample player.
+func (pattern *Pattern) ExportFaust(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "// Drum pattern, saved with HW version %s\n", pattern.Version)
+	buf.WriteString("import(\"stdfaust.lib\");\n\n")
+	fmt.Fprintf(buf, "tempo = %g;\n", pattern.Tempo)
+	fmt.Fprintf(buf, "stepSamples = int(ma.SR * 60 / (tempo * %d));\n", StepsPerBeat)
+	buf.WriteString("tick = ba.pulse(stepSamples);\n")
+	// The counter is incremented on every tick, shift it back so the first
+	// tick plays step 0
+	steps := pattern.Length()
+	fmt.Fprintf(buf, "step = (tick : (+ : %%(%d)) ~ _) + %d : %%(%d);\n\n", steps, steps-1, steps)
+
+	var outputs []string
+	for _, track := range pattern.Tracks {
+		name := trackIdentifier(track)
+		fmt.Fprintf(buf, "%s = (%s) : ba.selectn(%d, step) : *(tick);\n",
+			name, joinSteps(track, "1", "0", ","), steps)
+		outputs = append(outputs, name)
+	}
+	if len(outputs) == 0 {
+		outputs = append(outputs, "0")
+	}
+
+	fmt.Fprintf(buf, "\nprocess = %s;\n", strings.Join(outputs, ", "))
+
+	_, err := buf.WriteTo(w)
+	return err
+}
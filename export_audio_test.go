@@ -0,0 +1,148 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestExportSOUL(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 1, Name: "kick", Steps: [16]bool{0: true, 4: true, 8: true, 12: true}},
+	}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportSOUL(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+
+	expected := `// Drum pattern, saved with HW version 0.808-alpha
+processor DrumPattern
+{
+    output event soul::midi::Message midiOut;
+
+    let tempo = 120.0f;
+    let track1_kick = bool[16] (true, false, false, false, true, false, false, false, true, false, false, false, true, false, false, false);
+
+    void run()
+    {
+        let samplesPerStep = int (processor.frequency * 60.0 / (tempo * 4.0));
+        wrap<16> step;
+
+        loop
+        {
+            if (track1_kick[step]) midiOut << soul::midi::createMessage (0x99, 36, 100);
+
+            loop (samplesPerStep)
+                advance();
+
+            step++;
+        }
+    }
+}
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output.\nGot:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestExportTidalCycles(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 1, Name: "kick", Steps: [16]bool{0: true, 4: true, 8: true, 12: true}},
+		&Track{ID: 2, Name: "Low Conga", Steps: [16]bool{6: true}},
+	}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportTidalCycles(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+
+	expected := `setcps (120/60/4)
+
+d1 $ stack [
+  sound "kick ~ ~ ~ kick ~ ~ ~ kick ~ ~ ~ kick ~ ~ ~",
+  sound "~ ~ ~ ~ ~ ~ Low_Conga ~ ~ ~ ~ ~ ~ ~ ~ ~"
+]
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output.\nGot:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestExportPdPatch(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportPdPatch(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+
+	lines := strings.SplitN(buf.String(), "\n", 3)
+	if lines[0] != "#N canvas 0 50 800 600 12;" || lines[1] != "#X obj 10 10 loadbang;" {
+		t.Fatalf("unexpected start of patch:\n%s\n%s", lines[0], lines[1])
+	}
+	if !strings.Contains(buf.String(), "#X obj 10 40 metro 125;") {
+		t.Fatalf("expected a metro of 125ms for 120 BPM:\n%s", buf.String())
+	}
+}
+
+func TestExportMAX(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportMAX(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+
+	var parsed map[string]map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("output isn't valid JSON - %v", err)
+	}
+	patcher, ok := parsed["patcher"]
+	if !ok {
+		t.Fatalf("patcher key not found")
+	}
+	if _, ok := patcher["boxes"]; !ok {
+		t.Fatalf("boxes key not found")
+	}
+	if !strings.Contains(buf.String(), `"metro 125"`) {
+		t.Fatalf("expected a metro of 125ms for 120 BPM")
+	}
+}
+
+func TestExportFaust(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_5.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportFaust(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+
+	expected := `// Drum pattern, saved with HW version 0.708-alpha
+import("stdfaust.lib");
+
+tempo = 999;
+stepSamples = int(ma.SR * 60 / (tempo * 4));
+tick = ba.pulse(stepSamples);
+step = (tick : (+ : %(16)) ~ _) + 15 : %(16);
+
+track1_Kick = (1,0,0,0,0,0,0,0,1,0,0,0,0,0,0,0) : ba.selectn(16, step) : *(tick);
+track2_HiHat = (1,0,1,0,1,0,1,0,1,0,1,0,1,0,1,0) : ba.selectn(16, step) : *(tick);
+
+process = track1_Kick, track2_HiHat;
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output.\nGot:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,317 @@
+package drum
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ExportPython writes the pattern as a Python 3 script which uses mido to
+// write the pattern to pattern.mid, with notes on channel 10.
+func (pattern *Pattern) ExportPython(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	buf.WriteString("import mido\n\n")
+	fmt.Fprintf(buf, "# Drum pattern, saved with HW version %s\n", pattern.Version)
+	fmt.Fprintf(buf, "TEMPO = %g\n", pattern.Tempo)
+	buf.WriteString("TICKS_PER_BEAT = 480\n")
+	fmt.Fprintf(buf, "TICKS_PER_STEP = TICKS_PER_BEAT // %d\n\n", StepsPerBeat)
+
+	buf.WriteString("tracks = [\n")
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "    (%s, %d, [%s]),\n", strconv.Quote(track.Name), trackNote(track), joinSteps(track, "1", "0", ", "))
+	}
+	buf.WriteString("]\n")
+
+	buf.WriteString(`
+mid = mido.MidiFile(ticks_per_beat=TICKS_PER_BEAT)
+track = mido.MidiTrack()
+mid.tracks.append(track)
+track.append(mido.MetaMessage("set_tempo", tempo=mido.bpm2tempo(TEMPO)))
+
+events = []
+for name, note, steps in tracks:
+    for step, active in enumerate(steps):
+        if active:
+            events.append((step * TICKS_PER_STEP, "note_on", note, 100))
+            events.append(((step + 1) * TICKS_PER_STEP - 1, "note_off", note, 0))
+events.sort(key=lambda event: event[0])
+
+last = 0
+for time, kind, note, velocity in events:
+    track.append(mido.Message(kind, channel=9, note=note, velocity=velocity, time=time - last))
+    last = time
+
+mid.save("pattern.mid")
+`)
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ExportRuby writes the pattern as a Ruby script which uses the midilib gem
+// to write the pattern to pattern.mid, with one MIDI track per drum track.
+func (pattern *Pattern) ExportRuby(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	buf.WriteString("#!/usr/bin/env ruby\n")
+	fmt.Fprintf(buf, "# Drum pattern, saved with HW version %s\n", pattern.Version)
+	buf.WriteString("require 'midilib'\n\ninclude MIDI\n\n")
+	fmt.Fprintf(buf, "BPM = %g\n", pattern.Tempo)
+	buf.WriteString("PATTERN = [\n")
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "  [%s, %d, [%s]],\n", rubyQuote(track.Name), trackNote(track), joinSteps(track, "1", "0", ", "))
+	}
+	buf.WriteString("]\n")
+
+	buf.WriteString(`
+seq = Sequence.new
+tempo_track = Track.new(seq)
+seq.tracks << tempo_track
+tempo_track.events << Tempo.new(Tempo.bpm_to_mpq(BPM))
+tempo_track.events << MetaEvent.new(META_SEQ_NAME, 'Drum pattern')
+
+step_length = seq.note_to_delta('sixteenth')
+
+PATTERN.each do |name, note, steps|
+  track = Track.new(seq)
+  seq.tracks << track
+  track.name = name
+  rest = 0
+  steps.each do |active|
+    if active == 1
+      track.events << NoteOn.new(9, note, 100, rest)
+      track.events << NoteOff.new(9, note, 0, step_length)
+      rest = 0
+    else
+      rest += step_length
+    end
+  end
+end
+
+File.open('pattern.mid', 'wb') { |file| seq.write(file) }
+`)
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// rubyQuote returns s as a single quoted Ruby string
+func rubyQuote(s string) string {
+	return "'" + strings.NewReplacer("\\", "\\\\", "'", "\\'").Replace(s) + "'"
+}
+
+// ExportLua writes the pattern as a Lua module returning the pattern as a
+// table, with steps as 1 and 0.
+func (pattern *Pattern) ExportLua(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	buf.WriteString("local pattern = {\n")
+	fmt.Fprintf(buf, "  version = %s,\n", strconv.Quote(pattern.Version))
+	fmt.Fprintf(buf, "  tempo = %g,\n", pattern.Tempo)
+	buf.WriteString("  tracks = {\n")
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "    { id = %d, name = %s, steps = { %s } },\n",
+			track.ID, strconv.Quote(track.Name), joinSteps(track, "1", "0", ", "))
+	}
+	buf.WriteString("  },\n}\n\nreturn pattern\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ExportElixir writes the pattern as an Elixir module holding the pattern as
+// a keyword list in the @pattern attribute. The module is named after the
+// version, like DrumPattern.V0808Alpha.
+func (pattern *Pattern) ExportElixir(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "defmodule DrumPattern.%s do\n", elixirModuleName(pattern.Version))
+	buf.WriteString("  @pattern [\n")
+	fmt.Fprintf(buf, "    version: %s,\n", elixirQuote(pattern.Version))
+	fmt.Fprintf(buf, "    tempo: %s,\n", formatFloat(pattern.Tempo))
+	buf.WriteString("    tracks: [\n")
+	for i, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "      [id: %d, name: %s, steps: [%s]]",
+			track.ID, elixirQuote(track.Name), joinSteps(track, "true", "false", ", "))
+		if i < len(pattern.Tracks)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("    ]\n  ]\n\n  def pattern, do: @pattern\nend\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// elixirModuleName turns a version like 0.808-alpha into V0808Alpha
+func elixirModuleName(version string) string {
+	name := "V"
+	for _, part := range strings.FieldsFunc(version, func(r rune) bool {
+		return !(r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)))
+	}) {
+		name += strings.ToUpper(part[:1]) + part[1:]
+	}
+
+	return name
+}
+
+// elixirQuote returns s as a double quoted Elixir string without
+// interpolation
+func elixirQuote(s string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "#", "\\#", "\n", "\\n").Replace(s) + "\""
+}
+
+// ExportRust writes the pattern as Rust source defining a PATTERN constant.
+// The generated types only use static data, so the code works in no_std
+// crates.
+func (pattern *Pattern) ExportRust(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "// Drum pattern, saved with HW version %s\n\n", pattern.Version)
+	fmt.Fprintf(buf, `pub struct DrumTrack {
+    pub id: u32,
+    pub name: &'static str,
+    pub steps: [bool; %d],
+}
+
+pub struct DrumPattern {
+    pub version: &'static str,
+    pub tempo: f32,
+    pub tracks: &'static [DrumTrack],
+}
+
+`, pattern.Length())
+	buf.WriteString("pub const PATTERN: DrumPattern = DrumPattern {\n")
+	fmt.Fprintf(buf, "    version: %s,\n", rustQuote(pattern.Version))
+	fmt.Fprintf(buf, "    tempo: %s,\n", formatFloat(pattern.Tempo))
+	buf.WriteString("    tracks: &[\n")
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "        DrumTrack { id: %d, name: %s, steps: [%s] },\n",
+			track.ID, rustQuote(track.Name), joinSteps(track, "true", "false", ", "))
+	}
+	buf.WriteString("    ],\n};\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// rustQuote returns s as a Rust string literal
+func rustQuote(s string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			quoted.WriteByte('\\')
+			quoted.WriteRune(r)
+		case unicode.IsPrint(r):
+			quoted.WriteRune(r)
+		default:
+			fmt.Fprintf(&quoted, "\\u{%x}", r)
+		}
+	}
+	quoted.WriteByte('"')
+
+	return quoted.String()
+}
+
+// ExportPulumi writes the pattern as a TypeScript module exporting the
+// pattern as the drumPattern object, ready to be used as a Pulumi config
+// value.
+func (pattern *Pattern) ExportPulumi(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "// Drum pattern, saved with HW version %s\n", pattern.Version)
+	buf.WriteString("export const drumPattern = {\n")
+	fmt.Fprintf(buf, "  version: %s,\n", strconv.Quote(pattern.Version))
+	fmt.Fprintf(buf, "  tempo: %g,\n", pattern.Tempo)
+	buf.WriteString("  tracks: [\n")
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "    { id: %d, name: %s, steps: [%s] },\n",
+			track.ID, strconv.Quote(track.Name), joinSteps(track, "true", "false", ", "))
+	}
+	buf.WriteString("  ],\n};\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ExportNixExpression writes the pattern as a Nix attribute set
+func (pattern *Pattern) ExportNixExpression(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "# Drum pattern, saved with HW version %s\n", pattern.Version)
+	buf.WriteString("{\n")
+	fmt.Fprintf(buf, "  version = %s;\n", nixQuote(pattern.Version))
+	fmt.Fprintf(buf, "  tempo = %g;\n", pattern.Tempo)
+	buf.WriteString("  tracks = [\n")
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "    { id = %d; name = %s; steps = [ %s ]; }\n",
+			track.ID, nixQuote(track.Name), joinSteps(track, "true", "false", " "))
+	}
+	buf.WriteString("  ];\n}\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// nixQuote returns s as a double quoted Nix string, escaping antiquotations
+func nixQuote(s string) string {
+	return `"` + strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+		"${", `\${`,
+	).Replace(s) + `"`
+}
+
+// ExportCUE writes the pattern as a CUE file defining a #Pattern schema with
+// the constraints checked by Validate, and the pattern as a concrete value of
+// that schema.
+func (pattern *Pattern) ExportCUE(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "// Drum pattern, saved with HW version %s\n", pattern.Version)
+	buf.WriteString("package drum\n\n")
+	buf.WriteString("#Track: {\n")
+	buf.WriteString("\tid:    int & >=0 & <=2147483647\n")
+	buf.WriteString("\tname:  string & !=\"\"\n")
+	fmt.Fprintf(buf, "\tsteps: [%s]\n", strings.TrimSuffix(strings.Repeat("bool, ", pattern.Length()), ", "))
+	buf.WriteString("}\n\n")
+	buf.WriteString("#Pattern: {\n")
+	buf.WriteString("\tversion: string\n")
+	buf.WriteString("\ttempo:   number & >0\n")
+	buf.WriteString("\ttracks: [...#Track]\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString("pattern: #Pattern & {\n")
+	fmt.Fprintf(buf, "\tversion: %s\n", strconv.Quote(pattern.Version))
+	fmt.Fprintf(buf, "\ttempo:   %g\n", pattern.Tempo)
+	buf.WriteString("\ttracks: [\n")
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "\t\t{id: %d, name: %s, steps: [%s]},\n",
+			track.ID, strconv.Quote(track.Name), joinSteps(track, "true", "false", ", "))
+	}
+	buf.WriteString("\t]\n}\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ExportStarlark writes the pattern as a Starlark struct assigned to
+// drum_pattern, for use in Bazel and similar build systems.
+func (pattern *Pattern) ExportStarlark(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "# Drum pattern, saved with HW version %s\n", pattern.Version)
+	buf.WriteString("drum_pattern = struct(\n")
+	fmt.Fprintf(buf, "    version = %s,\n", strconv.Quote(pattern.Version))
+	fmt.Fprintf(buf, "    tempo = %s,\n", formatFloat(pattern.Tempo))
+	buf.WriteString("    tracks = [\n")
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "        struct(id = %d, name = %s, steps = [%s]),\n",
+			track.ID, strconv.Quote(track.Name), joinSteps(track, "True", "False", ", "))
+	}
+	buf.WriteString("    ],\n)\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,210 @@
+package drum
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestExportPython(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportPython(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "import mido\n") {
+		t.Fatalf("script doesn't start with import mido")
+	}
+	if !strings.Contains(buf.String(), "TEMPO = 120\n") {
+		t.Fatalf("tempo not found")
+	}
+}
+
+func TestExportRuby(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_2.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportRuby(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	if !strings.Contains(buf.String(), "require 'midilib'\n") {
+		t.Fatalf("require 'midilib' not found")
+	}
+	if !strings.Contains(buf.String(), "BPM = 98.4\n") {
+		t.Fatalf("tempo not found")
+	}
+}
+
+func TestExportLua(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_4.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportLua(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	output := buf.String()
+	if !strings.HasPrefix(output, "local pattern = {\n") {
+		t.Fatalf("output doesn't start with the pattern table")
+	}
+	if strings.Count(output, "{") != strings.Count(output, "}") {
+		t.Fatalf("unbalanced braces:\n%s", output)
+	}
+	if !strings.Contains(output, `{ id = 255, name = "Low Conga", steps = { 0, 0, 0, 0, 1,`) {
+		t.Fatalf("Low Conga track not found:\n%s", output)
+	}
+}
+
+func TestExportElixir(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportElixir(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "defmodule DrumPattern.V0808Alpha do\n") {
+		t.Fatalf("unexpected module definition:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "tempo: 120.0,") {
+		t.Fatalf("tempo not found")
+	}
+}
+
+func TestExportRust(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 1, Name: "kick", Steps: [16]bool{0: true, 4: true, 8: true, 12: true}},
+	}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportRust(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+
+	expected := `// Drum pattern, saved with HW version 0.808-alpha
+
+pub struct DrumTrack {
+    pub id: u32,
+    pub name: &'static str,
+    pub steps: [bool; 16],
+}
+
+pub struct DrumPattern {
+    pub version: &'static str,
+    pub tempo: f32,
+    pub tracks: &'static [DrumTrack],
+}
+
+pub const PATTERN: DrumPattern = DrumPattern {
+    version: "0.808-alpha",
+    tempo: 120.0,
+    tracks: &[
+        DrumTrack { id: 1, name: "kick", steps: [true, false, false, false, true, false, false, false, true, false, false, false, true, false, false, false] },
+    ],
+};
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output.\nGot:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestExportPulumi(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_2.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportPulumi(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "\nexport const drumPattern = {\n") || !strings.Contains(output, "  tempo: 98.4,\n") {
+		t.Fatalf("drumPattern with tempo 98.4 not found:\n%s", output)
+	}
+	if strings.Count(output, "{") != strings.Count(output, "}") || strings.Count(output, "[") != strings.Count(output, "]") {
+		t.Fatalf("unbalanced brackets:\n%s", output)
+	}
+	if !strings.Contains(output, `{ id: 0, name: "kick", steps: [true, false, false, false,`) {
+		t.Fatalf("kick track not found:\n%s", output)
+	}
+}
+
+func TestExportNixExpression(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 1, Name: "${kick}", Steps: [16]bool{0: true, 8: true}},
+	}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportNixExpression(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	expected := `# Drum pattern, saved with HW version 0.808-alpha
+{
+  version = "0.808-alpha";
+  tempo = 120;
+  tracks = [
+    { id = 1; name = "\${kick}"; steps = [ true false false false false false false false true false false false false false false false ]; }
+  ];
+}
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output.\nGot:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestExportCUE(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportCUE(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "\n#Pattern: {\n") || !strings.Contains(output, "\n#Track: {\n") {
+		t.Fatalf("schema definitions not found:\n%s", output)
+	}
+	if !strings.Contains(output, "\npattern: #Pattern & {\n") || !strings.Contains(output, "\ttempo:   120\n") {
+		t.Fatalf("pattern value not found:\n%s", output)
+	}
+	if strings.Count(output, "{id: ") != len(pattern.Tracks) {
+		t.Fatalf("expected %d tracks:\n%s", len(pattern.Tracks), output)
+	}
+}
+
+func TestExportStarlark(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_3.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportStarlark(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "\ndrum_pattern = struct(\n") || !strings.HasSuffix(output, "    ],\n)\n") {
+		t.Fatalf("drum_pattern struct not found:\n%s", output)
+	}
+	if strings.Count(output, "(") != strings.Count(output, ")") || strings.Count(output, "[") != strings.Count(output, "]") {
+		t.Fatalf("unbalanced brackets:\n%s", output)
+	}
+	if strings.Count(output, "struct(id = ") != len(pattern.Tracks) || strings.Contains(output, "true") {
+		t.Fatalf("unexpected tracks:\n%s", output)
+	}
+}
@@ -0,0 +1,327 @@
+package drum
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExportToPropertiesFile writes the pattern in the Java .properties format:
+//
+//	version=0.808-alpha
+//	tempo=120.0
+//	track.0.id=1
+//	track.0.name=kick
+//	track.0.steps=x---x---x---x---
+func (pattern *Pattern) ExportToPropertiesFile(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "version=%s\n", propertiesEscape(pattern.Version))
+	fmt.Fprintf(buf, "tempo=%s\n", formatFloat(pattern.Tempo))
+	for i, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "track.%d.id=%d\n", i, track.ID)
+		fmt.Fprintf(buf, "track.%d.name=%s\n", i, propertiesEscape(track.Name))
+		fmt.Fprintf(buf, "track.%d.steps=%s\n", i, formatSteps(track.AllSteps()))
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ImportFromPropertiesFile reads a pattern written by ExportToPropertiesFile
+// into the receiver. Line continuations are not supported.
+func (pattern *Pattern) ImportFromPropertiesFile(r io.Reader) error {
+	imported := &Pattern{}
+	tracks := make(map[int]*Track)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimLeft(scanner.Text(), " \t\f")
+		if text == "" || text[0] == '#' || text[0] == '!' {
+			continue
+		}
+
+		key, value, ok := splitProperty(text)
+		if !ok {
+			return fmt.Errorf("%w: line %d: missing separator", ErrInvalidFormat, line)
+		}
+		if err := imported.setField(tracks, "track.", ".", key, value); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	imported.Tracks = sortedTracks(tracks)
+	*pattern = *imported
+	return nil
+}
+
+// setField sets a pattern field from a flat key/value pair. Track fields use
+// keys like <prefix><index><sep>name, like track.0.name. Unknown keys are
+// ignored.
+func (pattern *Pattern) setField(tracks map[int]*Track, prefix, sep, key, value string) error {
+	switch key {
+	case "version":
+		pattern.Version = value
+		return nil
+	case "tempo":
+		tempo, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return fmt.Errorf("%w: invalid tempo %q", ErrInvalidFormat, value)
+		}
+		pattern.Tempo = float32(tempo)
+		return nil
+	}
+
+	if !strings.HasPrefix(key, prefix) {
+		return nil
+	}
+	parts := strings.SplitN(key[len(prefix):], sep, 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	index, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil
+	}
+
+	track, ok := tracks[index]
+	if !ok {
+		track = &Track{}
+		tracks[index] = track
+	}
+	return track.setField(parts[1], value)
+}
+
+// setField sets a track field from a key/value pair, unknown keys are
+// ignored.
+func (track *Track) setField(key, value string) error {
+	switch strings.ToLower(key) {
+	case "id":
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%w: invalid track ID %q", ErrInvalidFormat, value)
+		}
+		track.ID = id
+	case "name":
+		track.Name = value
+	case "steps":
+		return track.setStepString(value)
+	}
+
+	return nil
+}
+
+// sortedTracks returns the tracks ordered by their index
+func sortedTracks(tracks map[int]*Track) []*Track {
+	indices := make([]int, 0, len(tracks))
+	for index := range tracks {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+
+	sorted := make([]*Track, 0, len(tracks))
+	for _, index := range indices {
+		sorted = append(sorted, tracks[index])
+	}
+
+	return sorted
+}
+
+// splitProperty splits a properties line at the first unescaped = or :
+func splitProperty(line string) (string, string, bool) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			key := propertiesUnescape(strings.TrimRight(line[:i], " \t\f"))
+			value := propertiesUnescape(strings.TrimLeft(line[i+1:], " \t\f"))
+			return key, value, true
+		}
+	}
+
+	return "", "", false
+}
+
+func propertiesEscape(s string) string {
+	s = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\r", "\\r", "\t", "\\t").Replace(s)
+	if strings.HasPrefix(s, " ") {
+		s = "\\" + s
+	}
+
+	return s
+}
+
+func propertiesUnescape(s string) string {
+	var unescaped strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			unescaped.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			unescaped.WriteByte('\n')
+		case 'r':
+			unescaped.WriteByte('\r')
+		case 't':
+			unescaped.WriteByte('\t')
+		default:
+			unescaped.WriteByte(s[i])
+		}
+	}
+
+	return unescaped.String()
+}
+
+// ExportTOML writes the pattern as a TOML document with the version and tempo
+// at the top level and an array of tables for the tracks.
+func (pattern *Pattern) ExportTOML(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "version = %s\n", tomlQuote(pattern.Version))
+	fmt.Fprintf(buf, "tempo = %s\n", formatFloat(pattern.Tempo))
+	for _, track := range pattern.Tracks {
+		buf.WriteString("\n[[tracks]]\n")
+		fmt.Fprintf(buf, "id = %d\n", track.ID)
+		fmt.Fprintf(buf, "name = %s\n", tomlQuote(track.Name))
+		fmt.Fprintf(buf, "steps = %s\n", tomlQuote(formatSteps(track.AllSteps())))
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ImportTOML reads a pattern written by ExportTOML into the receiver. Only
+// the subset of TOML produced by ExportTOML is supported: key/value pairs
+// with string and number values, comments and the tracks array of tables.
+func (pattern *Pattern) ImportTOML(r io.Reader) error {
+	imported := &Pattern{}
+	var track *Track
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		if text == "[[tracks]]" {
+			track = &Track{}
+			imported.Tracks = append(imported.Tracks, track)
+			continue
+		}
+
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("%w: line %d: expected key = value", ErrInvalidFormat, line)
+		}
+		key := strings.TrimSpace(parts[0])
+		value, err := tomlValue(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if track == nil {
+			err = imported.setField(nil, "", "", key, value)
+		} else {
+			err = track.setField(key, value)
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	*pattern = *imported
+	return nil
+}
+
+// tomlQuote returns s as a TOML basic string
+func tomlQuote(s string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			quoted.WriteByte('\\')
+			quoted.WriteRune(r)
+		case r < 0x20 || r == 0x7F:
+			fmt.Fprintf(&quoted, "\\u%04X", r)
+		default:
+			quoted.WriteRune(r)
+		}
+	}
+	quoted.WriteByte('"')
+
+	return quoted.String()
+}
+
+// tomlValue returns the string representation of a TOML string or number,
+// stripping a trailing comment.
+func tomlValue(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, "'"):
+		end := strings.Index(s[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("%w: unterminated string", ErrInvalidFormat)
+		}
+		return s[1 : end+1], nil
+	case strings.HasPrefix(s, "\""):
+		var value strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '"':
+				return value.String(), nil
+			case '\\':
+				if i+1 >= len(s) {
+					break
+				}
+				i++
+				switch s[i] {
+				case 'b':
+					value.WriteByte('\b')
+				case 't':
+					value.WriteByte('\t')
+				case 'n':
+					value.WriteByte('\n')
+				case 'f':
+					value.WriteByte('\f')
+				case 'r':
+					value.WriteByte('\r')
+				case 'u', 'U':
+					length := 4
+					if s[i] == 'U' {
+						length = 8
+					}
+					if i+length >= len(s) {
+						return "", fmt.Errorf("%w: invalid escape", ErrInvalidFormat)
+					}
+					code, err := strconv.ParseUint(s[i+1:i+1+length], 16, 32)
+					if err != nil {
+						return "", fmt.Errorf("%w: invalid escape", ErrInvalidFormat)
+					}
+					value.WriteRune(rune(code))
+					i += length
+				default:
+					value.WriteByte(s[i])
+				}
+			default:
+				value.WriteByte(s[i])
+			}
+		}
+		return "", fmt.Errorf("%w: unterminated string", ErrInvalidFormat)
+	}
+
+	if i := strings.Index(s, "#"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return strings.Replace(s, "_", "", -1), nil
+}
@@ -0,0 +1,90 @@
+package drum
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+)
+
+// fixtures decodes all fixture files
+func fixtures(t *testing.T) map[string]*Pattern {
+	patterns := make(map[string]*Pattern)
+	for i := 1; i <= 5; i++ {
+		name := fmt.Sprintf("pattern_%d.splice", i)
+		pattern, err := DecodeFile(path.Join("fixtures", name))
+		if err != nil {
+			t.Fatalf("something went wrong decoding %s - %v", name, err)
+		}
+		patterns[name] = pattern
+	}
+
+	return patterns
+}
+
+func TestPropertiesRoundTrip(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		var buf bytes.Buffer
+		if err := pattern.ExportToPropertiesFile(&buf); err != nil {
+			t.Fatalf("something went wrong exporting %s - %v", name, err)
+		}
+
+		var imported Pattern
+		if err := imported.ImportFromPropertiesFile(&buf); err != nil {
+			t.Fatalf("something went wrong importing %s - %v", name, err)
+		}
+		if imported.String() != pattern.String() {
+			t.Fatalf("%s didn't survive the round trip.\nGot:\n%s\nExpected:\n%s", name, &imported, pattern)
+		}
+	}
+}
+
+func TestExportToPropertiesFile(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 1, Name: "kick", Steps: [16]bool{0: true, 4: true, 8: true, 12: true}},
+	}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportToPropertiesFile(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	expected := "version=0.808-alpha\ntempo=120.0\ntrack.0.id=1\ntrack.0.name=kick\ntrack.0.steps=x---x---x---x---\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected output.\nGot:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestTOMLRoundTrip(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		var buf bytes.Buffer
+		if err := pattern.ExportTOML(&buf); err != nil {
+			t.Fatalf("something went wrong exporting %s - %v", name, err)
+		}
+
+		var imported Pattern
+		if err := imported.ImportTOML(&buf); err != nil {
+			t.Fatalf("something went wrong importing %s - %v", name, err)
+		}
+		if imported.String() != pattern.String() {
+			t.Fatalf("%s didn't survive the round trip.\nGot:\n%s\nExpected:\n%s", name, &imported, pattern)
+		}
+	}
+
+	var imported Pattern
+	err := imported.ImportTOML(strings.NewReader(`# A pattern
+version = 'literal'
+tempo = 1_20 # comment
+
+[[tracks]]
+id = 7
+name = "quote \" and é"
+steps = "x---|x---|x---|x---"
+`))
+	if err != nil {
+		t.Fatalf("something went wrong importing - %v", err)
+	}
+	if imported.Version != "literal" || imported.Tempo != 120 || imported.Tracks[0].Name != "quote \" and é" {
+		t.Fatalf("unexpected pattern:\n%s", &imported)
+	}
+}
@@ -0,0 +1,1026 @@
+package drum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The protobuf exports use the following schema:
+//
+//	message Pattern {
+//	  string version = 1;
+//	  float tempo = 2;
+//	  repeated Track tracks = 3;
+//	}
+//
+//	message Track {
+//	  int32 id = 1;
+//	  string name = 2;
+//	  repeated bool steps = 3;
+//	}
+
+// ExportProtobufText writes the pattern in the Protocol Buffers text format,
+// pretty-printed with a field per line.
+func (pattern *Pattern) ExportProtobufText(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "version: %s\n", protoQuote(pattern.Version))
+	fmt.Fprintf(buf, "tempo: %g\n", pattern.Tempo)
+	for _, track := range pattern.Tracks {
+		buf.WriteString("tracks: {\n")
+		fmt.Fprintf(buf, "  id: %d\n", track.ID)
+		fmt.Fprintf(buf, "  name: %s\n", protoQuote(track.Name))
+		fmt.Fprintf(buf, "  steps: [%s]\n", joinSteps(track, "true", "false", ", "))
+		buf.WriteString("}\n")
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ImportProtobufText reads a pattern in the Protocol Buffers text format into
+// the receiver. Repeated steps may be written as a list or as a field per
+// step.
+func (pattern *Pattern) ImportProtobufText(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	imported := &Pattern{}
+	scanner := &protoScanner{s: string(data)}
+	err = scanner.fields("", func(name string) error {
+		switch name {
+		case "version":
+			return scanner.stringValue(&imported.Version)
+		case "tempo":
+			value, err := scanner.scalar()
+			if err != nil {
+				return err
+			}
+			tempo, err := strconv.ParseFloat(strings.TrimSuffix(value, "f"), 32)
+			if err != nil {
+				return fmt.Errorf("%w: invalid tempo %q", ErrInvalidFormat, value)
+			}
+			imported.Tempo = float32(tempo)
+		case "tracks":
+			track := &Track{}
+			scanner.steps = 0
+			if err := scanner.message(func(name string) error { return scanner.trackField(track, name) }); err != nil {
+				return err
+			}
+			imported.Tracks = append(imported.Tracks, track)
+		default:
+			return fmt.Errorf("%w: unknown field %q", ErrInvalidFormat, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*pattern = *imported
+	return nil
+}
+
+// protoQuote returns s as a protobuf text format string, escaping non
+// printable bytes as octal.
+func protoQuote(s string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			quoted.WriteByte('\\')
+			quoted.WriteByte(c)
+		case c == '\n':
+			quoted.WriteString("\\n")
+		case c < 0x20 || c == 0x7F:
+			fmt.Fprintf(&quoted, "\\%03o", c)
+		default:
+			quoted.WriteByte(c)
+		}
+	}
+	quoted.WriteByte('"')
+
+	return quoted.String()
+}
+
+// protoScanner reads tokens from the protobuf text format
+type protoScanner struct {
+	s   string
+	pos int
+	// steps counts the steps set by repeated step fields of the current track
+	steps int
+}
+
+// skip skips whitespace and comments
+func (scanner *protoScanner) skip() {
+	for scanner.pos < len(scanner.s) {
+		switch scanner.s[scanner.pos] {
+		case ' ', '\t', '\n', '\r':
+			scanner.pos++
+		case '#':
+			for scanner.pos < len(scanner.s) && scanner.s[scanner.pos] != '\n' {
+				scanner.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next token without consuming it, an empty string is
+// returned at the end of the input.
+func (scanner *protoScanner) peek() string {
+	scanner.skip()
+	if scanner.pos >= len(scanner.s) {
+		return ""
+	}
+
+	s := scanner.s[scanner.pos:]
+	switch s[0] {
+	case '{', '}', '[', ']', ':', ',', ';', '<', '>':
+		return s[:1]
+	case '"':
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				return s[:i+1]
+			}
+		}
+		return s
+	}
+
+	end := strings.IndexAny(s, " \t\r\n{}[]:,;<>#\"")
+	if end < 0 {
+		end = len(s)
+	}
+	return s[:end]
+}
+
+// next consumes and returns the next token
+func (scanner *protoScanner) next() string {
+	token := scanner.peek()
+	scanner.pos += len(token)
+	return token
+}
+
+// fields calls fn for every field name until the end token is reached
+func (scanner *protoScanner) fields(end string, fn func(name string) error) error {
+	for {
+		token := scanner.next()
+		switch token {
+		case end:
+			return nil
+		case "", "{", "}", "[", "]", ":", ",", ";", "<", ">":
+			return fmt.Errorf("%w: unexpected %q", ErrInvalidFormat, token)
+		}
+
+		if err := fn(token); err != nil {
+			return err
+		}
+		if separator := scanner.peek(); separator == "," || separator == ";" {
+			scanner.next()
+		}
+	}
+}
+
+// message reads a nested message, calling fn for every field
+func (scanner *protoScanner) message(fn func(name string) error) error {
+	if scanner.peek() == ":" {
+		scanner.next()
+	}
+
+	switch scanner.next() {
+	case "{":
+		return scanner.fields("}", fn)
+	case "<":
+		return scanner.fields(">", fn)
+	}
+	return fmt.Errorf("%w: expected message", ErrInvalidFormat)
+}
+
+// scalar reads the value of a scalar field
+func (scanner *protoScanner) scalar() (string, error) {
+	if scanner.next() != ":" {
+		return "", fmt.Errorf("%w: expected ':'", ErrInvalidFormat)
+	}
+	value := scanner.next()
+	if value == "" {
+		return "", fmt.Errorf("%w: missing value", ErrInvalidFormat)
+	}
+
+	return value, nil
+}
+
+// stringValue reads the value of a string field into dest
+func (scanner *protoScanner) stringValue(dest *string) error {
+	value, err := scanner.scalar()
+	if err != nil {
+		return err
+	}
+	s, err := strconv.Unquote(value)
+	if err != nil {
+		return fmt.Errorf("%w: invalid string %s", ErrInvalidFormat, value)
+	}
+
+	*dest = s
+	return nil
+}
+
+// trackField reads the named field of a track message
+func (scanner *protoScanner) trackField(track *Track, name string) error {
+	switch name {
+	case "id":
+		value, err := scanner.scalar()
+		if err != nil {
+			return err
+		}
+		id, err := strconv.ParseInt(value, 0, 32)
+		if err != nil {
+			return fmt.Errorf("%w: invalid track ID %q", ErrInvalidFormat, value)
+		}
+		track.ID = int(id)
+	case "name":
+		return scanner.stringValue(&track.Name)
+	case "steps":
+		if scanner.peek() != ":" {
+			return fmt.Errorf("%w: expected ':'", ErrInvalidFormat)
+		}
+		scanner.next()
+
+		var values []string
+		if scanner.peek() == "[" {
+			scanner.next()
+			for token := scanner.next(); token != "]"; token = scanner.next() {
+				switch token {
+				case "":
+					return fmt.Errorf("%w: unterminated list", ErrInvalidFormat)
+				case ",":
+					continue
+				}
+				values = append(values, token)
+			}
+		} else {
+			values = append(values, scanner.next())
+		}
+
+		for _, value := range values {
+			step, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%w: invalid step %q", ErrInvalidFormat, value)
+			}
+			// Long patterns have 32 or 64 steps, grow the track when a step
+			// past its end is read
+			if scanner.steps == track.Length() && track.SetLength(2*track.Length()) != nil {
+				return ErrStepOutOfRange
+			}
+			track.SetStep(scanner.steps, step)
+			scanner.steps++
+		}
+	default:
+		return fmt.Errorf("%w: unknown field %q", ErrInvalidFormat, name)
+	}
+
+	return nil
+}
+
+// ExportMessagePack writes the pattern as a MessagePack map with the keys
+// version, tempo and tracks. Every track is a map with the keys id, name and
+// steps.
+func (pattern *Pattern) ExportMessagePack(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	msgpackMap(buf, 3)
+	msgpackString(buf, "version")
+	msgpackString(buf, pattern.Version)
+	msgpackString(buf, "tempo")
+	buf.WriteByte(0xca)
+	binary.Write(buf, binary.BigEndian, math.Float32bits(pattern.Tempo))
+	msgpackString(buf, "tracks")
+	msgpackArray(buf, len(pattern.Tracks))
+	for _, track := range pattern.Tracks {
+		msgpackMap(buf, 3)
+		msgpackString(buf, "id")
+		msgpackInt(buf, int64(track.ID))
+		msgpackString(buf, "name")
+		msgpackString(buf, track.Name)
+		msgpackString(buf, "steps")
+		msgpackArray(buf, track.Length())
+		for _, step := range track.AllSteps() {
+			if step {
+				buf.WriteByte(0xc3)
+			} else {
+				buf.WriteByte(0xc2)
+			}
+		}
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ImportMessagePack reads a pattern written by ExportMessagePack into the
+// receiver. Unknown keys are ignored.
+func (pattern *Pattern) ImportMessagePack(r io.Reader) error {
+	value, err := msgpackDecode(bufio.NewReader(r), 0)
+	if err != nil {
+		return err
+	}
+
+	imported, err := patternFromValue(value)
+	if err != nil {
+		return err
+	}
+	*pattern = *imported
+	return nil
+}
+
+// msgpackMap writes a map header for n key/value pairs
+func msgpackMap(buf *bytes.Buffer, n int) {
+	switch {
+	case n < 16:
+		buf.WriteByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xde)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdf)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+// msgpackArray writes an array header for n values
+func msgpackArray(buf *bytes.Buffer, n int) {
+	switch {
+	case n < 16:
+		buf.WriteByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xdc)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdd)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+// msgpackString writes s using the smallest str format
+func msgpackString(buf *bytes.Buffer, s string) {
+	switch n := len(s); {
+	case n < 32:
+		buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(0xd9)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xda)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdb)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+	buf.WriteString(s)
+}
+
+// msgpackInt writes v using the smallest int format
+func msgpackInt(buf *bytes.Buffer, v int64) {
+	switch {
+	case v >= 0 && v <= math.MaxInt8:
+		buf.WriteByte(byte(v))
+	case v < 0 && v >= -32:
+		buf.WriteByte(byte(int8(v)))
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(v))
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(v))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, v)
+	}
+}
+
+// msgpackDecode reads a single MessagePack value. Maps are returned as
+// map[string]interface{}, arrays as []interface{}, integers as int64 and
+// floats as float64.
+func msgpackDecode(r *bufio.Reader, depth int) (interface{}, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return decodeMap(r, uint64(b&0x0f), depth, msgpackDecode)
+	case b&0xf0 == 0x90:
+		return decodeArray(r, uint64(b&0x0f), depth, msgpackDecode)
+	case b&0xe0 == 0xa0:
+		return decodeString(r, uint64(b&0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xca:
+		var v uint32
+		err := binary.Read(r, binary.BigEndian, &v)
+		return float64(math.Float32frombits(v)), err
+	case 0xcb:
+		var v uint64
+		err := binary.Read(r, binary.BigEndian, &v)
+		return math.Float64frombits(v), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := msgpackUint(r, 1<<(b-0xcc))
+		return int64(v), err
+	case 0xd0:
+		var v int8
+		err := binary.Read(r, binary.BigEndian, &v)
+		return int64(v), err
+	case 0xd1:
+		var v int16
+		err := binary.Read(r, binary.BigEndian, &v)
+		return int64(v), err
+	case 0xd2:
+		var v int32
+		err := binary.Read(r, binary.BigEndian, &v)
+		return int64(v), err
+	case 0xd3:
+		var v int64
+		err := binary.Read(r, binary.BigEndian, &v)
+		return v, err
+	case 0xd9, 0xda, 0xdb:
+		n, err := msgpackUint(r, 1<<(b-0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return decodeString(r, n)
+	case 0xdc, 0xdd:
+		n, err := msgpackUint(r, 2<<(b-0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return decodeArray(r, n, depth, msgpackDecode)
+	case 0xde, 0xdf:
+		n, err := msgpackUint(r, 2<<(b-0xde))
+		if err != nil {
+			return nil, err
+		}
+		return decodeMap(r, n, depth, msgpackDecode)
+	}
+
+	return nil, fmt.Errorf("%w: unsupported MessagePack type 0x%02x", ErrInvalidFormat, b)
+}
+
+// msgpackUint reads a big endian unsigned integer of size bytes
+func msgpackUint(r io.Reader, size int) (uint64, error) {
+	data := make([]byte, 8)
+	if _, err := io.ReadFull(r, data[8-size:]); err != nil {
+		return 0, err
+	}
+
+	return binary.BigEndian.Uint64(data), nil
+}
+
+// The lengths of strings, arrays and maps are read from the input, so the
+// decoders below don't allocate for them up front. Memory only grows with
+// the data which is actually read.
+
+// decodeString reads a string of n bytes
+func decodeString(r io.Reader, n uint64) (interface{}, error) {
+	if n > math.MaxInt64 {
+		return nil, fmt.Errorf("%w: string of %d bytes", ErrInvalidFormat, n)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := io.CopyN(buf, r, int64(n)); err != nil {
+		return nil, lengthError(err, "string", n)
+	}
+
+	return buf.String(), nil
+}
+
+// decodeArray reads an array of n values with decode
+func decodeArray(r *bufio.Reader, n uint64, depth int, decode func(*bufio.Reader, int) (interface{}, error)) (interface{}, error) {
+	if err := checkDepth(depth + 1); err != nil {
+		return nil, err
+	}
+
+	var values []interface{}
+	for i := uint64(0); i < n; i++ {
+		value, err := decode(r, depth+1)
+		if err != nil {
+			return nil, lengthError(err, "array", n)
+		}
+		values = append(values, value)
+	}
+	if values == nil {
+		values = []interface{}{}
+	}
+
+	return values, nil
+}
+
+// decodeMap reads a map of n key/value pairs with string keys with decode
+func decodeMap(r *bufio.Reader, n uint64, depth int, decode func(*bufio.Reader, int) (interface{}, error)) (interface{}, error) {
+	if err := checkDepth(depth + 1); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	for i := uint64(0); i < n; i++ {
+		key, err := decode(r, depth+1)
+		if err != nil {
+			return nil, lengthError(err, "map", n)
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: map key is not a string", ErrInvalidFormat)
+		}
+		if values[name], err = decode(r, depth+1); err != nil {
+			return nil, lengthError(err, "map", n)
+		}
+	}
+
+	return values, nil
+}
+
+// maxDecodeDepth is the deepest nesting of arrays, maps and tags accepted by
+// the MessagePack and CBOR decoders. Patterns are nested 4 levels deep, the
+// limit keeps hostile input from overflowing the stack.
+const maxDecodeDepth = 32
+
+// checkDepth returns an ErrInvalidFormat error if depth is over maxDecodeDepth
+func checkDepth(depth int) error {
+	if depth > maxDecodeDepth {
+		return fmt.Errorf("%w: nested deeper than %d levels", ErrInvalidFormat, maxDecodeDepth)
+	}
+
+	return nil
+}
+
+// lengthError returns an ErrInvalidFormat error for a string, array or map of
+// length n which is longer than the input, other errors are returned as is.
+func lengthError(err error, kind string, n uint64) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: %s of length %d is longer than the input", ErrInvalidFormat, kind, n)
+	}
+
+	return err
+}
+
+// patternFromValue converts a decoded map with the version, tempo and tracks
+// keys to a pattern. Numbers may be int64, uint64 or float64.
+func patternFromValue(value interface{}) (*Pattern, error) {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: pattern is not a map", ErrInvalidFormat)
+	}
+
+	pattern := &Pattern{}
+	if version, ok := fields["version"]; ok {
+		if pattern.Version, ok = version.(string); !ok {
+			return nil, fmt.Errorf("%w: version is not a string", ErrInvalidFormat)
+		}
+	}
+	if tempo, ok := fields["tempo"]; ok {
+		v, ok := numberValue(tempo)
+		if !ok {
+			return nil, fmt.Errorf("%w: tempo is not a number", ErrInvalidFormat)
+		}
+		pattern.Tempo = float32(v)
+	}
+
+	tracks, _ := fields["tracks"].([]interface{})
+	for _, value := range tracks {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: track is not a map", ErrInvalidFormat)
+		}
+
+		track := &Track{}
+		if id, ok := fields["id"]; ok {
+			v, ok := numberValue(id)
+			if !ok {
+				return nil, fmt.Errorf("%w: track ID is not a number", ErrInvalidFormat)
+			}
+			track.ID = int(v)
+		}
+		if name, ok := fields["name"]; ok {
+			if track.Name, ok = name.(string); !ok {
+				return nil, fmt.Errorf("%w: track name is not a string", ErrInvalidFormat)
+			}
+		}
+		steps, _ := fields["steps"].([]interface{})
+		if len(steps) > StepCount && track.SetLength(len(steps)) != nil {
+			return nil, ErrStepOutOfRange
+		}
+		for i, step := range steps {
+			on, ok := step.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%w: step is not a bool", ErrInvalidFormat)
+			}
+			track.SetStep(i, on)
+		}
+		pattern.Tracks = append(pattern.Tracks, track)
+	}
+
+	return pattern, nil
+}
+
+// numberValue returns a decoded number as float64
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+
+	return 0, false
+}
+
+// ExportCBOR writes the pattern as a CBOR (RFC 7049) map with the same keys
+// as ExportMessagePack.
+func (pattern *Pattern) ExportCBOR(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	cborHeader(buf, 5, 3)
+	cborString(buf, "version")
+	cborString(buf, pattern.Version)
+	cborString(buf, "tempo")
+	buf.WriteByte(0xfa)
+	binary.Write(buf, binary.BigEndian, math.Float32bits(pattern.Tempo))
+	cborString(buf, "tracks")
+	cborHeader(buf, 4, uint64(len(pattern.Tracks)))
+	for _, track := range pattern.Tracks {
+		cborHeader(buf, 5, 3)
+		cborString(buf, "id")
+		if track.ID < 0 {
+			cborHeader(buf, 1, uint64(-1-track.ID))
+		} else {
+			cborHeader(buf, 0, uint64(track.ID))
+		}
+		cborString(buf, "name")
+		cborString(buf, track.Name)
+		cborString(buf, "steps")
+		cborHeader(buf, 4, uint64(track.Length()))
+		for _, step := range track.AllSteps() {
+			if step {
+				buf.WriteByte(0xf5)
+			} else {
+				buf.WriteByte(0xf4)
+			}
+		}
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ImportCBOR reads a pattern written by ExportCBOR into the receiver. Unknown
+// keys are ignored, indefinite length items are not supported.
+func (pattern *Pattern) ImportCBOR(r io.Reader) error {
+	value, err := cborDecode(bufio.NewReader(r), 0)
+	if err != nil {
+		return err
+	}
+
+	imported, err := patternFromValue(value)
+	if err != nil {
+		return err
+	}
+	*pattern = *imported
+	return nil
+}
+
+// cborHeader writes the initial byte of a data item of the major type with
+// its argument.
+func cborHeader(buf *bytes.Buffer, major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		buf.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(major | 24)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	case n <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(major | 27)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+// cborString writes s as a text string
+func cborString(buf *bytes.Buffer, s string) {
+	cborHeader(buf, 3, uint64(len(s)))
+	buf.WriteString(s)
+}
+
+// cborDecode reads a single CBOR data item, returning the same types as
+// msgpackDecode. Tags are skipped.
+func cborDecode(r *bufio.Reader, depth int) (interface{}, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	major, info := b>>5, b&0x1f
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		case 25:
+			v, err := msgpackUint(r, 2)
+			return halfFloat(uint16(v)), err
+		case 26:
+			v, err := msgpackUint(r, 4)
+			return float64(math.Float32frombits(uint32(v))), err
+		case 27:
+			v, err := msgpackUint(r, 8)
+			return math.Float64frombits(v), err
+		}
+		return nil, fmt.Errorf("%w: unsupported CBOR simple value %d", ErrInvalidFormat, info)
+	}
+
+	var n uint64
+	switch {
+	case info < 24:
+		n = uint64(info)
+	case info <= 27:
+		if n, err = msgpackUint(r, 1<<(info-24)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported CBOR length 0x%02x", ErrInvalidFormat, b)
+	}
+
+	switch major {
+	case 0:
+		return n, nil
+	case 1:
+		return -1 - int64(n), nil
+	case 2, 3:
+		return decodeString(r, n)
+	case 4:
+		return decodeArray(r, n, depth, cborDecode)
+	case 5:
+		return decodeMap(r, n, depth, cborDecode)
+	}
+
+	// Tagged item
+	if err := checkDepth(depth + 1); err != nil {
+		return nil, err
+	}
+	return cborDecode(r, depth+1)
+}
+
+// halfFloat converts an IEEE 754 half precision float to float64
+func halfFloat(v uint16) float64 {
+	exponent := int(v >> 10 & 0x1f)
+	mantissa := float64(v & 0x3ff)
+
+	var f float64
+	switch exponent {
+	case 0:
+		f = math.Ldexp(mantissa, -24)
+	case 31:
+		if mantissa == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mantissa+1024, exponent-25)
+	}
+	if v&0x8000 != 0 {
+		f = -f
+	}
+
+	return f
+}
+
+// stochasticType marks the JSON written by ExportStochastic
+const stochasticType = "stochastic"
+
+type stochasticPattern struct {
+	Type    string            `json:"type"`
+	Version string            `json:"version"`
+	Tempo   float32           `json:"tempo"`
+	Tracks  []stochasticTrack `json:"tracks"`
+}
+
+type stochasticTrack struct {
+	ID            int                `json:"id"`
+	Name          string             `json:"name"`
+	Probabilities [StepCount]float32 `json:"probabilities"`
+}
+
+// ExportStochastic writes the pattern as JSON with the step probabilities of
+// every track instead of its steps. The document is marked with
+// "type": "stochastic", a concrete pattern can be generated with
+// Track.Materialize.
+func (pattern *Pattern) ExportStochastic(w io.Writer) error {
+	data := stochasticPattern{
+		Type:    stochasticType,
+		Version: pattern.Version,
+		Tempo:   pattern.Tempo,
+		Tracks:  []stochasticTrack{},
+	}
+	for _, track := range pattern.Tracks {
+		data.Tracks = append(data.Tracks, stochasticTrack{track.ID, track.Name, track.ProbabilitySteps})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// ImportStochastic reads a pattern written by ExportStochastic into the
+// receiver, setting the step probabilities of the tracks.
+func (pattern *Pattern) ImportStochastic(r io.Reader) error {
+	var data stochasticPattern
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return err
+	}
+	if data.Type != stochasticType {
+		return fmt.Errorf("%w: expected type %q, got %q", ErrInvalidFormat, stochasticType, data.Type)
+	}
+
+	imported := &Pattern{Version: data.Version, Tempo: data.Tempo}
+	for _, track := range data.Tracks {
+		imported.Tracks = append(imported.Tracks, &Track{ID: track.ID, Name: track.Name, ProbabilitySteps: track.Probabilities})
+	}
+
+	*pattern = *imported
+	return nil
+}
+
+// tabSeparatedHeader returns the header row of ExportTabSeparated, with a
+// column for each of the steps
+func tabSeparatedHeader(steps int) []string {
+	header := []string{"ID", "Name"}
+	for i := 1; i <= steps; i++ {
+		header = append(header, fmt.Sprintf("S%02d", i))
+	}
+
+	return header
+}
+
+// ExportTabSeparated writes the tracks of the pattern as tab separated
+// values with a header row, using 1 and 0 for active and inactive steps. Long
+// patterns have a column for each of their steps:
+//
+//	ID	Name	S01	S02	...	S16
+//	1	kick	1	0	...	0
+func (pattern *Pattern) ExportTabSeparated(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = '\t'
+
+	writer.Write(tabSeparatedHeader(pattern.Length()))
+	for _, track := range pattern.Tracks {
+		record := []string{strconv.Itoa(track.ID), track.Name}
+		record = append(record, strings.Split(joinSteps(track, "1", "0", ","), ",")...)
+		writer.Write(record)
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ImportTabSeparated reads tracks written by ExportTabSeparated, replacing
+// the tracks of the receiver. The version and tempo are not part of the
+// format and are left untouched.
+func (pattern *Pattern) ImportTabSeparated(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.Comma = '\t'
+
+	// All records have as many fields as the header
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+	if len(records) == 0 || records[0][0] != "ID" {
+		return fmt.Errorf("%w: missing header", ErrInvalidFormat)
+	}
+	steps := len(records[0]) - 2
+	if !supportedStepCount(steps) {
+		return fmt.Errorf("%w: %d step columns", ErrInvalidFormat, steps)
+	}
+
+	var tracks []*Track
+	for _, record := range records[1:] {
+		id, err := strconv.Atoi(record[0])
+		if err != nil {
+			return fmt.Errorf("%w: invalid track ID %q", ErrInvalidFormat, record[0])
+		}
+
+		track := &Track{ID: id, Name: record[1]}
+		track.SetLength(steps)
+		for i, value := range record[2:] {
+			switch value {
+			case "1":
+				track.SetStep(i, true)
+			case "0":
+			default:
+				return fmt.Errorf("%w: invalid step %q", ErrInvalidFormat, value)
+			}
+		}
+		tracks = append(tracks, track)
+	}
+
+	pattern.Tracks = tracks
+	return nil
+}
+
+// ndjsonRecord is a line written by ExportNewlineDelimitedJSON, either the
+// pattern header or a track.
+type ndjsonRecord struct {
+	Type    string  `json:"type"`
+	Version string  `json:"version,omitempty"`
+	Tempo   float32 `json:"tempo,omitempty"`
+	ID      int     `json:"id,omitempty"`
+	Name    string  `json:"name,omitempty"`
+	Steps   []bool  `json:"steps,omitempty"`
+}
+
+// ExportNewlineDelimitedJSON writes the pattern as newline-delimited JSON: a
+// line with "type": "pattern" holding the version and tempo, followed by a
+// line with "type": "track" for every track.
+func (pattern *Pattern) ExportNewlineDelimitedJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(ndjsonRecord{Type: "pattern", Version: pattern.Version, Tempo: pattern.Tempo}); err != nil {
+		return err
+	}
+	for _, track := range pattern.Tracks {
+		if err := encoder.Encode(ndjsonRecord{Type: "track", ID: track.ID, Name: track.Name, Steps: track.AllSteps()}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ImportNewlineDelimitedJSON reads a pattern written by
+// ExportNewlineDelimitedJSON into the receiver. Lines with an unknown type
+// are ignored.
+func (pattern *Pattern) ImportNewlineDelimitedJSON(r io.Reader) error {
+	imported := &Pattern{}
+	decoder := json.NewDecoder(r)
+	for {
+		var record ndjsonRecord
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch record.Type {
+		case "pattern":
+			imported.Version = record.Version
+			imported.Tempo = record.Tempo
+		case "track":
+			track := &Track{ID: record.ID, Name: record.Name}
+			copy(track.Steps[:], record.Steps)
+			if len(record.Steps) > StepCount {
+				if err := track.SetLength(len(record.Steps)); err != nil {
+					return err
+				}
+				track.setAllSteps(record.Steps)
+			}
+			imported.Tracks = append(imported.Tracks, track)
+		}
+	}
+
+	*pattern = *imported
+	return nil
+}
@@ -0,0 +1,269 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestProtobufTextRoundTrip(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		var buf bytes.Buffer
+		if err := pattern.ExportProtobufText(&buf); err != nil {
+			t.Fatalf("something went wrong exporting %s - %v", name, err)
+		}
+		text := buf.String()
+		if !strings.HasPrefix(text, "version: ") || !strings.Contains(text, "\ntempo: ") {
+			t.Fatalf("%s is missing the version or tempo line:\n%s", name, text)
+		}
+
+		var imported Pattern
+		if err := imported.ImportProtobufText(&buf); err != nil {
+			t.Fatalf("something went wrong importing %s - %v", name, err)
+		}
+		if imported.String() != pattern.String() {
+			t.Fatalf("%s didn't survive the round trip.\nGot:\n%s\nExpected:\n%s", name, &imported, pattern)
+		}
+	}
+
+	var imported Pattern
+	err := imported.ImportProtobufText(strings.NewReader(`# prototext output
+version: "0.808\001"
+tempo: 98.4
+tracks <
+  id: 2
+  name: "snare"
+  steps: false steps: true
+>`))
+	if err != nil {
+		t.Fatalf("something went wrong importing - %v", err)
+	}
+	if imported.Version != "0.808\x01" || imported.Tempo != 98.4 || !imported.Tracks[0].Steps[1] {
+		t.Fatalf("unexpected pattern:\n%s", &imported)
+	}
+}
+
+// jsonPattern marshals the pattern as JSON with the same keys as the
+// MessagePack export
+func jsonPattern(pattern *Pattern) ([]byte, error) {
+	tracks := []interface{}{}
+	for _, track := range pattern.Tracks {
+		tracks = append(tracks, map[string]interface{}{"id": track.ID, "name": track.Name, "steps": track.Steps})
+	}
+
+	return json.Marshal(map[string]interface{}{"version": pattern.Version, "tempo": pattern.Tempo, "tracks": tracks})
+}
+
+func TestMessagePackRoundTrip(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		var buf bytes.Buffer
+		if err := pattern.ExportMessagePack(&buf); err != nil {
+			t.Fatalf("something went wrong exporting %s - %v", name, err)
+		}
+		encoded, err := jsonPattern(pattern)
+		if err != nil {
+			t.Fatalf("something went wrong marshalling %s - %v", name, err)
+		}
+		if buf.Len() >= len(encoded) {
+			t.Fatalf("%s: MessagePack is %d bytes, JSON is %d bytes", name, buf.Len(), len(encoded))
+		}
+
+		var imported Pattern
+		if err := imported.ImportMessagePack(&buf); err != nil {
+			t.Fatalf("something went wrong importing %s - %v", name, err)
+		}
+		if imported.String() != pattern.String() {
+			t.Fatalf("%s didn't survive the round trip.\nGot:\n%s\nExpected:\n%s", name, &imported, pattern)
+		}
+	}
+}
+
+func TestImportMessagePackHostileLengths(t *testing.T) {
+	inputs := [][]byte{
+		// str 32, array 32 and map 32 claiming 2^31 entries
+		{0xdb, 0x7f, 0xff, 0xff, 0xff},
+		{0xdd, 0x7f, 0xff, 0xff, 0xff},
+		{0xdf, 0x7f, 0xff, 0xff, 0xff},
+		{0xdb, 0xff, 0xff, 0xff, 0xff, 'a', 'b'},
+		// A map with a truncated key
+		{0x81, 0xa5, 'v', 'e'},
+		// A million nested fixarrays
+		bytes.Repeat([]byte{0x91}, 1<<20),
+	}
+	for _, data := range inputs {
+		var imported Pattern
+		if err := imported.ImportMessagePack(bytes.NewReader(data)); !errors.Is(err, ErrInvalidFormat) {
+			t.Fatalf("% x: expected ErrInvalidFormat, got %v", data, err)
+		}
+	}
+}
+
+func BenchmarkExportMessagePack(b *testing.B) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		b.Fatalf("something went wrong decoding - %v", err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		pattern.ExportMessagePack(io.Discard)
+	}
+}
+
+func BenchmarkExportJSON(b *testing.B) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		b.Fatalf("something went wrong decoding - %v", err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		jsonPattern(pattern)
+	}
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		var buf bytes.Buffer
+		if err := pattern.ExportCBOR(&buf); err != nil {
+			t.Fatalf("something went wrong exporting %s - %v", name, err)
+		}
+
+		var imported Pattern
+		if err := imported.ImportCBOR(&buf); err != nil {
+			t.Fatalf("something went wrong importing %s - %v", name, err)
+		}
+		if imported.String() != pattern.String() {
+			t.Fatalf("%s didn't survive the round trip.\nGot:\n%s\nExpected:\n%s", name, &imported, pattern)
+		}
+	}
+
+	// {"version": "a", "tempo": 120.0 as half float, "tracks": [{"id": -1}]}
+	data := []byte{0xa3, 0x67, 'v', 'e', 'r', 's', 'i', 'o', 'n', 0x61, 'a',
+		0x65, 't', 'e', 'm', 'p', 'o', 0xf9, 0x57, 0x80,
+		0x66, 't', 'r', 'a', 'c', 'k', 's', 0x81, 0xa1, 0x62, 'i', 'd', 0x20}
+	var imported Pattern
+	if err := imported.ImportCBOR(bytes.NewReader(data)); err != nil {
+		t.Fatalf("something went wrong importing - %v", err)
+	}
+	if imported.Version != "a" || imported.Tempo != 120 || imported.Tracks[0].ID != -1 {
+		t.Fatalf("unexpected pattern:\n%s", &imported)
+	}
+}
+
+func TestImportCBORHostileLengths(t *testing.T) {
+	inputs := [][]byte{
+		// Arrays, text strings and maps claiming 2^64-1 entries
+		{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		{0x7b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		{0xbb, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		{0x7a, 0x7f, 0xff, 0xff, 0xff, 'a'},
+		{0x9a, 0x7f, 0xff, 0xff, 0xff, 0xf5},
+		// A million nested arrays, maps and tags
+		bytes.Repeat([]byte{0x81}, 1<<20),
+		bytes.Repeat([]byte{0xa1, 0x61, 'k'}, 1<<20),
+		bytes.Repeat([]byte{0xc0}, 1<<20),
+	}
+	for _, data := range inputs {
+		var imported Pattern
+		if err := imported.ImportCBOR(bytes.NewReader(data)); !errors.Is(err, ErrInvalidFormat) {
+			t.Fatalf("% x: expected ErrInvalidFormat, got %v", data, err)
+		}
+	}
+}
+
+func BenchmarkCBORSize(b *testing.B) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		b.Fatalf("something went wrong decoding - %v", err)
+	}
+
+	var buf bytes.Buffer
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		pattern.ExportCBOR(&buf)
+	}
+	b.ReportMetric(float64(buf.Len()), "cbor-bytes")
+	b.ReportMetric(float64(len(pattern.Bytes())), "splice-bytes")
+}
+
+func TestStochasticRoundTrip(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 1, Name: "kick", ProbabilitySteps: [16]float32{0: 1, 8: 1, 14: 0.25}},
+		&Track{ID: 2, Name: "hh", ProbabilitySteps: [16]float32{0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5}},
+	}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportStochastic(&buf); err != nil {
+		t.Fatalf("something went wrong exporting - %v", err)
+	}
+	if !strings.Contains(buf.String(), `"type": "stochastic"`) {
+		t.Fatalf("expected the stochastic type marker in:\n%s", buf.String())
+	}
+
+	var imported Pattern
+	if err := imported.ImportStochastic(&buf); err != nil {
+		t.Fatalf("something went wrong importing - %v", err)
+	}
+	if imported.Version != pattern.Version || imported.Tempo != pattern.Tempo || len(imported.Tracks) != len(pattern.Tracks) {
+		t.Fatalf("unexpected pattern:\n%s", &imported)
+	}
+	for i, track := range pattern.Tracks {
+		if got := imported.Tracks[i]; got.ID != track.ID || got.Name != track.Name || got.ProbabilitySteps != track.ProbabilitySteps {
+			t.Fatalf("expected track %v, got %v", track, got)
+		}
+	}
+
+	if err := imported.ImportStochastic(strings.NewReader(`{"type": "concrete"}`)); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("expected ErrInvalidFormat, got %v", err)
+	}
+}
+
+func TestTabSeparatedRoundTrip(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		var buf bytes.Buffer
+		if err := pattern.ExportTabSeparated(&buf); err != nil {
+			t.Fatalf("something went wrong exporting %s - %v", name, err)
+		}
+		if !strings.HasPrefix(buf.String(), "ID\tName\tS01\tS02\t") {
+			t.Fatalf("%s: unexpected header in:\n%s", name, buf.String())
+		}
+
+		imported := Pattern{Version: pattern.Version, Tempo: pattern.Tempo}
+		if err := imported.ImportTabSeparated(&buf); err != nil {
+			t.Fatalf("something went wrong importing %s - %v", name, err)
+		}
+		if imported.String() != pattern.String() {
+			t.Fatalf("%s didn't survive the round trip.\nGot:\n%s\nExpected:\n%s", name, &imported, pattern)
+		}
+	}
+}
+
+func TestNewlineDelimitedJSONRoundTrip(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		var buf bytes.Buffer
+		if err := pattern.ExportNewlineDelimitedJSON(&buf); err != nil {
+			t.Fatalf("something went wrong exporting %s - %v", name, err)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if len(lines) != len(pattern.Tracks)+1 {
+			t.Fatalf("%s: expected %d lines, got %d", name, len(pattern.Tracks)+1, len(lines))
+		}
+		for i, line := range lines {
+			if !json.Valid([]byte(line)) {
+				t.Fatalf("%s: line %d isn't valid JSON: %s", name, i+1, line)
+			}
+		}
+
+		var imported Pattern
+		if err := imported.ImportNewlineDelimitedJSON(&buf); err != nil {
+			t.Fatalf("something went wrong importing %s - %v", name, err)
+		}
+		if imported.String() != pattern.String() {
+			t.Fatalf("%s didn't survive the round trip.\nGot:\n%s\nExpected:\n%s", name, &imported, pattern)
+		}
+	}
+}
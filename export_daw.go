@@ -0,0 +1,110 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// flStudioPPQ is the resolution used for FL Studio beat positions
+const flStudioPPQ = 24
+
+// ExportFLStudio writes the pattern as FL Studio channel rack XML. Every track
+// becomes a Channel and every active step a Beat starting at its position in
+// ticks (24 PPQ).
+func (pattern *Pattern) ExportFLStudio(w io.Writer) error {
+	ticksPerStep := flStudioPPQ / StepsPerBeat
+
+	buf := new(bytes.Buffer)
+	buf.WriteString(xml.Header)
+	fmt.Fprintf(buf, "<Pattern name=\"%s\" tempo=\"%g\" ppq=\"%d\" length=\"%d\">\n",
+		xmlEscape(pattern.Version), pattern.Tempo, flStudioPPQ, pattern.Length()*ticksPerStep)
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "  <Channel id=\"%d\" name=\"%s\">\n", track.ID, xmlEscape(track.Name))
+		for i, step := range track.AllSteps() {
+			if step {
+				fmt.Fprintf(buf, "    <Beat start=\"%d\" length=\"%d\" velocity=\"100\"/>\n", i*ticksPerStep, ticksPerStep)
+			}
+		}
+		buf.WriteString("  </Channel>\n")
+	}
+	buf.WriteString("</Pattern>\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ExportReaScript writes the pattern as a REAPER Lua script. Running it
+// creates a MIDI item as long as the pattern at the edit cursor on the selected track,
+// setting the project tempo to the pattern tempo. Every track adds a text
+// event with its name and a 16th note on channel 10 for every active step.
+func (pattern *Pattern) ExportReaScript(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	// A line break would end the comment and turn the rest into code
+	version := strings.NewReplacer("\r", " ", "\n", " ").Replace(pattern.Version)
+	fmt.Fprintf(buf, "-- Drum pattern, saved with HW version %s\n", version)
+	buf.WriteString("reaper.Undo_BeginBlock()\n\n")
+	buf.WriteString("local track = reaper.GetSelectedTrack(0, 0)\n")
+	buf.WriteString("if not track then\n")
+	buf.WriteString("  reaper.InsertTrackAtIndex(reaper.CountTracks(0), true)\n")
+	buf.WriteString("  track = reaper.GetTrack(0, reaper.CountTracks(0) - 1)\n")
+	buf.WriteString("end\n\n")
+	fmt.Fprintf(buf, "local tempo = %g\n", pattern.Tempo)
+	buf.WriteString("reaper.SetCurrentBPM(0, tempo, true)\n")
+	buf.WriteString("local start = reaper.GetCursorPosition()\n")
+	fmt.Fprintf(buf, "local stepLength = 60 / (tempo * %d)\n", StepsPerBeat)
+	fmt.Fprintf(buf, "local item = reaper.CreateNewMIDIItemInProj(track, start, start + %d * stepLength, false)\n", pattern.Length())
+	buf.WriteString("local take = reaper.GetActiveTake(item)\n\n")
+	buf.WriteString("local function ppq(step)\n")
+	buf.WriteString("  return reaper.MIDI_GetPPQPosFromProjTime(take, start + step * stepLength)\n")
+	buf.WriteString("end\n\n")
+	buf.WriteString("local function note(step, pitch)\n")
+	fmt.Fprintf(buf, "  reaper.MIDI_InsertNote(take, false, false, ppq(step), ppq(step + 1), %d, pitch, 100, true)\n", midiDrumChannel)
+	buf.WriteString("end\n")
+
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "\nreaper.MIDI_InsertTextSysexEvt(take, false, false, ppq(0), 1, %s, true)\n", luaQuote(track.Name))
+		for i, step := range track.AllSteps() {
+			if step {
+				fmt.Fprintf(buf, "note(%d, %d)\n", i, trackNote(track))
+			}
+		}
+	}
+
+	buf.WriteString("\nreaper.MIDI_Sort(take)\n")
+	buf.WriteString("reaper.UpdateArrange()\n")
+	buf.WriteString("reaper.Undo_EndBlock(\"Insert drum pattern\", -1)\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// luaQuote returns s as a Lua string literal. Printable characters are kept
+// as UTF-8, other bytes use decimal escapes as Lua 5.1 has no \x or \u
+// escapes.
+func luaQuote(s string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		switch {
+		case r == '"' || r == '\\':
+			quoted.WriteByte('\\')
+			quoted.WriteRune(r)
+		case r != utf8.RuneError && unicode.IsPrint(r):
+			quoted.WriteRune(r)
+		default:
+			for _, b := range []byte(s[:size]) {
+				fmt.Fprintf(&quoted, "\\%03d", b)
+			}
+		}
+		s = s[size:]
+	}
+	quoted.WriteByte('"')
+
+	return quoted.String()
+}
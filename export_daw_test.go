@@ -0,0 +1,85 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/xml"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestExportFLStudio(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportFLStudio(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+
+	var parsed struct {
+		XMLName  xml.Name
+		Tempo    string `xml:"tempo,attr"`
+		Channels []struct {
+			Name  string `xml:"name,attr"`
+			Beats []struct {
+				Start *int `xml:"start,attr"`
+			} `xml:"Beat"`
+		} `xml:"Channel"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("output isn't valid XML - %v", err)
+	}
+	if parsed.XMLName.Local != "Pattern" || parsed.Tempo != "120" {
+		t.Fatalf("unexpected root element %s with tempo %q", parsed.XMLName.Local, parsed.Tempo)
+	}
+	if len(parsed.Channels) != 6 || parsed.Channels[0].Name != "kick" || len(parsed.Channels[0].Beats) != 4 {
+		t.Fatalf("unexpected channels %+v", parsed.Channels)
+	}
+	if start := parsed.Channels[0].Beats[1].Start; start == nil || *start != 24 {
+		t.Fatalf("expected the second kick to start at tick 24, got %v", start)
+	}
+}
+
+func TestExportReaScript(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 0, Name: "kick", Steps: [16]bool{0: true, 8: true}},
+		&Track{ID: 1, Name: "snare", MIDINote: 40, Steps: [16]bool{4: true}},
+	}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportReaScript(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if lines[0] != "-- Drum pattern, saved with HW version 0.808-alpha" || lines[1] != "reaper.Undo_BeginBlock()" {
+		t.Fatalf("expected the script to start with a REAPER API call, got:\n%s", buf.String())
+	}
+
+	expected := `reaper.MIDI_InsertTextSysexEvt(take, false, false, ppq(0), 1, "kick", true)
+note(0, 36)
+note(8, 36)
+
+reaper.MIDI_InsertTextSysexEvt(take, false, false, ppq(0), 1, "snare", true)
+note(4, 40)
+`
+	if !strings.Contains(buf.String(), expected) || !strings.Contains(buf.String(), "local tempo = 120\n") {
+		t.Fatalf("unexpected script:\n%s", buf.String())
+	}
+
+	// Names are Lua string literals and the version can't end the comment
+	pattern.Version = "0.808\nos.exit()"
+	pattern.Tracks[0].Name = "caf\u00e9 \U0001F600 \"x\"\\\n\xff"
+	buf.Reset()
+	if err := pattern.ExportReaScript(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "-- Drum pattern, saved with HW version 0.808 os.exit()\nreaper.") {
+		t.Fatalf("expected the version to stay in the comment, got:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "ppq(0), 1, \"caf\u00e9 \U0001F600 \\\"x\\\"\\\\\\010\\255\", true)") {
+		t.Fatalf("unexpected Lua string for the name:\n%s", buf.String())
+	}
+}
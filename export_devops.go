@@ -0,0 +1,496 @@
+package drum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// yamlQuote returns s as a double quoted YAML scalar. The escapes produced by
+// strconv.Quote are all valid in YAML.
+func yamlQuote(s string) string {
+	return strconv.Quote(s)
+}
+
+// yamlLine is a key/value line of a block mapping or sequence
+type yamlLine struct {
+	number int
+	indent int
+	// item is set for lines starting a sequence item with "- "
+	item  bool
+	key   string
+	value string
+}
+
+// readYAMLLines reads the key/value lines of the simple YAML documents
+// written by the exports in this file. Comments, blank lines and document
+// markers are skipped. Flow collections and multi-line scalars are not
+// supported.
+func readYAMLLines(r io.Reader) ([]yamlLine, error) {
+	var lines []yamlLine
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed[0] == '#' || trimmed == "---" {
+			continue
+		}
+
+		line := yamlLine{number: number, indent: len(text) - len(trimmed)}
+		if strings.HasPrefix(trimmed, "- ") {
+			line.item = true
+			trimmed = strings.TrimLeft(trimmed[2:], " ")
+			line.indent = len(text) - len(trimmed)
+		}
+
+		parts := strings.SplitN(trimmed, ":", 2)
+		if len(parts) != 2 || strings.HasPrefix(trimmed, "\"") {
+			return nil, fmt.Errorf("%w: line %d: expected key: value", ErrInvalidFormat, number)
+		}
+		value, err := yamlScalar(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+		line.key, line.value = strings.TrimSpace(parts[0]), value
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// yamlScalar returns the value of a plain, single or double quoted YAML
+// scalar, stripping a trailing comment from plain scalars.
+func yamlScalar(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, "\""):
+		end := len(s)
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+			} else if s[i] == '"' {
+				end = i + 1
+				break
+			}
+		}
+		value, err := strconv.Unquote(s[:end])
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid string %s", ErrInvalidFormat, s)
+		}
+		return value, nil
+	case strings.HasPrefix(s, "'"):
+		var value strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '\'' {
+				value.WriteByte(s[i])
+			} else if i+1 < len(s) && s[i+1] == '\'' {
+				value.WriteByte('\'')
+				i++
+			} else {
+				return value.String(), nil
+			}
+		}
+		return "", fmt.Errorf("%w: unterminated string", ErrInvalidFormat)
+	}
+
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s, nil
+}
+
+// ExportGitHubActions writes a GitHub Actions workflow with a matrix job per
+// track. The matrix lists the tracks as "(id) name" and the include entries
+// add the steps of every track as a string.
+func (pattern *Pattern) ExportGitHubActions(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "name: %s\n", yamlQuote("Drum pattern "+pattern.Version))
+	buf.WriteString("on: [push]\n")
+	buf.WriteString("jobs:\n")
+	buf.WriteString("  track:\n")
+	buf.WriteString("    runs-on: ubuntu-latest\n")
+	buf.WriteString("    strategy:\n")
+	buf.WriteString("      matrix:\n")
+	if len(pattern.Tracks) == 0 {
+		buf.WriteString("        track: []\n")
+	} else {
+		buf.WriteString("        track:\n")
+		for _, track := range pattern.Tracks {
+			fmt.Fprintf(buf, "          - %s\n", yamlQuote(fmt.Sprintf("(%d) %s", track.ID, track.Name)))
+		}
+		buf.WriteString("        include:\n")
+		for _, track := range pattern.Tracks {
+			fmt.Fprintf(buf, "          - track: %s\n", yamlQuote(fmt.Sprintf("(%d) %s", track.ID, track.Name)))
+			fmt.Fprintf(buf, "            id: %d\n", track.ID)
+			fmt.Fprintf(buf, "            steps: %s\n", yamlQuote(formatSteps(track.AllSteps())))
+		}
+	}
+	buf.WriteString("    steps:\n")
+	buf.WriteString("      - run: echo \"${{ matrix.track }} ${{ matrix.steps }}\"\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ExportKubernetes writes the pattern as a Kubernetes ConfigMap. The data
+// holds the version and tempo, and a track-<id> key for every track with the
+// value "name|steps".
+func (pattern *Pattern) ExportKubernetes(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	buf.WriteString("apiVersion: v1\n")
+	buf.WriteString("kind: ConfigMap\n")
+	buf.WriteString("metadata:\n")
+	buf.WriteString("  name: drum-pattern\n")
+	buf.WriteString("data:\n")
+	fmt.Fprintf(buf, "  version: %s\n", yamlQuote(pattern.Version))
+	fmt.Fprintf(buf, "  tempo: %s\n", yamlQuote(formatFloat(pattern.Tempo)))
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "  track-%d: %s\n", track.ID, yamlQuote(track.Name+"|"+formatSteps(track.AllSteps())))
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ImportKubernetes reads a ConfigMap written by ExportKubernetes into the
+// receiver. Tracks are added in the order of their keys in the document.
+func (pattern *Pattern) ImportKubernetes(r io.Reader) error {
+	lines, err := readYAMLLines(r)
+	if err != nil {
+		return err
+	}
+
+	imported := &Pattern{}
+	kind, inData := "", false
+	for _, line := range lines {
+		if line.indent == 0 {
+			inData = line.key == "data"
+			if line.key == "kind" {
+				kind = line.value
+			}
+			continue
+		}
+		if !inData {
+			continue
+		}
+
+		if !strings.HasPrefix(line.key, "track-") {
+			if err := imported.setField(nil, "", "", line.key, line.value); err != nil {
+				return fmt.Errorf("line %d: %w", line.number, err)
+			}
+			continue
+		}
+
+		id, err := strconv.Atoi(strings.TrimPrefix(line.key, "track-"))
+		if err != nil {
+			return fmt.Errorf("%w: line %d: invalid track ID in %q", ErrInvalidFormat, line.number, line.key)
+		}
+		separator := strings.LastIndex(line.value, "|")
+		if separator < 0 {
+			return fmt.Errorf("%w: line %d: expected name|steps", ErrInvalidFormat, line.number)
+		}
+		track := &Track{ID: id, Name: line.value[:separator]}
+		if err := track.setStepString(line.value[separator+1:]); err != nil {
+			return fmt.Errorf("line %d: %w", line.number, err)
+		}
+		imported.Tracks = append(imported.Tracks, track)
+	}
+	if kind != "ConfigMap" {
+		return fmt.Errorf("%w: expected a ConfigMap, got %q", ErrInvalidFormat, kind)
+	}
+
+	*pattern = *imported
+	return nil
+}
+
+// dockerEnvPrefix is the prefix of the variables written by ExportDockerEnv
+const dockerEnvPrefix = "SPLICE_"
+
+// ExportDockerEnv writes the pattern as environment variables in the format
+// of a Docker --env-file:
+//
+//	SPLICE_VERSION=0.808-alpha
+//	SPLICE_TEMPO=120.0
+//	SPLICE_TRACK_0_ID=1
+//	SPLICE_TRACK_0_NAME=kick
+//	SPLICE_TRACK_0_STEPS=x---x---x---x---
+func (pattern *Pattern) ExportDockerEnv(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "%sVERSION=%s\n", dockerEnvPrefix, pattern.Version)
+	fmt.Fprintf(buf, "%sTEMPO=%s\n", dockerEnvPrefix, formatFloat(pattern.Tempo))
+	for i, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "%sTRACK_%d_ID=%d\n", dockerEnvPrefix, i, track.ID)
+		fmt.Fprintf(buf, "%sTRACK_%d_NAME=%s\n", dockerEnvPrefix, i, track.Name)
+		fmt.Fprintf(buf, "%sTRACK_%d_STEPS=%s\n", dockerEnvPrefix, i, formatSteps(track.AllSteps()))
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ImportDockerEnv reads variables written by ExportDockerEnv into the
+// receiver. Lines may also be Dockerfile ENV declarations of the form
+// ENV KEY=value, variables without the SPLICE_ prefix are ignored.
+func (pattern *Pattern) ImportDockerEnv(r io.Reader) error {
+	imported := &Pattern{}
+	tracks := make(map[int]*Track)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		text = strings.TrimPrefix(text, "ENV ")
+
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("%w: line %d: expected KEY=value", ErrInvalidFormat, line)
+		}
+		if !strings.HasPrefix(parts[0], dockerEnvPrefix) {
+			continue
+		}
+
+		key := strings.ToLower(strings.TrimPrefix(parts[0], dockerEnvPrefix))
+		if err := imported.setField(tracks, "track_", "_", key, parts[1]); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	imported.Tracks = sortedTracks(tracks)
+	*pattern = *imported
+	return nil
+}
+
+// ExportHelmValues writes the pattern as a Helm values.yaml fragment under a
+// drumPattern key.
+func (pattern *Pattern) ExportHelmValues(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	buf.WriteString("drumPattern:\n")
+	fmt.Fprintf(buf, "  version: %s\n", yamlQuote(pattern.Version))
+	fmt.Fprintf(buf, "  tempo: %s\n", formatFloat(pattern.Tempo))
+	if len(pattern.Tracks) == 0 {
+		buf.WriteString("  tracks: []\n")
+	} else {
+		buf.WriteString("  tracks:\n")
+	}
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "    - id: %d\n", track.ID)
+		fmt.Fprintf(buf, "      name: %s\n", yamlQuote(track.Name))
+		fmt.Fprintf(buf, "      steps: %s\n", yamlQuote(formatSteps(track.AllSteps())))
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ImportHelmValues reads the drumPattern key of values written by
+// ExportHelmValues into the receiver, other keys are ignored.
+func (pattern *Pattern) ImportHelmValues(r io.Reader) error {
+	lines, err := readYAMLLines(r)
+	if err != nil {
+		return err
+	}
+
+	imported := &Pattern{}
+	var track *Track
+	found, inPattern, inTracks := false, false, false
+	for _, line := range lines {
+		switch {
+		case line.indent == 0:
+			inPattern = line.key == "drumPattern"
+			found = found || inPattern
+			inTracks = false
+			continue
+		case !inPattern:
+			continue
+		case line.item:
+			if !inTracks {
+				return fmt.Errorf("%w: line %d: unexpected list item", ErrInvalidFormat, line.number)
+			}
+			track = &Track{}
+			imported.Tracks = append(imported.Tracks, track)
+		case !inTracks || track == nil || line.indent <= 2:
+			inTracks = line.key == "tracks"
+			track = nil
+			if err := imported.setField(nil, "", "", line.key, line.value); err != nil {
+				return fmt.Errorf("line %d: %w", line.number, err)
+			}
+			continue
+		}
+
+		if err := track.setField(line.key, line.value); err != nil {
+			return fmt.Errorf("line %d: %w", line.number, err)
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: drumPattern not found", ErrInvalidFormat)
+	}
+
+	*pattern = *imported
+	return nil
+}
+
+// ExportTerraform writes the pattern as a Terraform locals block defining
+// local.drum_pattern as an HCL object.
+func (pattern *Pattern) ExportTerraform(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	buf.WriteString("locals {\n")
+	buf.WriteString("  drum_pattern = {\n")
+	fmt.Fprintf(buf, "    version = %s\n", hclQuote(pattern.Version))
+	fmt.Fprintf(buf, "    tempo   = %g\n", pattern.Tempo)
+	buf.WriteString("    tracks = [\n")
+	for _, track := range pattern.Tracks {
+		buf.WriteString("      {\n")
+		fmt.Fprintf(buf, "        id    = %d\n", track.ID)
+		fmt.Fprintf(buf, "        name  = %s\n", hclQuote(track.Name))
+		fmt.Fprintf(buf, "        steps = [%s]\n", joinSteps(track, "true", "false", ", "))
+		buf.WriteString("      },\n")
+	}
+	buf.WriteString("    ]\n")
+	buf.WriteString("  }\n")
+	buf.WriteString("}\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// hclQuote returns s as an HCL quoted string, escaping template sequences
+func hclQuote(s string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			quoted.WriteByte('\\')
+			quoted.WriteRune(r)
+		case r == '\n':
+			quoted.WriteString("\\n")
+		case r == '\t':
+			quoted.WriteString("\\t")
+		case r < 0x20 || r == 0x7F:
+			fmt.Fprintf(&quoted, "\\u%04X", r)
+		case (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{"):
+			quoted.WriteRune(r)
+			quoted.WriteRune(r)
+		default:
+			quoted.WriteRune(r)
+		}
+	}
+	quoted.WriteByte('"')
+
+	return quoted.String()
+}
+
+// ExportAnsible writes the pattern as an Ansible vars file defining a
+// drum_pattern variable. The steps of every track are a list of 1 and 0,
+// next to the derived density of the track.
+func (pattern *Pattern) ExportAnsible(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	buf.WriteString("---\n")
+	buf.WriteString("drum_pattern:\n")
+	fmt.Fprintf(buf, "  version: %s\n", yamlQuote(pattern.Version))
+	fmt.Fprintf(buf, "  tempo: %s\n", formatFloat(pattern.Tempo))
+	if len(pattern.Tracks) == 0 {
+		buf.WriteString("  tracks: []\n")
+	} else {
+		buf.WriteString("  tracks:\n")
+	}
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "    - id: %d\n", track.ID)
+		fmt.Fprintf(buf, "      name: %s\n", yamlQuote(track.Name))
+		fmt.Fprintf(buf, "      steps: [%s]\n", joinSteps(track, "1", "0", ", "))
+		fmt.Fprintf(buf, "      density: %s\n", formatFloat(float32(track.Density())))
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+type grafanaDashboard struct {
+	Title         string         `json:"title"`
+	Tags          []string       `json:"tags"`
+	SchemaVersion int            `json:"schemaVersion"`
+	Panels        []grafanaPanel `json:"panels"`
+}
+
+type grafanaPanel struct {
+	ID         int                    `json:"id"`
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	GridPos    grafanaGridPos         `json:"gridPos"`
+	Datasource grafanaDatasource      `json:"datasource"`
+	Targets    []grafanaTarget        `json:"targets"`
+	Options    map[string]interface{} `json:"options,omitempty"`
+}
+
+type grafanaGridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type grafanaDatasource struct {
+	Type string `json:"type"`
+}
+
+type grafanaTarget struct {
+	RefID      string `json:"refId"`
+	ScenarioID string `json:"scenarioId"`
+	CSVContent string `json:"csvContent"`
+}
+
+// ExportGrafanaDashboard writes the pattern as a Grafana dashboard with a
+// stat panel showing the tempo and a bar gauge panel showing the density of
+// every track. The values are embedded as CSV content of the TestData data
+// source, so the dashboard can be imported as is.
+func (pattern *Pattern) ExportGrafanaDashboard(w io.Writer) error {
+	datasource := grafanaDatasource{Type: "testdata"}
+	densities := []string{"track,density"}
+	for _, track := range pattern.Tracks {
+		name := strings.NewReplacer(",", " ", "\n", " ").Replace(fmt.Sprintf("(%d) %s", track.ID, track.Name))
+		densities = append(densities, fmt.Sprintf("%s,%g", name, track.Density()))
+	}
+
+	dashboard := grafanaDashboard{
+		Title:         "Drum pattern " + pattern.Version,
+		Tags:          []string{"drum"},
+		SchemaVersion: 39,
+		Panels: []grafanaPanel{
+			{
+				ID:         1,
+				Type:       "stat",
+				Title:      "Tempo (BPM)",
+				GridPos:    grafanaGridPos{H: 8, W: 6, X: 0, Y: 0},
+				Datasource: datasource,
+				Targets:    []grafanaTarget{{"A", "csv_content", fmt.Sprintf("bpm\n%g", pattern.Tempo)}},
+			},
+			{
+				ID:         2,
+				Type:       "bargauge",
+				Title:      "Track density",
+				GridPos:    grafanaGridPos{H: 8, W: 18, X: 6, Y: 0},
+				Datasource: datasource,
+				Targets:    []grafanaTarget{{"A", "csv_content", strings.Join(densities, "\n")}},
+				Options: map[string]interface{}{
+					"orientation":   "horizontal",
+					"reduceOptions": map[string]interface{}{"values": true, "calcs": []string{}},
+				},
+			},
+		},
+	}
+
+	data, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
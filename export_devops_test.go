@@ -0,0 +1,223 @@
+package drum
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestExportGitHubActions(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportGitHubActions(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	workflow := buf.String()
+	if strings.Contains(workflow, "\t") {
+		t.Fatalf("YAML can't be indented with tabs:\n%s", workflow)
+	}
+	if !strings.Contains(workflow, "    strategy:\n      matrix:\n        track:\n          - \"(0) kick\"\n") {
+		t.Fatalf("matrix not found in:\n%s", workflow)
+	}
+	if entries := strings.Count(workflow, "\n          - \""); entries != len(pattern.Tracks) {
+		t.Fatalf("expected %d matrix entries, got %d", len(pattern.Tracks), entries)
+	}
+	if includes := strings.Count(workflow, "\n          - track: "); includes != len(pattern.Tracks) {
+		t.Fatalf("expected %d include entries, got %d", len(pattern.Tracks), includes)
+	}
+	if !strings.Contains(workflow, "            steps: \"x---x---x---x---\"\n") {
+		t.Fatalf("steps not found in:\n%s", workflow)
+	}
+}
+
+func TestKubernetesRoundTrip(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		var buf bytes.Buffer
+		if err := pattern.ExportKubernetes(&buf); err != nil {
+			t.Fatalf("something went wrong exporting %s - %v", name, err)
+		}
+		if !strings.HasPrefix(buf.String(), "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: drum-pattern\ndata:\n") {
+			t.Fatalf("%s: not a ConfigMap:\n%s", name, buf.String())
+		}
+
+		var imported Pattern
+		if err := imported.ImportKubernetes(&buf); err != nil {
+			t.Fatalf("something went wrong importing %s - %v", name, err)
+		}
+		if imported.String() != pattern.String() {
+			t.Fatalf("%s didn't survive the round trip.\nGot:\n%s\nExpected:\n%s", name, &imported, pattern)
+		}
+	}
+
+	configMap := `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other
+data:
+  version: '0.909'
+  tempo: "98.4"  # BPM
+  track-7: 'it''s a|b|x-x-x-x-x-x-x-x-'
+`
+	var imported Pattern
+	if err := imported.ImportKubernetes(strings.NewReader(configMap)); err != nil {
+		t.Fatalf("something went wrong importing - %v", err)
+	}
+	if imported.Version != "0.909" || imported.Tempo != 98.4 || imported.Tracks[0].ID != 7 || imported.Tracks[0].Name != "it's a|b" {
+		t.Fatalf("unexpected pattern:\n%s", &imported)
+	}
+}
+
+func TestDockerEnvRoundTrip(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		var buf bytes.Buffer
+		if err := pattern.ExportDockerEnv(&buf); err != nil {
+			t.Fatalf("something went wrong exporting %s - %v", name, err)
+		}
+
+		var imported Pattern
+		if err := imported.ImportDockerEnv(&buf); err != nil {
+			t.Fatalf("something went wrong importing %s - %v", name, err)
+		}
+		if imported.String() != pattern.String() {
+			t.Fatalf("%s didn't survive the round trip.\nGot:\n%s\nExpected:\n%s", name, &imported, pattern)
+		}
+	}
+
+	dockerfile := `FROM scratch
+ENV SPLICE_VERSION=0.909
+ENV SPLICE_TEMPO=98.4
+ENV SPLICE_TRACK_0_ID=3
+ENV SPLICE_TRACK_0_NAME=hh=open
+ENV SPLICE_TRACK_0_STEPS=x-x-x-x-x-x-x-x-
+`
+	var imported Pattern
+	if err := imported.ImportDockerEnv(strings.NewReader(dockerfile)); err == nil {
+		t.Fatalf("expected an error for the FROM line")
+	}
+	dockerfile = strings.TrimPrefix(dockerfile, "FROM scratch\n")
+	if err := imported.ImportDockerEnv(strings.NewReader(dockerfile)); err != nil {
+		t.Fatalf("something went wrong importing - %v", err)
+	}
+	if imported.Version != "0.909" || imported.Tempo != 98.4 || imported.Tracks[0].Name != "hh=open" {
+		t.Fatalf("unexpected pattern:\n%s", &imported)
+	}
+}
+
+func TestHelmValuesRoundTrip(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		var buf bytes.Buffer
+		if err := pattern.ExportHelmValues(&buf); err != nil {
+			t.Fatalf("something went wrong exporting %s - %v", name, err)
+		}
+
+		var imported Pattern
+		if err := imported.ImportHelmValues(&buf); err != nil {
+			t.Fatalf("something went wrong importing %s - %v", name, err)
+		}
+		if imported.String() != pattern.String() {
+			t.Fatalf("%s didn't survive the round trip.\nGot:\n%s\nExpected:\n%s", name, &imported, pattern)
+		}
+	}
+
+	values := `replicaCount: 1
+drumPattern:
+  version: "0.909"
+  tracks:
+    - id: 3
+      # open hi-hat
+      name: hh-open
+      steps: "x-x-x-x-x-x-x-x-"
+  tempo: 98.4
+image:
+  tag: latest
+`
+	var imported Pattern
+	if err := imported.ImportHelmValues(strings.NewReader(values)); err != nil {
+		t.Fatalf("something went wrong importing - %v", err)
+	}
+	if imported.Version != "0.909" || imported.Tempo != 98.4 || len(imported.Tracks) != 1 || imported.Tracks[0].Name != "hh-open" {
+		t.Fatalf("unexpected pattern:\n%s", &imported)
+	}
+}
+
+func TestExportTerraform(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 98.4, Tracks: []*Track{
+		&Track{ID: 1, Name: "${kick}", Steps: [16]bool{0: true}},
+	}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportTerraform(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	expected := `locals {
+  drum_pattern = {
+    version = "0.808-alpha"
+    tempo   = 98.4
+    tracks = [
+      {
+        id    = 1
+        name  = "$${kick}"
+        steps = [true, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false]
+      },
+    ]
+  }
+}
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output.\nGot:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestExportAnsible(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 1, Name: "kick", Steps: [16]bool{0: true, 4: true, 8: true, 12: true}},
+		&Track{ID: 2, Name: "silence"},
+	}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportAnsible(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	expected := `---
+drum_pattern:
+  version: "0.808-alpha"
+  tempo: 120.0
+  tracks:
+    - id: 1
+      name: "kick"
+      steps: [1, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0]
+      density: 0.25
+    - id: 2
+      name: "silence"
+      steps: [0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0]
+      density: 0.0
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output.\nGot:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestExportGrafanaDashboard(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_2.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportGrafanaDashboard(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, `"type": "stat"`) || !strings.Contains(output, `"csvContent": "bpm\n98.4"`) {
+		t.Fatalf("tempo stat panel not found:\n%s", output)
+	}
+	if !strings.Contains(output, `"type": "bargauge"`) || !strings.Contains(output, `(0) kick,0.125`) {
+		t.Fatalf("density panel not found:\n%s", output)
+	}
+}
@@ -0,0 +1,147 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ExportGraphviz writes the pattern as a Graphviz DOT graph with a node per
+// step. Steps which are active in any track are colored red, and every track
+// adds an edge labeled with its name from each active step to its next
+// active step.
+func (pattern *Pattern) ExportGraphviz(w io.Writer) error {
+	active := make([]bool, pattern.Length())
+	for _, track := range pattern.Tracks {
+		for i, step := range track.AllSteps() {
+			active[i] = active[i] || step
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("digraph pattern {\n")
+	fmt.Fprintf(buf, "  label=%s;\n", strconv.Quote(pattern.Version))
+	buf.WriteString("  rankdir=LR;\n")
+	buf.WriteString("  node [shape=circle, style=filled, fillcolor=white];\n")
+	for i, step := range active {
+		if step {
+			fmt.Fprintf(buf, "  step%d [label=\"%d\", fillcolor=red];\n", i, i+1)
+		} else {
+			fmt.Fprintf(buf, "  step%d [label=\"%d\"];\n", i, i+1)
+		}
+	}
+
+	for _, track := range pattern.Tracks {
+		previous := -1
+		for i, step := range track.AllSteps() {
+			if !step {
+				continue
+			}
+			if previous >= 0 {
+				fmt.Fprintf(buf, "  step%d -> step%d [label=%s];\n", previous, i, strconv.Quote(track.Name))
+			}
+			previous = i
+		}
+	}
+	buf.WriteString("}\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ExportMermaid writes the pattern as a Mermaid gantt chart titled with the
+// pattern version. Every track is a section with a task per active step,
+// timed in milliseconds at the pattern tempo.
+func (pattern *Pattern) ExportMermaid(w io.Writer) error {
+	stepMillis := 60000 / (float64(pattern.Tempo) * StepsPerBeat)
+	millis := func(step int) int64 {
+		return int64(math.Round(float64(step) * stepMillis))
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("gantt\n")
+	fmt.Fprintf(buf, "    title %s\n", mermaidEscape(pattern.Version))
+	buf.WriteString("    dateFormat x\n")
+	buf.WriteString("    axisFormat %L\n")
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "    section %s\n", mermaidEscape(track.Name))
+		for i, step := range track.AllSteps() {
+			if step {
+				fmt.Fprintf(buf, "    step %d : %d, %d\n", i+1, millis(i), millis(i+1))
+			}
+		}
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// mermaidEscape replaces the characters which end a statement or start a
+// comment in Mermaid.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(":", " ", ";", " ", "#", " ", "%", " ", "\n", " ").Replace(s)
+}
+
+type vegaSpec struct {
+	Schema      string                  `json:"$schema"`
+	Description string                  `json:"description"`
+	Data        vegaData                `json:"data"`
+	Mark        string                  `json:"mark"`
+	Encoding    map[string]vegaEncoding `json:"encoding"`
+}
+
+type vegaData struct {
+	Values []vegaValue `json:"values"`
+}
+
+type vegaValue struct {
+	Track  string `json:"track"`
+	Step   int    `json:"step"`
+	Active bool   `json:"active"`
+}
+
+type vegaEncoding struct {
+	Field string      `json:"field"`
+	Type  string      `json:"type"`
+	Sort  interface{} `json:"sort,omitempty"`
+	Scale interface{} `json:"scale,omitempty"`
+}
+
+// ExportVega writes the pattern as a Vega-Lite spec rendering a heatmap with
+// a row per track and a column per step, colored by the active state.
+func (pattern *Pattern) ExportVega(w io.Writer) error {
+	spec := vegaSpec{
+		Schema:      "https://vega.github.io/schema/vega-lite/v5.json",
+		Description: "Drum pattern " + pattern.Version,
+		Data:        vegaData{Values: []vegaValue{}},
+		Mark:        "rect",
+	}
+
+	var order []string
+	for _, track := range pattern.Tracks {
+		name := fmt.Sprintf("(%d) %s", track.ID, track.Name)
+		order = append(order, name)
+		for i, step := range track.AllSteps() {
+			spec.Data.Values = append(spec.Data.Values, vegaValue{name, i + 1, step})
+		}
+	}
+	spec.Encoding = map[string]vegaEncoding{
+		"x": {Field: "step", Type: "ordinal"},
+		"y": {Field: "track", Type: "nominal", Sort: order},
+		"color": {Field: "active", Type: "nominal", Scale: map[string][]interface{}{
+			"domain": {true, false},
+			"range":  {"#e63", "#36a"},
+		}},
+	}
+
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
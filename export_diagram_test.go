@@ -0,0 +1,74 @@
+package drum
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestExportGraphviz(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportGraphviz(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "digraph ") {
+		t.Fatalf("expected a digraph, got:\n%s", buf.String())
+	}
+
+	// kick: 4 steps, snare: 2, clap: 2, hh-open: 5, hh-close: 4, cowbell: 1
+	if edges := strings.Count(buf.String(), " -> "); edges != 3+1+1+4+3 {
+		t.Fatalf("expected 12 edges, got %d", edges)
+	}
+	if !strings.Contains(buf.String(), `step0 -> step4 [label="kick"];`) {
+		t.Fatalf("kick edge not found in:\n%s", buf.String())
+	}
+}
+
+func TestExportMermaid(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 0, Name: "kick", Steps: [16]bool{0: true, 8: true}},
+		&Track{ID: 1, Name: "snare: rim", Steps: [16]bool{15: true}},
+	}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportMermaid(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	expected := `gantt
+    title 0.808-alpha
+    dateFormat x
+    axisFormat %L
+    section kick
+    step 1 : 0, 125
+    step 9 : 1000, 1125
+    section snare  rim
+    step 16 : 1875, 2000
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output.\nGot:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestExportVega(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_2.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportVega(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	if !strings.Contains(buf.String(), `"$schema"`) || !strings.Contains(buf.String(), `"mark": "rect"`) {
+		t.Fatalf("not a Vega-Lite rect spec:\n%s", buf.String())
+	}
+	if count := strings.Count(buf.String(), `"step": `); count != len(pattern.Tracks)*StepCount {
+		t.Fatalf("expected %d values, got %d", len(pattern.Tracks)*StepCount, count)
+	}
+}
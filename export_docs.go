@@ -0,0 +1,90 @@
+package drum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ExportMarkdown writes the pattern tempo and a Markdown table with a row
+// per track and a column per step, marking active steps with an x.
+func (pattern *Pattern) ExportMarkdown(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "**Tempo:** %g BPM\n\n", pattern.Tempo)
+
+	buf.WriteString("| Track |")
+	for i := 1; i <= pattern.Length(); i++ {
+		fmt.Fprintf(buf, " %d |", i)
+	}
+	buf.WriteString("\n|---|")
+	buf.WriteString(strings.Repeat(":-:|", pattern.Length()))
+	buf.WriteString("\n")
+
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "| (%d) %s |", track.ID, markdownEscape(track.Name))
+		for _, step := range track.AllSteps() {
+			if step {
+				buf.WriteString(" x |")
+			} else {
+				buf.WriteString("   |")
+			}
+		}
+		buf.WriteString("\n")
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// markdownEscape escapes the characters with a special meaning in Markdown
+// table cells.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", "\\|", "*", "\\*", "_", "\\_", "`", "\\`").Replace(s)
+}
+
+// ExportObsidianCallout writes the Markdown of ExportMarkdown wrapped in an
+// Obsidian info callout titled with the pattern version.
+func (pattern *Pattern) ExportObsidianCallout(w io.Writer) error {
+	markdown := new(bytes.Buffer)
+	if err := pattern.ExportMarkdown(markdown); err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "> [!INFO] Pattern: %s\n", pattern.Version)
+	scanner := bufio.NewScanner(markdown)
+	for scanner.Scan() {
+		if scanner.Text() == "" {
+			buf.WriteString(">\n")
+		} else {
+			fmt.Fprintf(buf, "> %s\n", scanner.Text())
+		}
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ExportNotionDatabase writes the tracks of the pattern as CSV for a Notion
+// database import, with the columns Name, ID, Active Steps, Density and
+// Pattern.
+func (pattern *Pattern) ExportNotionDatabase(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"Name", "ID", "Active Steps", "Density", "Pattern"})
+	for _, track := range pattern.Tracks {
+		writer.Write([]string{
+			track.Name,
+			strconv.Itoa(track.ID),
+			strconv.Itoa(track.ActiveStepCount()),
+			strconv.FormatFloat(track.Density(), 'g', -1, 64),
+			formatSteps(track.AllSteps()),
+		})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,51 @@
+package drum
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestExportObsidianCallout(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 1, Name: "hh_open|closed", Steps: [16]bool{0: true, 15: true}},
+	}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportObsidianCallout(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	expected := `> [!INFO] Pattern: 0.808-alpha
+> **Tempo:** 120 BPM
+>
+> | Track | 1 | 2 | 3 | 4 | 5 | 6 | 7 | 8 | 9 | 10 | 11 | 12 | 13 | 14 | 15 | 16 |
+> |---|:-:|:-:|:-:|:-:|:-:|:-:|:-:|:-:|:-:|:-:|:-:|:-:|:-:|:-:|:-:|:-:|
+> | (1) hh\_open\|closed | x |   |   |   |   |   |   |   |   |   |   |   |   |   |   | x |
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output.\nGot:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestExportNotionDatabase(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportNotionDatabase(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if lines[0] != "Name,ID,Active Steps,Density,Pattern" {
+		t.Fatalf("unexpected header %q", lines[0])
+	}
+	if lines[4] != "hh-open,3,5,0.3125,--x---x-x-x---x-" {
+		t.Fatalf("unexpected row %q", lines[4])
+	}
+	if len(lines) != len(pattern.Tracks)+2 {
+		t.Fatalf("expected %d rows, got %d", len(pattern.Tracks)+1, len(lines)-1)
+	}
+}
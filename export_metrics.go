@@ -0,0 +1,315 @@
+package drum
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// now returns the time used for the timestamps of the exported metrics and
+// traces.
+var now = time.Now
+
+// ExportPrometheus writes the pattern as gauges in the Prometheus text
+// exposition format, terminated with # EOF so the output is valid
+// OpenMetrics as well.
+func (pattern *Pattern) ExportPrometheus(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	buf.WriteString("# HELP drum_pattern_tempo Tempo of the pattern in beats per minute.\n")
+	buf.WriteString("# TYPE drum_pattern_tempo gauge\n")
+	fmt.Fprintf(buf, "drum_pattern_tempo{version=\"%s\"} %g\n", prometheusEscape(pattern.Version), pattern.Tempo)
+
+	buf.WriteString("# HELP drum_track_density Ratio of active steps of the track.\n")
+	buf.WriteString("# TYPE drum_track_density gauge\n")
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "drum_track_density{name=\"%s\",id=\"%d\"} %g\n", prometheusEscape(track.Name), track.ID, track.Density())
+	}
+
+	buf.WriteString("# HELP drum_track_active_steps Number of active steps of the track.\n")
+	buf.WriteString("# TYPE drum_track_active_steps gauge\n")
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "drum_track_active_steps{name=\"%s\",id=\"%d\"} %d\n", prometheusEscape(track.Name), track.ID, track.ActiveStepCount())
+	}
+	buf.WriteString("# EOF\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// prometheusEscape escapes a label value
+func prometheusEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+type otlpTrace struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+	Events            []otlpEvent     `json:"events"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string          `json:"timeUnixNano"`
+	Name         string          `json:"name"`
+	Attributes   []otlpAttribute `json:"attributes"`
+}
+
+type otlpAttribute struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+// otlpString returns a string attribute
+func otlpString(key, value string) otlpAttribute {
+	return otlpAttribute{key, map[string]interface{}{"stringValue": value}}
+}
+
+// otlpInt returns an int attribute, which is encoded as a string in OTLP JSON
+func otlpInt(key string, value int) otlpAttribute {
+	return otlpAttribute{key, map[string]interface{}{"intValue": strconv.Itoa(value)}}
+}
+
+// ExportOpenTelemetry writes the pattern as an OTLP JSON trace. A single
+// internal span covers the steps of the pattern starting now, with an event
+// for every active step of every track. The trace and span IDs are derived from the pattern
+// content.
+func (pattern *Pattern) ExportOpenTelemetry(w io.Writer) error {
+	id := sha256.Sum256(pattern.Bytes())
+	start := now()
+	stepDuration := pattern.BarDuration() / StepCount
+	end := start.Add(time.Duration(pattern.Length()) * stepDuration)
+	nanos := func(t time.Time) string {
+		return strconv.FormatInt(t.UnixNano(), 10)
+	}
+
+	span := otlpSpan{
+		TraceID:           hex.EncodeToString(id[:16]),
+		SpanID:            hex.EncodeToString(id[16:24]),
+		Name:              "bar",
+		Kind:              1,
+		StartTimeUnixNano: nanos(start),
+		EndTimeUnixNano:   nanos(end),
+		Attributes: []otlpAttribute{
+			otlpString("drum.version", pattern.Version),
+			{"drum.tempo", map[string]interface{}{"doubleValue": pattern.Tempo}},
+		},
+		Events: []otlpEvent{},
+	}
+	for i := 0; i < pattern.Length(); i++ {
+		for _, track := range pattern.Tracks {
+			if step, err := track.Step(i); err != nil || !step.On {
+				continue
+			}
+			span.Events = append(span.Events, otlpEvent{
+				TimeUnixNano: nanos(start.Add(time.Duration(i) * stepDuration)),
+				Name:         fmt.Sprintf("step %d", i+1),
+				Attributes: []otlpAttribute{
+					otlpString("drum.track.name", track.Name),
+					otlpInt("drum.track.id", track.ID),
+					otlpInt("drum.step", i),
+				},
+			})
+		}
+	}
+
+	trace := otlpTrace{[]otlpResourceSpans{{
+		Resource:   otlpResource{[]otlpAttribute{otlpString("service.name", "drum")}},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{"drum"}, Spans: []otlpSpan{span}}},
+	}}}
+
+	data, err := json.MarshalIndent(trace, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+type datadogPayload struct {
+	Series []datadogSeries `json:"series"`
+}
+
+type datadogSeries struct {
+	Metric string       `json:"metric"`
+	Type   string       `json:"type"`
+	Points [][2]float64 `json:"points"`
+	Tags   []string     `json:"tags"`
+}
+
+// ExportDatadog writes the pattern as a Datadog v1 metrics payload with a
+// drum.pattern.tempo gauge and a drum.track.density gauge for every track,
+// tagged with the pattern version and track name.
+func (pattern *Pattern) ExportDatadog(w io.Writer) error {
+	timestamp := float64(now().Unix())
+	versionTag := "pattern_version:" + pattern.Version
+
+	payload := datadogPayload{[]datadogSeries{{
+		Metric: "drum.pattern.tempo",
+		Type:   "gauge",
+		Points: [][2]float64{{timestamp, float64(pattern.Tempo)}},
+		Tags:   []string{versionTag},
+	}}}
+	for _, track := range pattern.Tracks {
+		payload.Series = append(payload.Series, datadogSeries{
+			Metric: "drum.track.density",
+			Type:   "gauge",
+			Points: [][2]float64{{timestamp, track.Density()}},
+			Tags:   []string{versionTag, "track_name:" + track.Name, fmt.Sprintf("track_id:%d", track.ID)},
+		})
+	}
+
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+type cloudWatchPayload struct {
+	Namespace  string            `json:"Namespace"`
+	MetricData []cloudWatchDatum `json:"MetricData"`
+}
+
+type cloudWatchDatum struct {
+	MetricName string                `json:"MetricName"`
+	Dimensions []cloudWatchDimension `json:"Dimensions"`
+	Value      float64               `json:"Value"`
+	Unit       string                `json:"Unit"`
+}
+
+type cloudWatchDimension struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+// ExportCloudWatch writes the pattern as the JSON body of a CloudWatch
+// PutMetricData request in the DrumPattern namespace, with a PatternTempo
+// datum and a TrackDensity datum for every track.
+func (pattern *Pattern) ExportCloudWatch(w io.Writer) error {
+	payload := cloudWatchPayload{
+		Namespace: "DrumPattern",
+		MetricData: []cloudWatchDatum{{
+			MetricName: "PatternTempo",
+			Dimensions: []cloudWatchDimension{{"PatternVersion", pattern.Version}},
+			Value:      float64(pattern.Tempo),
+			Unit:       "None",
+		}},
+	}
+	for _, track := range pattern.Tracks {
+		payload.MetricData = append(payload.MetricData, cloudWatchDatum{
+			MetricName: "TrackDensity",
+			Dimensions: []cloudWatchDimension{{"TrackName", track.Name}},
+			Value:      track.Density(),
+			Unit:       "None",
+		})
+	}
+
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// ExportInfluxDB writes the pattern in the InfluxDB line protocol, a
+// drum_pattern point with the tempo followed by a drum_track point for every
+// track. The points have no timestamp, so the server time is used.
+func (pattern *Pattern) ExportInfluxDB(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	buf.WriteString("drum_pattern")
+	if pattern.Version != "" {
+		fmt.Fprintf(buf, ",version=%s", influxEscape(pattern.Version))
+	}
+	fmt.Fprintf(buf, " tempo=%s\n", formatFloat(pattern.Tempo))
+
+	for _, track := range pattern.Tracks {
+		buf.WriteString("drum_track")
+		if track.Name != "" {
+			fmt.Fprintf(buf, ",name=%s", influxEscape(track.Name))
+		}
+		fmt.Fprintf(buf, ",id=%d density=%g,active_steps=%di\n", track.ID, track.Density(), track.ActiveStepCount())
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// influxEscape escapes a tag value, line breaks can't be escaped and are
+// replaced by spaces.
+func influxEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`, " ", `\ `, "\n", `\ `, "\r", `\ `).Replace(s)
+}
+
+// elasticsearchIndex is the index used by ExportElasticsearch
+const elasticsearchIndex = "patterns"
+
+// ExportElasticsearch writes the pattern as an Elasticsearch bulk request
+// indexing a pattern document and a track document for every track.
+func (pattern *Pattern) ExportElasticsearch(w io.Writer) error {
+	action := map[string]interface{}{"index": map[string]string{"_index": elasticsearchIndex}}
+	documents := []interface{}{map[string]interface{}{
+		"type":    "pattern",
+		"version": pattern.Version,
+		"tempo":   pattern.Tempo,
+		"tracks":  len(pattern.Tracks),
+	}}
+	for _, track := range pattern.Tracks {
+		documents = append(documents, map[string]interface{}{
+			"type":            "track",
+			"pattern_version": pattern.Version,
+			"id":              track.ID,
+			"name":            track.Name,
+			"steps":           formatSteps(track.AllSteps()),
+			"active_steps":    track.ActiveStepCount(),
+			"density":         track.Density(),
+		})
+	}
+
+	buf := new(bytes.Buffer)
+	encoder := json.NewEncoder(buf)
+	for _, document := range documents {
+		if err := encoder.Encode(action); err != nil {
+			return err
+		}
+		if err := encoder.Encode(document); err != nil {
+			return err
+		}
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
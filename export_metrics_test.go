@@ -0,0 +1,205 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportPrometheus(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+	pattern.Tracks[0].Name = `kick "808"`
+
+	var buf bytes.Buffer
+	if err := pattern.ExportPrometheus(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "\n# EOF\n") {
+		t.Fatalf("output doesn't end with # EOF:\n%s", buf.String())
+	}
+
+	sample := regexp.MustCompile(`^[a-z_]+\{([a-z]+="([^"\\]|\\.)*",?)+\} [0-9.e+-]+$`)
+	samples := 0
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if strings.HasPrefix(line, "# ") {
+			continue
+		}
+		if !sample.MatchString(line) {
+			t.Fatalf("invalid sample %q", line)
+		}
+		samples++
+	}
+	if samples != 1+2*len(pattern.Tracks) {
+		t.Fatalf("expected %d samples, got %d", 1+2*len(pattern.Tracks), samples)
+	}
+	if !strings.Contains(buf.String(), `drum_track_active_steps{name="kick \"808\"",id="0"} 4`) {
+		t.Fatalf("kick sample not found:\n%s", buf.String())
+	}
+}
+
+func TestExportOpenTelemetry(t *testing.T) {
+	defer func() { now = time.Now }()
+	now = func() time.Time { return time.Unix(1000, 0) }
+
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 1, Name: "kick", Steps: [16]bool{0: true, 8: true}},
+		&Track{ID: 2, Name: "snare", Steps: [16]bool{4: true}},
+	}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportOpenTelemetry(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	if !strings.Contains(buf.String(), `"resourceSpans"`) {
+		t.Fatalf("resourceSpans not found:\n%s", buf.String())
+	}
+
+	var trace otlpTrace
+	if err := json.Unmarshal(buf.Bytes(), &trace); err != nil {
+		t.Fatalf("output isn't valid JSON - %v", err)
+	}
+	span := trace.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	if len(span.TraceID) != 32 || len(span.SpanID) != 16 {
+		t.Fatalf("invalid trace ID %q or span ID %q", span.TraceID, span.SpanID)
+	}
+	if span.StartTimeUnixNano != "1000000000000" || span.EndTimeUnixNano != "1002000000000" {
+		t.Fatalf("expected the span to cover a bar, got %s to %s", span.StartTimeUnixNano, span.EndTimeUnixNano)
+	}
+
+	var events []string
+	for _, event := range span.Events {
+		events = append(events, event.TimeUnixNano+" "+event.Attributes[0].Value["stringValue"].(string))
+	}
+	expected := "1000000000000 kick, 1000500000000 snare, 1001000000000 kick"
+	if strings.Join(events, ", ") != expected {
+		t.Fatalf("expected events %s, got %s", expected, strings.Join(events, ", "))
+	}
+}
+
+func TestExportDatadog(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportDatadog(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+
+	var payload struct {
+		Series []struct {
+			Metric string
+			Points [][2]float64
+			Tags   []string
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		t.Fatalf("output isn't valid JSON - %v", err)
+	}
+	if len(payload.Series) != len(pattern.Tracks)+1 {
+		t.Fatalf("expected %d series, got %d", len(pattern.Tracks)+1, len(payload.Series))
+	}
+	if tempo := payload.Series[0]; tempo.Metric != "drum.pattern.tempo" || tempo.Points[0][1] != 120 {
+		t.Fatalf("unexpected tempo series %+v", tempo)
+	}
+	kick := payload.Series[1]
+	if kick.Metric != "drum.track.density" || kick.Points[0][1] != 0.25 || strings.Join(kick.Tags, ",") != "pattern_version:0.808-alpha,track_name:kick,track_id:0" {
+		t.Fatalf("unexpected kick series %+v", kick)
+	}
+}
+
+func TestExportCloudWatch(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportCloudWatch(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+
+	var payload cloudWatchPayload
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		t.Fatalf("output isn't valid JSON - %v", err)
+	}
+	if payload.Namespace == "" || !strings.Contains(buf.String(), `"Namespace": "DrumPattern"`) {
+		t.Fatalf("Namespace not found:\n%s", buf.String())
+	}
+	if len(payload.MetricData) != len(pattern.Tracks)+1 {
+		t.Fatalf("expected %d data points, got %d", len(pattern.Tracks)+1, len(payload.MetricData))
+	}
+	if kick := payload.MetricData[1]; kick.MetricName != "TrackDensity" || kick.Dimensions[0].Value != "kick" || kick.Value != 0.25 {
+		t.Fatalf("unexpected kick datum %+v", kick)
+	}
+}
+
+func TestExportInfluxDB(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_4.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+	pattern.Tracks[0].Name = "kick=808, loud"
+
+	var buf bytes.Buffer
+	if err := pattern.ExportInfluxDB(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+
+	tag := `,[a-z_]+=([^,= \\]|\\.)+`
+	field := `[a-z_]+=([0-9.e+-]+i?)`
+	point := regexp.MustCompile(`^drum_(pattern|track)(` + tag + `)* ` + field + `(,` + field + `)*$`)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for _, line := range lines {
+		if !point.MatchString(line) {
+			t.Fatalf("invalid point %q", line)
+		}
+	}
+	if len(lines) != len(pattern.Tracks)+1 {
+		t.Fatalf("expected %d points, got %d", len(pattern.Tracks)+1, len(lines))
+	}
+	if lines[0] != "drum_pattern,version=0.909 tempo=240.0" {
+		t.Fatalf("unexpected pattern point %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], `drum_track,name=kick\=808\,\ loud,id=0 density=`) {
+		t.Fatalf("unexpected track point %q", lines[1])
+	}
+}
+
+func TestExportElasticsearch(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportElasticsearch(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines)%2 != 0 || len(lines) != 2*(len(pattern.Tracks)+1) {
+		t.Fatalf("expected %d lines, got %d", 2*(len(pattern.Tracks)+1), len(lines))
+	}
+
+	for i := 0; i < len(lines); i += 2 {
+		var action map[string]map[string]string
+		if err := json.Unmarshal([]byte(lines[i]), &action); err != nil || action["index"]["_index"] != "patterns" {
+			t.Fatalf("invalid action line %q - %v", lines[i], err)
+		}
+		var document map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[i+1]), &document); err != nil {
+			t.Fatalf("invalid source line %q - %v", lines[i+1], err)
+		}
+	}
+	if !strings.Contains(lines[3], `"steps":"x---x---x---x---"`) {
+		t.Fatalf("unexpected kick document %s", lines[3])
+	}
+}
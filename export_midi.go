@@ -0,0 +1,149 @@
+package drum
+
+import (
+	"fmt"
+	"io"
+)
+
+// Default resolutions of MIDI files created by DAWs
+const (
+	ardourPPQ = 480
+	bitwigPPQ = 480
+)
+
+// midiDefaultVelocity is used for steps without a velocity
+const midiDefaultVelocity = 100
+
+// ExportArdourMIDI writes the pattern as a Standard MIDI File matching the
+// conventions of Ardour: 480 PPQ, a separate tempo track and notes on channel
+// 10 lasting a full 16th note. Notes are taken from the General MIDI drum map
+// and clamped to 0-127, velocities from the Velocities field of the tracks,
+// defaulting to 100.
+func (pattern *Pattern) ExportArdourMIDI(w io.Writer) error {
+	tempoTrack, err := pattern.midiHeader()
+	if err != nil {
+		return err
+	}
+
+	var drums []midiEvent
+	for _, track := range pattern.Tracks {
+		drums = append(drums, midiDrumEvents(track, ardourPPQ, trackNote(track), midiDefaultVelocity, ardourPPQ/StepsPerBeat)...)
+	}
+
+	return writeSMF(w, 1, ardourPPQ, [][]midiEvent{tempoTrack, drums})
+}
+
+// ExportBitwig writes the pattern as a Standard MIDI File matching the MIDI
+// import of Bitwig Studio: a single track at 480 PPQ with notes on channel 10
+// lasting a 16th note minus one tick. Velocities are taken from the
+// Velocities field of the tracks, defaulting to 100.
+func (pattern *Pattern) ExportBitwig(w io.Writer) error {
+	events, err := pattern.midiHeader()
+	if err != nil {
+		return err
+	}
+	for _, track := range pattern.Tracks {
+		events = append(events, midiDrumEvents(track, bitwigPPQ, trackNote(track), midiDefaultVelocity, bitwigPPQ/StepsPerBeat-1)...)
+	}
+
+	return writeSMF(w, 0, bitwigPPQ, [][]midiEvent{events})
+}
+
+// MIDIOptions configures ExportMIDI
+type MIDIOptions struct {
+	// Format is the Standard MIDI File format, 0 for a single track or 1 for
+	// a tempo track followed by a track per drum track.
+	Format int
+	// PPQ is the number of ticks per beat, 480 if 0. It must be a multiple
+	// of the steps per beat.
+	PPQ int
+	// Velocity of the notes, 100 if 0. Velocities set on a track take
+	// precedence.
+	Velocity int
+	// NotesByID and NotesByName map tracks to MIDI notes, by ID first and
+	// then by name. Other tracks use their MIDINote or the General MIDI drum
+	// map.
+	NotesByID   map[int]int
+	NotesByName map[string]int
+}
+
+// ExportMIDI writes the pattern as a Standard MIDI File at the pattern tempo,
+// with a note on channel 10 for every active step quantized to 16th notes.
+func (pattern *Pattern) ExportMIDI(w io.Writer, opts MIDIOptions) error {
+	if opts.Format != 0 && opts.Format != 1 {
+		return fmt.Errorf("%w: unsupported MIDI file format %d", ErrInvalidFormat, opts.Format)
+	}
+	ppq := opts.PPQ
+	if ppq == 0 {
+		ppq = 480
+	}
+	if ppq < 0 || ppq%StepsPerBeat != 0 || ppq > 0x7FFF {
+		return fmt.Errorf("%w: invalid PPQ %d", ErrInvalidFormat, ppq)
+	}
+	defaultVelocity := opts.Velocity
+	if defaultVelocity == 0 {
+		defaultVelocity = midiDefaultVelocity
+	}
+
+	header, err := pattern.midiHeader()
+	if err != nil {
+		return err
+	}
+	tracks := [][]midiEvent{header}
+	for _, track := range pattern.Tracks {
+		note, ok := opts.NotesByID[track.ID]
+		if !ok {
+			note, ok = opts.NotesByName[track.Name]
+		}
+		if !ok {
+			note = trackNote(track)
+		}
+
+		var events []midiEvent
+		if opts.Format == 1 {
+			events = append(events, midiTextEvent(0, 0x03, track.Name))
+		}
+		events = append(events, midiDrumEvents(track, ppq, note, defaultVelocity, ppq/StepsPerBeat)...)
+
+		if opts.Format == 1 {
+			tracks = append(tracks, events)
+		} else {
+			tracks[0] = append(tracks[0], events...)
+		}
+	}
+
+	return writeSMF(w, opts.Format, ppq, tracks)
+}
+
+// midiHeader validates the tempo and returns the meta events at the start of
+// the MIDI files of the pattern: the version, a 4/4 time signature and the
+// tempo.
+func (pattern *Pattern) midiHeader() ([]midiEvent, error) {
+	if err := validateTempo(pattern.Tempo); err != nil {
+		return nil, err
+	}
+	// The tempo meta event stores the microseconds per beat in 24 bits
+	if usPerBeat := 60000000 / float64(pattern.Tempo); usPerBeat < 1 || usPerBeat > 0xFFFFFF {
+		return nil, fmt.Errorf("%w: %g BPM can't be stored in a MIDI file", ErrInvalidTempo, pattern.Tempo)
+	}
+
+	return []midiEvent{
+		midiTextEvent(0, 0x03, pattern.Version),
+		midiTimeSignatureEvent(),
+		midiTempoEvent(pattern.Tempo),
+	}, nil
+}
+
+// midiDrumEvents returns a note on channel 10 lasting length ticks for every
+// active step of the track. The note is clamped to 0-127 and the velocities
+// to 1-127, steps without a velocity use defaultVelocity.
+func midiDrumEvents(track *Track, ppq, note, defaultVelocity, length int) []midiEvent {
+	note = clamp(note, 0, 127)
+	return midiNoteEvents(track, ppq, midiDrumChannel, func(step int) midiNote {
+		velocity := stepVelocity(track, step)
+		if velocity == 0 {
+			velocity = defaultVelocity
+		}
+		return midiNote{note: note, velocity: clamp(velocity, 1, 127), length: length}
+	})
+}
@@ -0,0 +1,174 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"path"
+	"testing"
+)
+
+func TestExportArdourMIDI(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportArdourMIDI(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	data := buf.Bytes()
+
+	if string(data[:4]) != "MThd" {
+		t.Fatalf("expected MThd header, got %q", data[:4])
+	}
+	if ppq := binary.BigEndian.Uint16(data[12:]); ppq != 480 {
+		t.Fatalf("expected 480 PPQ, got %d", ppq)
+	}
+	// 120 BPM is 500000 microseconds per beat
+	if !bytes.Contains(data, []byte{0xFF, 0x51, 0x03, 0x07, 0xA1, 0x20}) {
+		t.Fatalf("tempo meta event for 120 BPM not found")
+	}
+	// Kick (36) on channel 10
+	if !bytes.Contains(data, []byte{0x99, 36, 100}) {
+		t.Fatalf("kick note on channel 10 not found")
+	}
+
+	pattern.Tracks[0].MIDINote = 200
+	buf.Reset()
+	if err := pattern.ExportArdourMIDI(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte{0x99, 127, 100}) {
+		t.Fatalf("note 200 wasn't clamped to 127")
+	}
+
+	pattern.Tempo = 0
+	if err := pattern.ExportArdourMIDI(&buf); !errors.Is(err, ErrInvalidTempo) {
+		t.Fatalf("expected ErrInvalidTempo, got %v", err)
+	}
+}
+
+func TestExportBitwig(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 1, Name: "kick", Steps: [16]bool{0: true, 4: true}, Velocities: [16]uint8{4: 64}},
+	}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportBitwig(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	data := buf.Bytes()
+
+	if format := binary.BigEndian.Uint16(data[8:]); format != 0 {
+		t.Fatalf("expected a type 0 MIDI file, got type %d", format)
+	}
+	if !bytes.Contains(data, []byte{0xFF, 0x51, 0x03, 0x07, 0xA1, 0x20}) {
+		t.Fatalf("tempo meta event for 120 BPM not found")
+	}
+	// Default velocity, followed by the note off 119 ticks later
+	if !bytes.Contains(data, []byte{0x99, 36, 100, 0x77, 0x89, 36, 0}) {
+		t.Fatalf("first kick note with default velocity not found")
+	}
+	if !bytes.Contains(data, []byte{0x99, 36, 64}) {
+		t.Fatalf("second kick note with velocity 64 not found")
+	}
+
+	pattern.Tracks[0].MIDINote = -5
+	buf.Reset()
+	if err := pattern.ExportBitwig(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte{0x99, 0, 100}) {
+		t.Fatalf("note -5 wasn't clamped to 0")
+	}
+
+	pattern.Tempo = 0
+	if err := pattern.ExportBitwig(&buf); !errors.Is(err, ErrInvalidTempo) {
+		t.Fatalf("expected ErrInvalidTempo, got %v", err)
+	}
+	if err := pattern.ExportMIDI(&buf, MIDIOptions{}); !errors.Is(err, ErrInvalidTempo) {
+		t.Fatalf("expected ErrInvalidTempo from ExportMIDI, got %v", err)
+	}
+
+	// Every tempo Validate accepts can be exported, as long as it fits the
+	// 24 bit tempo meta event
+	pattern.Tempo = 15
+	buf.Reset()
+	if err := pattern.ExportBitwig(&buf); err != nil {
+		t.Fatalf("something went wrong exporting at 15 BPM - %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte{0xFF, 0x51, 0x03, 0x3D, 0x09, 0x00}) {
+		t.Fatalf("tempo meta event for 15 BPM not found")
+	}
+	for _, tempo := range []float32{3, 1e8} {
+		pattern.Tempo = tempo
+		if err := pattern.ExportMIDI(&buf, MIDIOptions{}); !errors.Is(err, ErrInvalidTempo) {
+			t.Fatalf("%g BPM: expected ErrInvalidTempo, got %v", tempo, err)
+		}
+	}
+}
+
+func TestExportMIDI(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 1, Name: "kick", Steps: [16]bool{0: true}},
+		&Track{ID: 2, Name: "snare", Steps: [16]bool{4: true}},
+		&Track{ID: 3, Name: "zap", Steps: [16]bool{8: true}},
+	}}
+	opts := MIDIOptions{
+		Format:      1,
+		PPQ:         96,
+		Velocity:    90,
+		NotesByID:   map[int]int{1: 35},
+		NotesByName: map[string]int{"kick": 60, "zap": 70},
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportMIDI(&buf, opts); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	data := buf.Bytes()
+	if format, tracks, ppq := binary.BigEndian.Uint16(data[8:]), binary.BigEndian.Uint16(data[10:]), binary.BigEndian.Uint16(data[12:]); format != 1 || tracks != 4 || ppq != 96 {
+		t.Fatalf("expected a type 1 file with 4 tracks at 96 PPQ, got type %d with %d tracks at %d PPQ", format, tracks, ppq)
+	}
+	// Mapped by ID, lasting a 16th note of 24 ticks
+	if !bytes.Contains(data, []byte{0x00, 0x99, 35, 90, 0x18, 0x89, 35, 0}) {
+		t.Fatalf("kick note mapped by ID not found")
+	}
+	// General MIDI snare at the second beat
+	if !bytes.Contains(data, []byte{0x60, 0x99, 38, 90}) {
+		t.Fatalf("snare note not found")
+	}
+	if !bytes.Contains(data, []byte{0x99, 70, 90}) || !bytes.Contains(data, append([]byte{0xFF, 0x03, 3}, "zap"...)) {
+		t.Fatalf("zap track mapped by name not found")
+	}
+
+	buf.Reset()
+	if err := pattern.ExportMIDI(&buf, MIDIOptions{}); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	if format, tracks, ppq := binary.BigEndian.Uint16(buf.Bytes()[8:]), binary.BigEndian.Uint16(buf.Bytes()[10:]), binary.BigEndian.Uint16(buf.Bytes()[12:]); format != 0 || tracks != 1 || ppq != 480 {
+		t.Fatalf("expected a type 0 file with a single track at 480 PPQ, got type %d with %d tracks at %d PPQ", format, tracks, ppq)
+	}
+
+	if err := pattern.ExportMIDI(&buf, MIDIOptions{Format: 2}); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("expected ErrInvalidFormat for format 2, got %v", err)
+	}
+}
+
+func TestExportMIDILongPattern(t *testing.T) {
+	track := &Track{ID: 1, Name: "kick", Steps: [16]bool{0: true}}
+	track.SetLength(32)
+	track.SetStep(20, true)
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{track}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportMIDI(&buf, MIDIOptions{PPQ: 96}); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	// Step 20 starts at tick 480, 456 ticks after the note off of step 0
+	if !bytes.Contains(buf.Bytes(), []byte{0x18, 0x89, 36, 0, 0x83, 0x48, 0x99, 36, 100}) {
+		t.Fatalf("kick note on step 20 not found")
+	}
+}
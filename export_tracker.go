@@ -0,0 +1,203 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Impulse Tracker layout constants
+const (
+	itHeaderSize     = 0xC0
+	itInstrumentSize = 554
+	itSampleSize     = 80
+	itRowsPerStep    = 4
+	itMaxRows        = 200
+	itMaxChannels    = 64
+)
+
+// ExportImpulseTracker writes the pattern as an Impulse Tracker (.it) module.
+// Every track becomes a channel with its own instrument and every step spans
+// 4 rows of a single pattern, 64 rows for 16 steps. Impulse Tracker patterns
+// have at most 200 rows, so 64 step patterns are not supported. The
+// instruments have empty samples and send on MIDI channel 10, so they are
+// meant to be replaced in the tracker.
+func (pattern *Pattern) ExportImpulseTracker(w io.Writer) error {
+	if len(pattern.Tracks) > itMaxChannels {
+		return fmt.Errorf("Impulse Tracker supports at most %d channels, pattern has %d tracks", itMaxChannels, len(pattern.Tracks))
+	}
+	if rows := pattern.Length() * itRowsPerStep; rows > itMaxRows {
+		return fmt.Errorf("%w: Impulse Tracker supports at most %d rows, pattern has %d steps", ErrInvalidStepCount, itMaxRows, pattern.Length())
+	}
+
+	buf := new(bytes.Buffer)
+	count := len(pattern.Tracks)
+	orders := []byte{0, 255}
+	speed, tempo := itSpeedAndTempo(pattern.Tempo)
+
+	// Header
+	buf.WriteString("IMPM")
+	writeFixedString(buf, pattern.Version, 26)
+	buf.Write([]byte{StepsPerBeat * itRowsPerStep, byte(pattern.Length() * itRowsPerStep)})
+	binary.Write(buf, binary.LittleEndian, []uint16{
+		uint16(len(orders)), uint16(count), uint16(count), 1, // order, instrument, sample and pattern count
+		0x0214, 0x0214, // created with / compatible with version
+		0x0D, // stereo, use instruments, linear slides
+		0,    // special
+	})
+	buf.Write([]byte{128, 48, speed, tempo, 128, 0})
+	binary.Write(buf, binary.LittleEndian, []uint16{0})    // message length
+	binary.Write(buf, binary.LittleEndian, []uint32{0, 0}) // message offset, reserved
+	for i := 0; i < itMaxChannels; i++ {
+		if i < count {
+			buf.WriteByte(32)
+		} else {
+			buf.WriteByte(32 | 128)
+		}
+	}
+	buf.Write(bytes.Repeat([]byte{64}, itMaxChannels))
+	buf.Write(orders)
+
+	// Parapointers to instruments, samples and the pattern
+	offset := uint32(buf.Len() + 4*(2*count+1))
+	for i := 0; i < count; i++ {
+		binary.Write(buf, binary.LittleEndian, offset+uint32(i*itInstrumentSize))
+	}
+	offset += uint32(count * itInstrumentSize)
+	for i := 0; i < count; i++ {
+		binary.Write(buf, binary.LittleEndian, offset+uint32(i*itSampleSize))
+	}
+	offset += uint32(count * itSampleSize)
+	binary.Write(buf, binary.LittleEndian, offset)
+
+	for i, track := range pattern.Tracks {
+		writeITInstrument(buf, track, i+1)
+	}
+	for _, track := range pattern.Tracks {
+		writeITSample(buf, track)
+	}
+	writeITPattern(buf, pattern)
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// itSpeedAndTempo maps the pattern tempo to an IT speed and tempo. IT plays
+// 4 rows per beat at tempo = BPM and speed 6, but with 4 rows per step a beat
+// is 16 rows. The speed is lowered from the default until the matching IT
+// tempo fits in a byte.
+func itSpeedAndTempo(bpm float32) (byte, byte) {
+	rowsPerBeat := float64(StepsPerBeat * itRowsPerStep)
+	speed := 6
+	tempo := float64(bpm) * rowsPerBeat / 4
+	for speed > 1 && tempo > 255 {
+		speed--
+		tempo = float64(bpm) * rowsPerBeat / 4 * float64(speed) / 6
+	}
+
+	return byte(speed), byte(clamp(int(math.Round(tempo)), 32, 255))
+}
+
+func writeITInstrument(buf *bytes.Buffer, track *Track, sample int) {
+	start := buf.Len()
+	buf.WriteString("IMPI")
+	writeFixedString(buf, "", 13)  // DOS filename
+	buf.Write([]byte{0, 0, 0})     // NNA, duplicate check type and action
+	buf.Write([]byte{0, 0, 0, 60}) // fade out, pitch pan separation and center
+	buf.Write([]byte{128, 32 | 128, 0, 0})
+	buf.Write([]byte{0x14, 0x02, 1, 0}) // tracker version, sample count
+	writeFixedString(buf, track.Name, 26)
+	buf.Write([]byte{0, 0, 10, 0xFF}) // filter cutoff, resonance, MIDI channel, program
+	buf.Write([]byte{0xFF, 0xFF})     // MIDI bank
+	for note := 0; note < 120; note++ {
+		buf.Write([]byte{byte(note), byte(sample)})
+	}
+	// Volume, panning and pitch envelopes are left empty
+	buf.Write(make([]byte, itInstrumentSize-(buf.Len()-start)))
+}
+
+func writeITSample(buf *bytes.Buffer, track *Track) {
+	buf.WriteString("IMPS")
+	writeFixedString(buf, "", 13)
+	buf.Write([]byte{64, 0, 64})
+	writeFixedString(buf, track.Name, 26)
+	buf.Write([]byte{1, 32})
+	binary.Write(buf, binary.LittleEndian, []uint32{0, 0, 0, 8363, 0, 0, 0})
+	buf.Write([]byte{0, 0, 0, 0})
+}
+
+func writeITPattern(buf *bytes.Buffer, pattern *Pattern) {
+	rows := pattern.Length() * itRowsPerStep
+	data := new(bytes.Buffer)
+	for row := 0; row < rows; row++ {
+		if row%itRowsPerStep == 0 {
+			step := row / itRowsPerStep
+			for i, track := range pattern.Tracks {
+				if on, err := track.step(step); err != nil || !*on {
+					continue
+				}
+				// Channel with mask, mask for note, instrument and volume
+				data.Write([]byte{byte(i+1) | 0x80, 0x07, byte(trackNote(track)), byte(i + 1), 64})
+			}
+		}
+		data.WriteByte(0)
+	}
+
+	binary.Write(buf, binary.LittleEndian, []uint16{uint16(data.Len()), uint16(rows)})
+	buf.Write(make([]byte, 4))
+	data.WriteTo(buf)
+}
+
+// trackNote returns the MIDI note of the track, falling back on the General
+// MIDI drum map and middle C.
+func trackNote(track *Track) int {
+	if track.MIDINote != 0 {
+		return track.MIDINote
+	}
+	if note, ok := GMDrumNote(track.Name); ok {
+		return note
+	}
+
+	return 60
+}
+
+// writeFixedString writes s truncated or null-padded to length bytes, always
+// leaving room for a terminating null byte.
+func writeFixedString(buf *bytes.Buffer, s string, length int) {
+	if len(s) > length-1 {
+		s = s[:length-1]
+	}
+	buf.WriteString(s)
+	buf.Write(make([]byte, length-len(s)))
+}
+
+// ExportOpenDrumMachine writes the pattern as OpenDrumMachine XML. Every track
+// becomes an instrument element listing its active steps.
+func (pattern *Pattern) ExportOpenDrumMachine(w io.Writer) error {
+	buf := new(bytes.Buffer)
+	buf.WriteString(xml.Header)
+	fmt.Fprintf(buf, "<pattern version=\"%s\" tempo=\"%g\" steps=\"%d\">\n",
+		xmlEscape(pattern.Version), pattern.Tempo, pattern.Length())
+	for _, track := range pattern.Tracks {
+		fmt.Fprintf(buf, "  <instrument id=\"%d\" name=\"%s\">\n", track.ID, xmlEscape(track.Name))
+		for i, step := range track.AllSteps() {
+			if step {
+				fmt.Fprintf(buf, "    <step index=\"%d\" active=\"true\"/>\n", i)
+			}
+		}
+		buf.WriteString("  </instrument>\n")
+	}
+	buf.WriteString("</pattern>\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func xmlEscape(s string) string {
+	buf := new(bytes.Buffer)
+	xml.EscapeText(buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,70 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path"
+	"testing"
+)
+
+func TestExportImpulseTracker(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportImpulseTracker(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	data := buf.Bytes()
+
+	if string(data[:4]) != "IMPM" {
+		t.Fatalf("expected IMPM magic, got %q", data[:4])
+	}
+	if count := binary.LittleEndian.Uint16(data[0x22:]); int(count) != len(pattern.Tracks) {
+		t.Fatalf("expected %d instruments, got %d", len(pattern.Tracks), count)
+	}
+	offset := binary.LittleEndian.Uint32(data[itHeaderSize+2:])
+	if string(data[offset:offset+4]) != "IMPI" {
+		t.Fatalf("expected an instrument at offset %d", offset)
+	}
+}
+
+func TestExportOpenDrumMachine(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_2.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportOpenDrumMachine(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<pattern version="0.808-alpha" tempo="98.4" steps="16">
+  <instrument id="0" name="kick">
+    <step index="0" active="true"/>
+    <step index="8" active="true"/>
+  </instrument>
+  <instrument id="1" name="snare">
+    <step index="4" active="true"/>
+    <step index="12" active="true"/>
+  </instrument>
+  <instrument id="3" name="hh-open">
+    <step index="2" active="true"/>
+    <step index="6" active="true"/>
+    <step index="8" active="true"/>
+    <step index="10" active="true"/>
+    <step index="14" active="true"/>
+  </instrument>
+  <instrument id="5" name="cowbell">
+    <step index="8" active="true"/>
+  </instrument>
+</pattern>
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output.\nGot:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,275 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// webPattern is the representation of a pattern embedded in the generated
+// web pages and scripts.
+type webPattern struct {
+	Version string     `json:"version"`
+	Tempo   float32    `json:"tempo"`
+	Steps   int        `json:"steps"`
+	Tracks  []webTrack `json:"tracks"`
+}
+
+type webTrack struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Note  int    `json:"note"`
+	Steps []bool `json:"steps"`
+}
+
+// webJSON returns the pattern as JSON which is safe to embed in a script
+// element.
+func (pattern *Pattern) webJSON() (string, error) {
+	data := webPattern{Version: pattern.Version, Tempo: pattern.Tempo, Steps: pattern.Length(), Tracks: []webTrack{}}
+	for _, track := range pattern.Tracks {
+		data.Tracks = append(data.Tracks, webTrack{track.ID, track.Name, trackNote(track), track.AllSteps()})
+	}
+
+	// json.Marshal escapes <, > and &, so the output can't close the script
+	encoded, err := json.Marshal(data)
+	return string(encoded), err
+}
+
+const html5CanvasTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{title}}</title>
+<style>
+  body { background: #111; color: #eee; font-family: sans-serif; }
+</style>
+</head>
+<body>
+<h1>{{title}}</h1>
+<canvas id="sequencer" width="800" height="400"></canvas>
+<script>
+var pattern = {{pattern}};
+var canvas = document.getElementById("sequencer");
+var ctx = canvas.getContext("2d");
+var labelWidth = 160, cell = (canvas.width - labelWidth) / pattern.steps, row = 32;
+canvas.height = Math.max(row * pattern.tracks.length, row);
+var stepDuration = 60000 / (pattern.tempo * 4);
+var start = null;
+
+function draw(timestamp) {
+  if (start === null) start = timestamp;
+  var current = Math.floor((timestamp - start) / stepDuration) % pattern.steps;
+  ctx.clearRect(0, 0, canvas.width, canvas.height);
+  pattern.tracks.forEach(function (track, i) {
+    ctx.fillStyle = "#eee";
+    ctx.font = "16px sans-serif";
+    ctx.fillText(track.name, 4, i * row + row / 2 + 6);
+    track.steps.forEach(function (on, s) {
+      if (s === current) ctx.fillStyle = on ? "#ff0" : "#555";
+      else ctx.fillStyle = on ? "#e63" : (Math.floor(s / 4) % 2 ? "#333" : "#222");
+      ctx.fillRect(labelWidth + s * cell + 2, i * row + 2, cell - 4, row - 4);
+    });
+  });
+  window.requestAnimationFrame(draw);
+}
+window.requestAnimationFrame(draw);
+</script>
+</body>
+</html>
+`
+
+// ExportHTML5Canvas writes the pattern as a self-contained HTML page showing
+// an animated step sequencer on a canvas. The highlighted step advances at
+// the pattern tempo.
+func (pattern *Pattern) ExportHTML5Canvas(w io.Writer) error {
+	data, err := pattern.webJSON()
+	if err != nil {
+		return err
+	}
+
+	page := strings.NewReplacer(
+		"{{title}}", html.EscapeString("Drum pattern "+pattern.Version),
+		"{{pattern}}", data,
+	).Replace(html5CanvasTemplate)
+
+	_, err = io.WriteString(w, page)
+	return err
+}
+
+const d3Template = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{title}}</title>
+<script src="https://cdn.jsdelivr.net/npm/d3@7"></script>
+<style>
+  body { background: #111; color: #eee; font-family: sans-serif; }
+  text { fill: #eee; font-size: 14px; }
+</style>
+</head>
+<body>
+<h1>{{title}}</h1>
+<svg id="sequencer"></svg>
+<script>
+var pattern = {{pattern}};
+var tempo = {{tempo}};
+var labelWidth = 160, cell = 32;
+var svg = d3.select("#sequencer")
+  .attr("width", labelWidth + pattern.steps * cell)
+  .attr("height", Math.max(cell * pattern.tracks.length, cell));
+
+var rows = svg.selectAll("g")
+  .data(pattern.tracks)
+  .join("g")
+  .attr("transform", function (track, i) { return "translate(0," + i * cell + ")"; });
+rows.append("text")
+  .attr("x", 4)
+  .attr("y", cell / 2 + 5)
+  .text(function (track) { return "(" + track.id + ") " + track.name; });
+rows.selectAll("rect")
+  .data(function (track) { return track.steps; })
+  .join("rect")
+  .attr("x", function (on, s) { return labelWidth + s * cell + 2; })
+  .attr("y", 2)
+  .attr("width", cell - 4)
+  .attr("height", cell - 4)
+  .attr("fill", function (on) { return on ? "#e63" : "#36a"; });
+
+var playhead = svg.append("rect")
+  .attr("x", labelWidth)
+  .attr("width", cell)
+  .attr("height", Math.max(cell * pattern.tracks.length, cell))
+  .attr("fill", "#ff0")
+  .attr("opacity", 0.3);
+
+var step = 0;
+d3.interval(function () {
+  step = (step + 1) % pattern.steps;
+  playhead.attr("x", labelWidth + step * cell);
+}, 60000 / (tempo * 4));
+</script>
+</body>
+</html>
+`
+
+// ExportD3 writes the pattern as a self-contained HTML page rendering the
+// step grid with D3.js, loaded from a CDN. Active steps are drawn in a warm
+// color and inactive steps in a cool color, while a playhead sweeps through
+// the steps at the pattern tempo.
+func (pattern *Pattern) ExportD3(w io.Writer) error {
+	data, err := pattern.webJSON()
+	if err != nil {
+		return err
+	}
+
+	page := strings.NewReplacer(
+		"{{title}}", html.EscapeString("Drum pattern "+pattern.Version),
+		"{{pattern}}", data,
+		"{{tempo}}", fmt.Sprintf("%g", pattern.Tempo),
+	).Replace(d3Template)
+
+	_, err = io.WriteString(w, page)
+	return err
+}
+
+const webAudioTemplate = `<script>
+(function () {
+  var pattern = {{pattern}};
+  var audioContext = new (window.AudioContext || window.webkitAudioContext)();
+  var bpm = {{bpm}};
+  var stepInterval = 60 / (bpm * 4);
+  var nextStepTime = audioContext.currentTime + 0.1;
+  var step = 0;
+
+  function burst(note, time) {
+    var oscillator = audioContext.createOscillator();
+    var gain = audioContext.createGain();
+    oscillator.frequency.value = 440 * Math.pow(2, (note - 69) / 12);
+    gain.gain.setValueAtTime(0.5, time);
+    gain.gain.exponentialRampToValueAtTime(0.001, time + 0.1);
+    oscillator.connect(gain);
+    gain.connect(audioContext.destination);
+    oscillator.start(time);
+    oscillator.stop(time + 0.1);
+  }
+
+  function schedule() {
+    while (nextStepTime < audioContext.currentTime + 0.1) {
+      pattern.tracks.forEach(function (track) {
+        if (track.steps[step]) burst(track.note, nextStepTime);
+      });
+      nextStepTime += stepInterval;
+      step = (step + 1) % pattern.steps;
+    }
+  }
+  setInterval(schedule, 25);
+})();
+</script>
+`
+
+// ExportWebAudio writes the pattern as a script element playing it with the
+// Web Audio API. Every active step schedules a short oscillator burst at the
+// pitch of the track note.
+func (pattern *Pattern) ExportWebAudio(w io.Writer) error {
+	data, err := pattern.webJSON()
+	if err != nil {
+		return err
+	}
+
+	script := strings.NewReplacer(
+		"{{pattern}}", data,
+		"{{bpm}}", fmt.Sprintf("%g", pattern.Tempo),
+	).Replace(webAudioTemplate)
+
+	_, err = io.WriteString(w, script)
+	return err
+}
+
+// SVG layout of ExportSVGAnimation
+const (
+	svgLabelWidth = 120
+	svgCell       = 20
+)
+
+// ExportSVGAnimation writes the pattern as an SVG step sequencer grid. A CSS
+// animation sweeps a highlight bar across the steps, taking BarDuration for
+// every StepCount steps.
+func (pattern *Pattern) ExportSVGAnimation(w io.Writer) error {
+	steps := pattern.Length()
+	width := svgLabelWidth + steps*svgCell
+	height := len(pattern.Tracks) * svgCell
+	if height == 0 {
+		height = svgCell
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height)
+	buf.WriteString("<style>\n")
+	buf.WriteString("  text { font: 12px sans-serif; }\n")
+	buf.WriteString("  .on { fill: #333; }\n  .off { fill: #ddd; }\n")
+	fmt.Fprintf(buf, "  .playhead { fill: #f80; opacity: 0.5; animation: sweep %gs steps(%d) infinite; }\n",
+		pattern.BarDuration().Seconds()*float64(steps)/StepCount, steps)
+	fmt.Fprintf(buf, "  @keyframes sweep { from { transform: translateX(0); } to { transform: translateX(%dpx); } }\n", steps*svgCell)
+	buf.WriteString("</style>\n")
+
+	for row, track := range pattern.Tracks {
+		y := row * svgCell
+		fmt.Fprintf(buf, "<text x=\"4\" y=\"%d\">%s</text>\n", y+svgCell*3/4, html.EscapeString(fmt.Sprintf("(%d) %s", track.ID, track.Name)))
+		for i, step := range track.AllSteps() {
+			class := "off"
+			if step {
+				class = "on"
+			}
+			fmt.Fprintf(buf, "<rect class=\"%s\" x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\"/>\n",
+				class, svgLabelWidth+i*svgCell+1, y+1, svgCell-2, svgCell-2)
+		}
+	}
+	fmt.Fprintf(buf, "<rect class=\"playhead\" x=\"%d\" y=\"0\" width=\"%d\" height=\"%d\"/>\n", svgLabelWidth, svgCell, height)
+	buf.WriteString("</svg>\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
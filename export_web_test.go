@@ -0,0 +1,83 @@
+package drum
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestExportHTML5Canvas(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_2.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportHTML5Canvas(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	if !strings.Contains(buf.String(), "<canvas") {
+		t.Fatalf("no canvas element found")
+	}
+	if !strings.Contains(buf.String(), `"tempo":98.4`) {
+		t.Fatalf("tempo not found")
+	}
+}
+
+func TestExportWebAudio(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_2.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportWebAudio(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	if !strings.Contains(buf.String(), "AudioContext") {
+		t.Fatalf("no AudioContext found")
+	}
+	if !strings.Contains(buf.String(), "var bpm = 98.4;") {
+		t.Fatalf("tempo not found")
+	}
+}
+
+func TestExportSVGAnimation(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 1, Name: "kick & snare", Steps: [16]bool{0: true, 8: true}},
+	}}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportSVGAnimation(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	svg := buf.String()
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, "<style>") {
+		t.Fatalf("no svg with a style element found:\n%s", svg)
+	}
+	if !strings.Contains(svg, "animation: sweep 2s steps(16) infinite") {
+		t.Fatalf("expected an animation of 2s:\n%s", svg)
+	}
+	if !strings.Contains(svg, "kick &amp; snare") || strings.Count(svg, `class="on"`) != 2 {
+		t.Fatalf("unexpected track rendering:\n%s", svg)
+	}
+}
+
+func TestExportD3(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_2.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.ExportD3(&buf); err != nil {
+		t.Fatalf("something went wrong exporting %v", err)
+	}
+	if !strings.Contains(buf.String(), "d3.select(") {
+		t.Fatalf("no d3.select call found")
+	}
+	if !strings.Contains(buf.String(), "var tempo = 98.4;") {
+		t.Fatalf("tempo not found")
+	}
+}
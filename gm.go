@@ -0,0 +1,105 @@
+package drum
+
+import "strings"
+
+// gmDrumNotes maps common drum names to General MIDI percussion notes.
+// Names are stored normalized, see normalizeDrumName.
+var gmDrumNotes = map[string]int{
+	"acoustic bass drum": 35,
+	"subkick":            35,
+	"sub kick":           35,
+	"kick":               36,
+	"bass drum":          36,
+	"bd":                 36,
+	"side stick":         37,
+	"rim":                37,
+	"rimshot":            37,
+	"snare":              38,
+	"sd":                 38,
+	"clap":               39,
+	"hand clap":          39,
+	"electric snare":     40,
+	"low floor tom":      41,
+	"floor tom":          41,
+	"closed hat":         42,
+	"closed hihat":       42,
+	"hh close":           42,
+	"hh closed":          42,
+	"hihat":              42,
+	"hi hat":             42,
+	"hh":                 42,
+	"high floor tom":     43,
+	"pedal hat":          44,
+	"pedal hihat":        44,
+	"low tom":            45,
+	"open hat":           46,
+	"open hihat":         46,
+	"hh open":            46,
+	"mid tom":            47,
+	"low mid tom":        47,
+	"hi mid tom":         48,
+	"crash":              49,
+	"crash cymbal":       49,
+	"hi tom":             50,
+	"high tom":           50,
+	"ride":               51,
+	"ride cymbal":        51,
+	"china":              52,
+	"ride bell":          53,
+	"tambourine":         54,
+	"splash":             55,
+	"cowbell":            56,
+	"vibraslap":          58,
+	"hi bongo":           60,
+	"low bongo":          61,
+	"mute hi conga":      62,
+	"hi conga":           63,
+	"high conga":         63,
+	"low conga":          64,
+	"conga":              64,
+	"high timbale":       65,
+	"low timbale":        66,
+	"high agogo":         67,
+	"low agogo":          68,
+	"cabasa":             69,
+	"maracas":            70,
+	"shaker":             70,
+	"short whistle":      71,
+	"long whistle":       72,
+	"short guiro":        73,
+	"long guiro":         74,
+	"claves":             75,
+	"hi wood block":      76,
+	"low wood block":     77,
+	"mute cuica":         78,
+	"open cuica":         79,
+	"mute triangle":      80,
+	"triangle":           81,
+	"open triangle":      81,
+}
+
+// GMDrumNote returns the General MIDI percussion note for a drum name like
+// "kick", "snare" or "hh-open". Names are matched case-insensitively and
+// dashes and underscores are treated as spaces.
+func GMDrumNote(name string) (int, bool) {
+	note, ok := gmDrumNotes[normalizeDrumName(name)]
+	return note, ok
+}
+
+func normalizeDrumName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.NewReplacer("-", " ", "_", " ").Replace(name)
+
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// AutoAssignMIDINote sets the MIDI note of the track based on its name. It
+// returns false and leaves the note untouched if the name isn't recognized.
+func (track *Track) AutoAssignMIDINote() bool {
+	note, ok := GMDrumNote(track.Name)
+	if ok {
+		track.MIDINote = note
+	}
+
+	return ok
+}
@@ -0,0 +1,36 @@
+package drum
+
+import "testing"
+
+func TestGMDrumNote(t *testing.T) {
+	tData := []struct {
+		name  string
+		note  int
+		found bool
+	}{
+		{"kick", 36, true},
+		{"Kick", 36, true},
+		{"SubKick", 35, true},
+		{"hh-open", 46, true},
+		{"hh-close", 42, true},
+		{"Low Conga", 64, true},
+		{"low_tom", 45, true},
+		{"theremin", 0, false},
+	}
+
+	for _, exp := range tData {
+		note, found := GMDrumNote(exp.name)
+		if note != exp.note || found != exp.found {
+			t.Fatalf("GMDrumNote(%q): expected (%d, %v), got (%d, %v)", exp.name, exp.note, exp.found, note, found)
+		}
+	}
+
+	track := &Track{Name: "snare"}
+	if !track.AutoAssignMIDINote() || track.MIDINote != 38 {
+		t.Fatalf("expected note 38 to be assigned, got %d", track.MIDINote)
+	}
+	track = &Track{Name: "theremin", MIDINote: 60}
+	if track.AutoAssignMIDINote() || track.MIDINote != 60 {
+		t.Fatalf("expected the note to be left at 60, got %d", track.MIDINote)
+	}
+}
@@ -0,0 +1,210 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// PatternInfo is the version, tempo and tracks of a pattern read by
+// DecodeHeader, without the steps of the tracks. The steps are read on
+// demand by LoadTrack.
+type PatternInfo struct {
+	Version string
+	Tempo   float32
+	Tracks  []TrackInfo
+	// StepCount is the number of steps of the tracks
+	StepCount int
+
+	r        io.ReaderAt
+	extended bool
+	// tracks holds the tracks read by a registered codec
+	tracks []*Track
+}
+
+// TrackInfo is the ID and name of a track in a PatternInfo
+type TrackInfo struct {
+	ID   int
+	Name string
+
+	// steps is the offset of the steps in the file
+	steps int64
+}
+
+// DecodeHeader reads the version and tempo of the pattern in r, and the IDs
+// and names of its tracks, skipping their steps. r has to stay readable for
+// LoadTrack. The step count is detected like Decode does, but only from the
+// layout of the tracks as their steps aren't read.
+func DecodeHeader(r io.ReaderAt) (*PatternInfo, error) {
+	header := make([]byte, 50)
+	if err := readAt(r, header, 0); err != nil {
+		return nil, err
+	}
+	if string(header[:6]) != "SPLICE" {
+		return nil, fmt.Errorf("%w, expected SPLICE, got %s", ErrBadHeader, header[:6])
+	}
+
+	info := &PatternInfo{
+		Version: string(bytes.Trim(header[14:46], "\x00")),
+		Tempo:   math.Float32frombits(binary.LittleEndian.Uint32(header[46:])),
+		r:       r,
+	}
+	info.extended = isExtendedVersion(info.Version)
+	end := 14 + int64(binary.BigEndian.Uint64(header[6:]))
+	if codec := CodecFor(info.Version); !isBuiltinCodec(codec) {
+		return decodeHeaderWithCodec(info, codec, end)
+	}
+
+	stepSize := 1
+	if info.extended {
+		stepSize = 2
+	}
+
+	for _, steps := range SupportedStepCounts {
+		if tracks, err := indexTracks(r, end, int64(steps*stepSize), true); err == nil {
+			info.Tracks, info.StepCount = tracks, steps
+			return info, nil
+		}
+	}
+	if info.extended {
+		return nil, fmt.Errorf("%w: extended tracks don't fill the content", ErrInvalidFormat)
+	}
+
+	tracks, err := indexTracks(r, end, StepCount, false)
+	if err != nil {
+		return nil, err
+	}
+	info.Tracks, info.StepCount = tracks, StepCount
+
+	return info, nil
+}
+
+// decodeHeaderWithCodec reads the tracks of a pattern with a version of a
+// registered codec. The layout of the tracks is unknown, so the steps are
+// read as well.
+func decodeHeaderWithCodec(info *PatternInfo, codec Codec, end int64) (*PatternInfo, error) {
+	size := end - 50
+	if size < 0 {
+		size = 0
+	}
+	tracks, err := codec.ReadTracks(io.NewSectionReader(info.r, 50, size), size)
+	if err != nil {
+		return nil, err
+	}
+
+	info.tracks, info.StepCount = tracks, StepCount
+	for _, track := range tracks {
+		info.Tracks = append(info.Tracks, TrackInfo{ID: track.ID, Name: track.Name})
+		info.StepCount = track.Length()
+	}
+
+	return info, nil
+}
+
+// indexTracks reads the IDs and names of the tracks from offset 50 to end,
+// which have stepBytes bytes of steps. If exact, the tracks have to fill the
+// content and have a name, like parseTracks. Otherwise the last track can
+// continue after end, like readTracks.
+func indexTracks(r io.ReaderAt, end, stepBytes int64, exact bool) ([]TrackInfo, error) {
+	var tracks []TrackInfo
+	offset := int64(50)
+	for offset < end {
+		head := make([]byte, 5)
+		if err := readAt(r, head, offset); err != nil {
+			return nil, err
+		}
+		nameLength := int64(int8(head[4]))
+		next := offset + 5 + nameLength + stepBytes
+		switch {
+		case nameLength < 0:
+			return nil, fmt.Errorf("%w: negative track name length %d", ErrInvalidFormat, nameLength)
+		case exact && (nameLength == 0 || next > end):
+			return nil, ErrInvalidFormat
+		}
+
+		name := make([]byte, nameLength)
+		if err := readAt(r, name, offset+5); err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, TrackInfo{
+			ID:    int(int32(binary.LittleEndian.Uint32(head))),
+			Name:  string(name),
+			steps: offset + 5 + nameLength,
+		})
+		offset = next
+	}
+
+	// The steps of the last track have to be in the file
+	if len(tracks) > 0 && !exact {
+		if err := readAt(r, make([]byte, 1), offset-1); err != nil {
+			return nil, err
+		}
+	}
+
+	return tracks, nil
+}
+
+// LoadTrack reads the steps of track i and returns the track. Tracks of a
+// registered codec are read by DecodeHeader already.
+func (info *PatternInfo) LoadTrack(i int) (*Track, error) {
+	if i < 0 || i >= len(info.Tracks) {
+		return nil, fmt.Errorf("%w: index %d", ErrTrackNotFound, i)
+	}
+	if info.tracks != nil {
+		return info.tracks[i].Clone(), nil
+	}
+
+	stepSize := 1
+	if info.extended {
+		stepSize = 2
+	}
+	data := make([]byte, info.StepCount*stepSize)
+	if err := readAt(info.r, data, info.Tracks[i].steps); err != nil {
+		return nil, err
+	}
+
+	track := &Track{ID: info.Tracks[i].ID, Name: info.Tracks[i].Name}
+	track.SetLength(info.StepCount)
+	for i := 0; i < info.StepCount; i++ {
+		if !info.extended {
+			// Same as Decode, which reads the steps as int8
+			track.SetStep(i, int8(data[i]) > 0)
+			continue
+		}
+		if velocity := data[2*i]; velocity > 127 {
+			return nil, fmt.Errorf("%w: %d", ErrInvalidVelocity, velocity)
+		}
+		track.setExtendedStep(i, data[2*i], data[2*i+1])
+	}
+
+	return track, nil
+}
+
+// Load reads the steps of all tracks and returns the pattern
+func (info *PatternInfo) Load() (*Pattern, error) {
+	pattern := &Pattern{Version: info.Version, Tempo: info.Tempo}
+	for i := range info.Tracks {
+		track, err := info.LoadTrack(i)
+		if err != nil {
+			return nil, err
+		}
+		pattern.Tracks = append(pattern.Tracks, track)
+	}
+
+	return pattern, nil
+}
+
+// readAt fills buf from r at offset, a short read is io.ErrUnexpectedEOF
+func readAt(r io.ReaderAt, buf []byte, offset int64) error {
+	n, err := r.ReadAt(buf, offset)
+	if n == len(buf) {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
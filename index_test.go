@@ -0,0 +1,94 @@
+package drum
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"testing"
+)
+
+type countingReaderAt struct {
+	r io.ReaderAt
+	n int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.n += n
+	return n, err
+}
+
+func TestDecodeHeader(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		f, err := os.Open(path.Join("fixtures", name))
+		if err != nil {
+			t.Fatalf("something went wrong opening %s - %v", name, err)
+		}
+		defer f.Close()
+
+		info, err := DecodeHeader(f)
+		if err != nil {
+			t.Fatalf("something went wrong decoding the header of %s - %v", name, err)
+		}
+		if info.Version != pattern.Version || info.Tempo != pattern.Tempo || len(info.Tracks) != len(pattern.Tracks) {
+			t.Fatalf("%s: expected version %s, tempo %g and %d tracks, got %+v", name, pattern.Version, pattern.Tempo, len(pattern.Tracks), info)
+		}
+		for i, track := range pattern.Tracks {
+			if info.Tracks[i].ID != track.ID || info.Tracks[i].Name != track.Name {
+				t.Fatalf("%s: expected track %d to be (%d) %s, got %+v", name, i, track.ID, track.Name, info.Tracks[i])
+			}
+		}
+
+		loaded, err := info.Load()
+		if err != nil {
+			t.Fatalf("something went wrong loading %s - %v", name, err)
+		}
+		if loaded.String() != pattern.String() {
+			t.Fatalf("%s: loaded pattern differs, got:\n%s", name, loaded)
+		}
+	}
+}
+
+func TestDecodeHeaderLazy(t *testing.T) {
+	for _, version := range []string{"0.808-alpha", "0.909-ext"} {
+		pattern := testPattern()
+		pattern.Version = version
+		pattern.SetLength(64)
+		pattern.Tracks[1].SetStep(40, true)
+		data := pattern.Bytes()
+
+		r := &countingReaderAt{r: bytes.NewReader(data)}
+		info, err := DecodeHeader(r)
+		if err != nil {
+			t.Fatalf("something went wrong decoding the header - %v", err)
+		}
+		if info.StepCount != 64 || len(info.Tracks) != 4 {
+			t.Fatalf("expected 4 tracks of 64 steps, got %+v", info)
+		}
+		if r.n >= len(data)/2 {
+			t.Fatalf("expected the steps not to be read, read %d of %d bytes", r.n, len(data))
+		}
+
+		track, err := info.LoadTrack(1)
+		if err != nil {
+			t.Fatalf("something went wrong loading a track - %v", err)
+		}
+		if track.String() != pattern.Tracks[1].String() || !track.AllSteps()[40] {
+			t.Fatalf("expected %s, got %s", pattern.Tracks[1], track)
+		}
+		if _, err := info.LoadTrack(4); !errors.Is(err, ErrTrackNotFound) {
+			t.Fatalf("expected ErrTrackNotFound, got %v", err)
+		}
+	}
+
+	data := testPattern().Bytes()
+	if _, err := DecodeHeader(bytes.NewReader(data[:60])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	data[0] = 'X'
+	if _, err := DecodeHeader(bytes.NewReader(data)); !errors.Is(err, ErrBadHeader) {
+		t.Fatalf("expected ErrBadHeader, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package drum
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ExportToStruct copies the pattern into dest, which must be a pointer to a
+// struct. Exported fields of dest are matched case-insensitively by name to
+// the fields of Pattern, and a slice field matching Tracks is filled with
+// the tracks in the same way. Fields without a match are left untouched.
+func (pattern *Pattern) ExportToStruct(dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected a non-nil pointer to a struct, got %T", ErrInvalidDestination, dest)
+	}
+
+	return mapStruct(v.Elem(), reflect.ValueOf(pattern).Elem())
+}
+
+// mapStruct copies the fields of the struct src into the struct dst by
+// case-insensitive field name.
+func mapStruct(dst, src reflect.Value) error {
+	dstType := dst.Type()
+	for i := 0; i < dstType.NumField(); i++ {
+		field := dstType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		srcField := src.FieldByNameFunc(func(name string) bool {
+			return strings.EqualFold(name, field.Name)
+		})
+		if !srcField.IsValid() {
+			continue
+		}
+
+		if err := mapValue(dst.Field(i), srcField, field.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mapValue assigns src to dst, converting between numeric types, slices and
+// arrays, and structs where needed.
+func mapValue(dst, src reflect.Value, name string) error {
+	if src.Kind() == reflect.Ptr {
+		if src.IsNil() {
+			return nil
+		}
+		src = src.Elem()
+	}
+
+	switch {
+	case src.Type().AssignableTo(dst.Type()):
+		dst.Set(src)
+		return nil
+	case isNumeric(src.Kind()) && isNumeric(dst.Kind()):
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	case src.Kind() == reflect.String && dst.Kind() == reflect.String:
+		dst.SetString(src.String())
+		return nil
+	case dst.Kind() == reflect.Ptr:
+		elem := reflect.New(dst.Type().Elem())
+		if err := mapValue(elem.Elem(), src, name); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	case src.Kind() == reflect.Struct && dst.Kind() == reflect.Struct:
+		return mapStruct(dst, src)
+	case (src.Kind() == reflect.Slice || src.Kind() == reflect.Array) && dst.Kind() == reflect.Slice:
+		slice := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := mapValue(slice.Index(i), src.Index(i), fmt.Sprintf("%s[%d]", name, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+		return nil
+	case (src.Kind() == reflect.Slice || src.Kind() == reflect.Array) && dst.Kind() == reflect.Array && dst.Len() == src.Len():
+		for i := 0; i < src.Len(); i++ {
+			if err := mapValue(dst.Index(i), src.Index(i), fmt.Sprintf("%s[%d]", name, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%w: cannot map field %s of type %s to %s", ErrInvalidDestination, name, src.Type(), dst.Type())
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// trackFields maps the splice struct tag names to the fields of Track
+var trackFields = map[string]string{
+	"id":         "ID",
+	"name":       "Name",
+	"steps":      "Steps",
+	"midinote":   "MIDINote",
+	"velocities": "Velocities",
+}
+
+// ToStruct copies the track into dest, which must be a pointer to a struct.
+// Fields of dest are populated according to their splice struct tag, like
+// `splice:"name"`. Valid tags are id, name, steps, midinote and velocities.
+// Fields without a tag or tagged with "-" are skipped.
+func (track *Track) ToStruct(dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected a non-nil pointer to a struct, got %T", ErrInvalidDestination, dest)
+	}
+
+	dst := v.Elem()
+	src := reflect.ValueOf(track).Elem()
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Type().Field(i)
+		tag, ok := field.Tag.Lookup("splice")
+		if !ok || tag == "-" {
+			continue
+		}
+		if field.PkgPath != "" {
+			return fmt.Errorf("%w: field %s with splice tag %q is unexported", ErrInvalidDestination, field.Name, tag)
+		}
+
+		name, ok := trackFields[tag]
+		if !ok {
+			return fmt.Errorf("%w: unknown splice tag %q on field %s", ErrInvalidDestination, tag, field.Name)
+		}
+		if err := mapValue(dst.Field(i), src.FieldByName(name), field.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package drum
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExportToStruct(t *testing.T) {
+	type instrument struct {
+		Id    int64
+		NAME  string
+		Steps []bool
+		Color string
+	}
+	type song struct {
+		version string
+		Version string
+		BPM     int
+		Tempo   float64
+		Tracks  []instrument
+	}
+
+	pattern := testPattern()
+	var s song
+	if err := pattern.ExportToStruct(&s); err != nil {
+		t.Fatalf("something went wrong exporting - %v", err)
+	}
+	if s.Version != "0.808-alpha" || s.Tempo != 120 || s.BPM != 0 || s.version != "" {
+		t.Fatalf("unexpected pattern fields %+v", s)
+	}
+	if len(s.Tracks) != 4 {
+		t.Fatalf("expected 4 tracks, got %d", len(s.Tracks))
+	}
+	kick := s.Tracks[0]
+	if kick.Id != 0 || kick.NAME != "kick" || len(kick.Steps) != 16 || !kick.Steps[4] || kick.Color != "" {
+		t.Fatalf("unexpected track fields %+v", kick)
+	}
+
+	var mismatch struct {
+		Tempo string
+	}
+	if err := pattern.ExportToStruct(&mismatch); !errors.Is(err, ErrInvalidDestination) {
+		t.Fatalf("expected an error mapping a float to a string")
+	}
+	if err := pattern.ExportToStruct(s); !errors.Is(err, ErrInvalidDestination) {
+		t.Fatalf("expected an error for a non-pointer destination")
+	}
+}
+
+func TestTrackToStruct(t *testing.T) {
+	type instrument struct {
+		Label    string `splice:"name"`
+		Number   uint16 `splice:"id"`
+		Pattern  []bool `splice:"steps"`
+		Skipped  string `splice:"-"`
+		Untagged string
+	}
+
+	track := &Track{ID: 3, Name: "hh-open", Steps: [16]bool{2: true}}
+	dest := instrument{Skipped: "keep", Untagged: "keep"}
+	if err := track.ToStruct(&dest); err != nil {
+		t.Fatalf("something went wrong mapping - %v", err)
+	}
+	if dest.Label != "hh-open" || dest.Number != 3 || len(dest.Pattern) != 16 || !dest.Pattern[2] {
+		t.Fatalf("unexpected mapped fields %+v", dest)
+	}
+	if dest.Skipped != "keep" || dest.Untagged != "keep" {
+		t.Fatalf("fields without a tag were modified %+v", dest)
+	}
+
+	var mismatch struct {
+		Name int `splice:"name"`
+	}
+	err := track.ToStruct(&mismatch)
+	if !errors.Is(err, ErrInvalidDestination) || !strings.Contains(err.Error(), "Name") || !strings.Contains(err.Error(), "string") {
+		t.Fatalf("expected a descriptive type mismatch error, got %v", err)
+	}
+
+	var unknown struct {
+		Color string `splice:"color"`
+	}
+	if err := track.ToStruct(&unknown); !errors.Is(err, ErrInvalidDestination) {
+		t.Fatalf("expected an error for an unknown tag")
+	}
+}
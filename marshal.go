@@ -0,0 +1,174 @@
+package drum
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// patternText is the text form of a pattern used for JSON and YAML
+type patternText struct {
+	Version string   `json:"version" yaml:"version"`
+	Tempo   float32  `json:"tempo" yaml:"tempo"`
+	Tracks  []*Track `json:"tracks" yaml:"tracks"`
+}
+
+// trackText is the text form of a track, with all steps as a string like
+// "x---x---x---x---". The velocities and probabilities of the steps are only
+// included if any of them is set.
+type trackText struct {
+	ID            int       `json:"id" yaml:"id"`
+	Name          string    `json:"name" yaml:"name"`
+	Steps         string    `json:"steps" yaml:"steps"`
+	Velocities    []int     `json:"velocities,omitempty" yaml:"velocities,omitempty"`
+	Probabilities []float32 `json:"probabilities,omitempty" yaml:"probabilities,omitempty"`
+}
+
+// text returns the text form of the pattern, sharing its tracks
+func (pattern *Pattern) text() patternText {
+	text := patternText{Version: pattern.Version, Tempo: pattern.Tempo, Tracks: pattern.Tracks}
+	if text.Tracks == nil {
+		text.Tracks = []*Track{}
+	}
+
+	return text
+}
+
+// setText replaces the receiver with the decoded text form
+func (pattern *Pattern) setText(text patternText) {
+	*pattern = Pattern{Version: text.Version, Tempo: text.Tempo, Tracks: text.Tracks}
+}
+
+// MarshalJSON encodes the pattern as a JSON object with the version, tempo
+// and tracks. Only the fields stored in .splice files are included, like the
+// velocities and probabilities of extended patterns.
+func (pattern *Pattern) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pattern.text())
+}
+
+// UnmarshalJSON decodes a pattern encoded by MarshalJSON into the receiver
+func (pattern *Pattern) UnmarshalJSON(data []byte) error {
+	var text patternText
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	pattern.setText(text)
+
+	return nil
+}
+
+// MarshalYAML returns the value encoded by YAML libraries like
+// gopkg.in/yaml.v3, using the same layout as MarshalJSON.
+func (pattern *Pattern) MarshalYAML() (interface{}, error) {
+	return pattern.text(), nil
+}
+
+// UnmarshalYAML decodes a pattern encoded by MarshalYAML into the receiver.
+// The signature is supported by both gopkg.in/yaml.v2 and gopkg.in/yaml.v3.
+func (pattern *Pattern) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text patternText
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+	pattern.setText(text)
+
+	return nil
+}
+
+// text returns the text form of the track
+func (track *Track) text() trackText {
+	steps := make([]byte, 0, track.Length())
+	for _, step := range track.AllSteps() {
+		if step {
+			steps = append(steps, 'x')
+		} else {
+			steps = append(steps, '-')
+		}
+	}
+
+	text := trackText{ID: track.ID, Name: track.Name, Steps: string(steps)}
+	if track.Velocities != ([StepCount]uint8{}) {
+		for _, velocity := range track.Velocities {
+			text.Velocities = append(text.Velocities, int(velocity))
+		}
+	}
+	if track.ProbabilitySteps != ([StepCount]float32{}) {
+		text.Probabilities = append([]float32(nil), track.ProbabilitySteps[:]...)
+	}
+
+	return text
+}
+
+// setText parses the steps and replaces the receiver with the decoded
+// text form.
+func (track *Track) setText(text trackText) error {
+	steps, err := parseStepSlice(text.Steps)
+	if err != nil {
+		return err
+	}
+	if !supportedStepCount(len(steps)) {
+		return fmt.Errorf("%w: %q has %d steps, expected one of %v", ErrInvalidSteps, text.Steps, len(steps), SupportedStepCounts)
+	}
+
+	decoded := Track{ID: text.ID, Name: text.Name}
+	copy(decoded.Steps[:], steps)
+	decoded.SetLength(len(steps))
+	copy(decoded.ExtraSteps, steps[StepCount:])
+
+	if text.Velocities != nil {
+		if len(text.Velocities) != StepCount {
+			return fmt.Errorf("%w: %d velocities, expected %d", ErrInvalidFormat, len(text.Velocities), StepCount)
+		}
+		for i, velocity := range text.Velocities {
+			if velocity < 0 || velocity > 127 {
+				return fmt.Errorf("%w: %d", ErrInvalidVelocity, velocity)
+			}
+			decoded.Velocities[i] = uint8(velocity)
+		}
+	}
+	if text.Probabilities != nil {
+		if len(text.Probabilities) != StepCount {
+			return fmt.Errorf("%w: %d probabilities, expected %d", ErrInvalidFormat, len(text.Probabilities), StepCount)
+		}
+		copy(decoded.ProbabilitySteps[:], text.Probabilities)
+	}
+	if err := decoded.validateExtended(); err != nil {
+		return err
+	}
+
+	*track = decoded
+	return nil
+}
+
+// MarshalJSON encodes the track as a JSON object with the steps as a string
+// like "x---x---x---x---".
+func (track *Track) MarshalJSON() ([]byte, error) {
+	return json.Marshal(track.text())
+}
+
+// UnmarshalJSON decodes a track encoded by MarshalJSON into the receiver. The
+// steps are parsed like TrackBuilder.WithStepsFromString, but can have any of
+// the SupportedStepCounts.
+func (track *Track) UnmarshalJSON(data []byte) error {
+	var text trackText
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+
+	return track.setText(text)
+}
+
+// MarshalYAML returns the value encoded by YAML libraries, using the same
+// layout as MarshalJSON.
+func (track *Track) MarshalYAML() (interface{}, error) {
+	return track.text(), nil
+}
+
+// UnmarshalYAML decodes a track encoded by MarshalYAML into the receiver
+func (track *Track) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text trackText
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+
+	return track.setText(text)
+}
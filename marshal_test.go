@@ -0,0 +1,148 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		data, err := json.Marshal(pattern)
+		if err != nil {
+			t.Fatalf("something went wrong marshalling %s - %v", name, err)
+		}
+
+		var unmarshalled Pattern
+		if err := json.Unmarshal(data, &unmarshalled); err != nil {
+			t.Fatalf("something went wrong unmarshalling %s - %v", name, err)
+		}
+		if unmarshalled.String() != pattern.String() {
+			t.Fatalf("%s changed in the round trip, got:\n%s", name, &unmarshalled)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 0, Name: "kick", Steps: [16]bool{0: true, 4: true, 8: true, 12: true}},
+	}}
+
+	data, err := json.Marshal(pattern)
+	if err != nil {
+		t.Fatalf("something went wrong marshalling - %v", err)
+	}
+	expected := `{"version":"0.808-alpha","tempo":120,"tracks":[{"id":0,"name":"kick","steps":"x---x---x---x---"}]}`
+	if string(data) != expected {
+		t.Fatalf("expected %s, got %s", expected, data)
+	}
+
+	var track Track
+	if err := json.Unmarshal([]byte(`{"id":1,"name":"snare","steps":"----|x---|----|x---"}`), &track); err != nil {
+		t.Fatalf("something went wrong unmarshalling - %v", err)
+	}
+	if !track.Steps[4] || !track.Steps[12] || track.ActiveStepCount() != 2 {
+		t.Fatalf("unexpected steps %s", formatSteps(track.Steps[:]))
+	}
+	if err := json.Unmarshal([]byte(`{"id":1,"name":"snare","steps":"x---"}`), &track); !errors.Is(err, ErrInvalidSteps) {
+		t.Fatalf("expected ErrInvalidSteps, got %v", err)
+	}
+}
+
+// jsonUnmarshal returns an unmarshal function for UnmarshalYAML which
+// decodes the JSON marshalled value
+func jsonUnmarshal(value interface{}) func(interface{}) error {
+	return func(out interface{}) error {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, out)
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		value, err := pattern.MarshalYAML()
+		if err != nil {
+			t.Fatalf("something went wrong marshalling %s - %v", name, err)
+		}
+
+		var unmarshalled Pattern
+		if err := unmarshalled.UnmarshalYAML(jsonUnmarshal(value)); err != nil {
+			t.Fatalf("something went wrong unmarshalling %s - %v", name, err)
+		}
+		if unmarshalled.String() != pattern.String() {
+			t.Fatalf("%s changed in the round trip, got:\n%s", name, &unmarshalled)
+		}
+	}
+
+	var track Track
+	err := track.UnmarshalYAML(jsonUnmarshal(map[string]interface{}{"id": 1, "name": "kick", "steps": strings.Repeat("x", 17)}))
+	if !errors.Is(err, ErrInvalidSteps) {
+		t.Fatalf("expected ErrInvalidSteps, got %v", err)
+	}
+}
+
+func TestMarshalJSONLongPattern(t *testing.T) {
+	pattern := testPattern()
+	pattern.SetLength(32)
+	pattern.Tracks[1].SetStep(20, true)
+
+	data, err := json.Marshal(pattern)
+	if err != nil {
+		t.Fatalf("something went wrong marshalling - %v", err)
+	}
+	var unmarshalled Pattern
+	if err := json.Unmarshal(data, &unmarshalled); err != nil {
+		t.Fatalf("something went wrong unmarshalling - %v", err)
+	}
+	if unmarshalled.Length() != 32 || unmarshalled.String() != pattern.String() {
+		t.Fatalf("expected:\n%s\ngot:\n%s", pattern, &unmarshalled)
+	}
+}
+
+func TestMarshalJSONExtended(t *testing.T) {
+	pattern := testPattern()
+	pattern.Version = "0.808-ext"
+	pattern.Tracks[0].SetStepValue(4, Step{On: true, Velocity: 64, Probability: 0.5})
+	pattern.Tracks[1].SetStepValue(12, Step{On: true, Velocity: 127, Probability: 1})
+
+	data, err := json.Marshal(pattern)
+	if err != nil {
+		t.Fatalf("something went wrong marshalling - %v", err)
+	}
+	var unmarshalled Pattern
+	if err := json.Unmarshal(data, &unmarshalled); err != nil {
+		t.Fatalf("something went wrong unmarshalling - %v", err)
+	}
+	for i, track := range pattern.Tracks {
+		got := unmarshalled.Tracks[i]
+		if got.Velocities != track.Velocities || got.ProbabilitySteps != track.ProbabilitySteps {
+			t.Fatalf("velocities and probabilities of track %d changed in the round trip, got %v %v", track.ID, got.Velocities, got.ProbabilitySteps)
+		}
+	}
+	if !bytes.Equal(unmarshalled.Bytes(), pattern.Bytes()) {
+		t.Fatalf("encoding changed in the round trip")
+	}
+
+	value, err := pattern.MarshalYAML()
+	if err != nil {
+		t.Fatalf("something went wrong marshalling - %v", err)
+	}
+	unmarshalled = Pattern{}
+	if err := unmarshalled.UnmarshalYAML(jsonUnmarshal(value)); err != nil {
+		t.Fatalf("something went wrong unmarshalling - %v", err)
+	}
+	if unmarshalled.Tracks[0].Velocities[4] != 64 || unmarshalled.Tracks[0].ProbabilitySteps[4] != 0.5 {
+		t.Fatalf("expected velocity 64 and probability 0.5, got %s", unmarshalled.Tracks[0])
+	}
+
+	var track Track
+	err = json.Unmarshal([]byte(`{"id":1,"name":"kick","steps":"x---x---x---x---","velocities":[200,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0]}`), &track)
+	if !errors.Is(err, ErrInvalidVelocity) {
+		t.Fatalf("expected ErrInvalidVelocity, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package drum
+
+import "fmt"
+
+// TempoStrategy selects the tempo of merged patterns
+type TempoStrategy int
+
+// Tempo strategies of MergeOptions
+const (
+	// KeepFirstTempo uses the tempo of the first pattern
+	KeepFirstTempo TempoStrategy = iota
+	// ChooseTempo uses MergeOptions.ChosenTempo
+	ChooseTempo
+	// RequireSameTempo fails with ErrTempoMismatch if the tempos differ
+	RequireSameTempo
+)
+
+// NameStrategy selects what happens to merged tracks with a name which is
+// already used
+type NameStrategy int
+
+// Name strategies of MergeOptions
+const (
+	// KeepNames keeps the tracks as they are
+	KeepNames NameStrategy = iota
+	// RenameDuplicates appends a number to the name, like "kick (2)"
+	RenameDuplicates
+	// SkipDuplicates leaves the track out
+	SkipDuplicates
+)
+
+// MergeOptions configures MergeWithOptions
+type MergeOptions struct {
+	Tempo TempoStrategy
+	// ChosenTempo is the tempo of the result for ChooseTempo
+	ChosenTempo float32
+	Names       NameStrategy
+}
+
+// Merge combines copies of the tracks of the patterns into a new pattern
+// with the version and tempo of the first one. Tracks whose ID is already
+// used get the lowest free ID.
+func Merge(patterns ...*Pattern) (*Pattern, error) {
+	return MergeWithOptions(MergeOptions{}, patterns...)
+}
+
+// MergeWithOptions combines copies of the tracks of the patterns like Merge,
+// handling tempos and duplicate track names as configured by opts. The
+// result is validated.
+func MergeWithOptions(opts MergeOptions, patterns ...*Pattern) (*Pattern, error) {
+	if len(patterns) == 0 {
+		return nil, ErrNilPattern
+	}
+	for _, pattern := range patterns {
+		if pattern == nil {
+			return nil, ErrNilPattern
+		}
+	}
+
+	merged := &Pattern{Version: patterns[0].Version, Tempo: patterns[0].Tempo}
+	switch opts.Tempo {
+	case ChooseTempo:
+		merged.Tempo = opts.ChosenTempo
+	case RequireSameTempo:
+		for _, pattern := range patterns[1:] {
+			if pattern.Tempo != merged.Tempo {
+				return nil, fmt.Errorf("%w: %g and %g", ErrTempoMismatch, merged.Tempo, pattern.Tempo)
+			}
+		}
+	}
+
+	ids := make(map[int]bool)
+	names := make(map[string]bool)
+	for _, pattern := range patterns {
+		for _, track := range pattern.Tracks {
+			track = track.Clone()
+			if names[track.Name] {
+				switch opts.Names {
+				case SkipDuplicates:
+					continue
+				case RenameDuplicates:
+					track.Name = uniqueName(track.Name, names)
+				}
+			}
+			if ids[track.ID] {
+				track.ID = freeID(ids)
+			}
+
+			ids[track.ID] = true
+			names[track.Name] = true
+			merged.Tracks = append(merged.Tracks, track)
+		}
+	}
+
+	if err := merged.Validate(); err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+// Overlay layers the tracks of other on the pattern. Steps of tracks with a
+// name which is already used are added to the first track with that name,
+// other tracks are appended like Merge does. Version and tempo are left
+// untouched.
+func (pattern *Pattern) Overlay(other *Pattern) error {
+	if other == nil {
+		return ErrNilPattern
+	}
+
+	ids := make(map[int]bool)
+	for _, track := range pattern.Tracks {
+		ids[track.ID] = true
+	}
+	for _, track := range other.Tracks {
+		if layer := pattern.findTrackByName(track.Name); layer != nil {
+			if layer.Length() != track.Length() {
+				return fmt.Errorf("%w: track %q has %d steps, expected %d",
+					ErrStepCountMismatch, track.Name, track.Length(), layer.Length())
+			}
+			for i, step := range track.AllSteps() {
+				if step {
+					layer.SetStep(i, true)
+				}
+			}
+			continue
+		}
+
+		track = track.Clone()
+		if ids[track.ID] {
+			track.ID = freeID(ids)
+		}
+		ids[track.ID] = true
+		pattern.Tracks = append(pattern.Tracks, track)
+	}
+
+	return nil
+}
+
+// findTrackByName returns the first track with the given name or nil
+func (pattern *Pattern) findTrackByName(name string) *Track {
+	for _, track := range pattern.Tracks {
+		if track.Name == name {
+			return track
+		}
+	}
+
+	return nil
+}
+
+// freeID returns the lowest ID which is not in ids
+func freeID(ids map[int]bool) int {
+	id := 0
+	for ids[id] {
+		id++
+	}
+
+	return id
+}
+
+// uniqueName returns name with the lowest number appended which makes it
+// unused, like "kick (2)".
+func uniqueName(name string, names map[string]bool) string {
+	for i := 2; ; i++ {
+		if unique := fmt.Sprintf("%s (%d)", name, i); !names[unique] {
+			return unique
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package drum
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	other := &Pattern{Version: "0.909", Tempo: 98, Tracks: []*Track{
+		&Track{ID: 1, Name: "kick", Steps: [16]bool{2: true}},
+		&Track{ID: 7, Name: "rim"},
+	}}
+
+	merged, err := Merge(testPattern(), other)
+	if err != nil {
+		t.Fatalf("something went wrong merging - %v", err)
+	}
+	expected := testPattern().String() + "(2) kick\t|--x-|----|----|----|\n(7) rim\t|----|----|----|----|\n"
+	if merged.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, merged)
+	}
+	if other.Tracks[0].ID != 1 {
+		t.Fatalf("merging changed the merged pattern")
+	}
+
+	merged, err = MergeWithOptions(MergeOptions{Tempo: ChooseTempo, ChosenTempo: 100, Names: RenameDuplicates}, testPattern(), other)
+	if err != nil {
+		t.Fatalf("something went wrong merging - %v", err)
+	}
+	if merged.Tempo != 100 || merged.FindTrackByID(2).Name != "kick (2)" {
+		t.Fatalf("unexpected merged pattern:\n%s", merged)
+	}
+
+	merged, err = MergeWithOptions(MergeOptions{Names: SkipDuplicates}, testPattern(), other)
+	if err != nil {
+		t.Fatalf("something went wrong merging - %v", err)
+	}
+	if len(merged.Tracks) != 5 || merged.FindTrackByID(2) != nil {
+		t.Fatalf("expected the second kick to be skipped:\n%s", merged)
+	}
+
+	if _, err := MergeWithOptions(MergeOptions{Tempo: RequireSameTempo}, testPattern(), other); !errors.Is(err, ErrTempoMismatch) {
+		t.Fatalf("expected ErrTempoMismatch, got %v", err)
+	}
+	if _, err := MergeWithOptions(MergeOptions{Tempo: ChooseTempo}, testPattern()); !errors.Is(err, ErrInvalidTempo) {
+		t.Fatalf("expected ErrInvalidTempo, got %v", err)
+	}
+	if _, err := Merge(); err != ErrNilPattern {
+		t.Fatalf("expected ErrNilPattern, got %v", err)
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	pattern := testPattern()
+	other := &Pattern{Version: "0.909", Tempo: 98, Tracks: []*Track{
+		&Track{ID: 1, Name: "kick", Steps: [16]bool{2: true}},
+		&Track{ID: 5, Name: "rim", Steps: [16]bool{15: true}},
+	}}
+
+	if err := pattern.Overlay(other); err != nil {
+		t.Fatalf("something went wrong overlaying - %v", err)
+	}
+	if steps := formatSteps(pattern.Tracks[0].Steps[:]); steps != "x-x-x---x---x---" {
+		t.Fatalf("expected the kicks to be layered, got %s", steps)
+	}
+	if rim := pattern.Tracks[4]; rim.Name != "rim" || rim.ID != 2 {
+		t.Fatalf("expected rim to be appended with ID 2, got %v", rim)
+	}
+	if pattern.Tempo != 120 || pattern.Version != "0.808-alpha" {
+		t.Fatalf("overlaying changed version or tempo:\n%s", pattern)
+	}
+	if err := pattern.Validate(); err != nil {
+		t.Fatalf("overlaid pattern is invalid - %v", err)
+	}
+}
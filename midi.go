@@ -0,0 +1,118 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sort"
+)
+
+// midiDrumChannel is MIDI channel 10, the General MIDI percussion channel
+const midiDrumChannel = 9
+
+// midiEvent is a single event in a Standard MIDI File track
+type midiEvent struct {
+	tick int
+	data []byte
+}
+
+// midiNote describes how a step is turned into a note
+type midiNote struct {
+	note     int
+	velocity int
+	length   int
+}
+
+// writeSMF writes a Standard MIDI File with the given format, resolution and
+// tracks. Events are sorted by tick, an end of track event is appended to
+// every track.
+func writeSMF(w io.Writer, format int, ppq int, tracks [][]midiEvent) error {
+	buf := new(bytes.Buffer)
+	buf.WriteString("MThd")
+	binary.Write(buf, binary.BigEndian, []uint16{0, 6, uint16(format), uint16(len(tracks)), uint16(ppq)})
+
+	for _, events := range tracks {
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].tick < events[j].tick
+		})
+
+		data := new(bytes.Buffer)
+		last := 0
+		for _, event := range events {
+			writeVarInt(data, event.tick-last)
+			data.Write(event.data)
+			last = event.tick
+		}
+		writeVarInt(data, 0)
+		data.Write([]byte{0xFF, 0x2F, 0x00})
+
+		buf.WriteString("MTrk")
+		binary.Write(buf, binary.BigEndian, uint32(data.Len()))
+		data.WriteTo(buf)
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// writeVarInt writes v as a MIDI variable-length quantity
+func writeVarInt(buf *bytes.Buffer, v int) {
+	out := []byte{byte(v & 0x7F)}
+	for v >>= 7; v > 0; v >>= 7 {
+		out = append([]byte{byte(v&0x7F) | 0x80}, out...)
+	}
+	buf.Write(out)
+}
+
+// midiTempoEvent returns a set tempo meta event for the given BPM
+func midiTempoEvent(bpm float32) midiEvent {
+	usPerBeat := uint32(60000000 / float64(bpm))
+	return midiEvent{0, []byte{0xFF, 0x51, 0x03, byte(usPerBeat >> 16), byte(usPerBeat >> 8), byte(usPerBeat)}}
+}
+
+// midiTimeSignatureEvent returns a 4/4 time signature meta event
+func midiTimeSignatureEvent() midiEvent {
+	return midiEvent{0, []byte{0xFF, 0x58, 0x04, 4, 2, 24, 8}}
+}
+
+// midiTextEvent returns a meta event of the given type holding text
+func midiTextEvent(tick int, kind byte, text string) midiEvent {
+	data := new(bytes.Buffer)
+	data.Write([]byte{0xFF, kind})
+	writeVarInt(data, len(text))
+	data.WriteString(text)
+
+	return midiEvent{tick, data.Bytes()}
+}
+
+// midiNoteEvents returns note on and off events for every active step of the
+// track. ppq is the number of ticks per beat and note is called for every
+// active step to determine the note to play.
+func midiNoteEvents(track *Track, ppq int, channel int, note func(step int) midiNote) []midiEvent {
+	ticksPerStep := ppq / StepsPerBeat
+
+	var events []midiEvent
+	for i, step := range track.AllSteps() {
+		if !step {
+			continue
+		}
+		n := note(i)
+		start := i * ticksPerStep
+		events = append(events,
+			midiEvent{start, []byte{0x90 | byte(channel), byte(n.note), byte(n.velocity)}},
+			midiEvent{start + n.length, []byte{0x80 | byte(channel), byte(n.note), 0}},
+		)
+	}
+
+	return events
+}
+
+// stepVelocity returns the velocity set on step i of the track, 0 if it isn't
+// set or the step is one of the extra steps, which have no velocity.
+func stepVelocity(track *Track, i int) int {
+	if i >= StepCount {
+		return 0
+	}
+
+	return int(track.Velocities[i])
+}
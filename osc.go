@@ -0,0 +1,266 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+)
+
+// OSC addresses handled by OSCClock and sent by OSCSender. /drum/tempo has a
+// float or int argument, /drum/position an int argument and /drum/trigger
+// the step, track ID and track name.
+const (
+	OSCStart    = "/drum/start"
+	OSCContinue = "/drum/continue"
+	OSCStop     = "/drum/stop"
+	OSCStep     = "/drum/step"
+	OSCPosition = "/drum/position"
+	OSCTempo    = "/drum/tempo"
+	OSCTrigger  = "/drum/trigger"
+)
+
+// oscPacketSize is the largest OSC packet which is read
+const oscPacketSize = 1 << 16
+
+// OSCMessage is an Open Sound Control message. The arguments can be int32,
+// float32 and string values.
+type OSCMessage struct {
+	Address string
+	Args    []interface{}
+}
+
+// MarshalBinary encodes the message. It implements encoding.BinaryMarshaler.
+func (msg OSCMessage) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	writeOSCString(buf, msg.Address)
+
+	tags := ","
+	args := new(bytes.Buffer)
+	for _, arg := range msg.Args {
+		switch v := arg.(type) {
+		case int32:
+			tags += "i"
+			binary.Write(args, binary.BigEndian, v)
+		case int:
+			tags += "i"
+			binary.Write(args, binary.BigEndian, int32(v))
+		case float32:
+			tags += "f"
+			binary.Write(args, binary.BigEndian, v)
+		case string:
+			tags += "s"
+			writeOSCString(args, v)
+		default:
+			return nil, fmt.Errorf("%w: unsupported argument %v", ErrInvalidOSC, arg)
+		}
+	}
+	writeOSCString(buf, tags)
+	args.WriteTo(buf)
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a message with int32, float32 and string
+// arguments. It implements encoding.BinaryUnmarshaler.
+func (msg *OSCMessage) UnmarshalBinary(data []byte) error {
+	address, data, err := readOSCString(data)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(address, "/") {
+		return fmt.Errorf("%w: address %q", ErrInvalidOSC, address)
+	}
+	tags, data, err := readOSCString(data)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(tags, ",") {
+		return fmt.Errorf("%w: type tags %q", ErrInvalidOSC, tags)
+	}
+
+	var args []interface{}
+	for _, tag := range tags[1:] {
+		switch tag {
+		case 'i', 'f':
+			if len(data) < 4 {
+				return fmt.Errorf("%w: missing argument", ErrInvalidOSC)
+			}
+			bits := binary.BigEndian.Uint32(data)
+			if tag == 'i' {
+				args = append(args, int32(bits))
+			} else {
+				args = append(args, math.Float32frombits(bits))
+			}
+			data = data[4:]
+		case 's':
+			var s string
+			if s, data, err = readOSCString(data); err != nil {
+				return err
+			}
+			args = append(args, s)
+		default:
+			return fmt.Errorf("%w: unsupported type tag %q", ErrInvalidOSC, tag)
+		}
+	}
+
+	msg.Address, msg.Args = address, args
+	return nil
+}
+
+// writeOSCString writes s null terminated and padded to 4 bytes
+func writeOSCString(buf *bytes.Buffer, s string) {
+	buf.WriteString(s)
+	buf.Write(make([]byte, 4-len(s)%4))
+}
+
+// readOSCString reads a padded string and returns the data after it
+func readOSCString(data []byte) (string, []byte, error) {
+	end := bytes.IndexByte(data, 0)
+	if end < 0 {
+		return "", nil, fmt.Errorf("%w: unterminated string", ErrInvalidOSC)
+	}
+	next := (end/4 + 1) * 4
+	if next > len(data) {
+		return "", nil, fmt.Errorf("%w: unpadded string", ErrInvalidOSC)
+	}
+
+	return string(data[:end]), data[next:], nil
+}
+
+// OSCClock is a ClockSource receiving OSC messages on a UDP port. Packets
+// which aren't messages with a known address, like bundles, are ignored.
+type OSCClock struct {
+	conn   net.PacketConn
+	events chan ClockEvent
+}
+
+// ListenOSC creates a clock listening on the UDP address addr, like
+// ":9000".
+func ListenOSC(addr string) (*OSCClock, error) {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	clock := &OSCClock{conn: conn, events: make(chan ClockEvent, 16)}
+	go clock.read()
+
+	return clock, nil
+}
+
+// Addr returns the address the clock listens on
+func (clock *OSCClock) Addr() net.Addr {
+	return clock.conn.LocalAddr()
+}
+
+// Events implements ClockSource. The channel is closed when the clock is
+// closed.
+func (clock *OSCClock) Events() <-chan ClockEvent {
+	return clock.events
+}
+
+// Close stops listening
+func (clock *OSCClock) Close() error {
+	return clock.conn.Close()
+}
+
+// read receives packets until the connection is closed
+func (clock *OSCClock) read() {
+	defer close(clock.events)
+
+	buf := make([]byte, oscPacketSize)
+	for {
+		n, _, err := clock.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		var msg OSCMessage
+		if msg.UnmarshalBinary(buf[:n]) != nil {
+			continue
+		}
+		if event, ok := oscClockEvent(msg); ok {
+			clock.events <- event
+		}
+	}
+}
+
+// oscClockEvent returns the clock event of a message
+func oscClockEvent(msg OSCMessage) (ClockEvent, bool) {
+	switch msg.Address {
+	case OSCStart:
+		return ClockEvent{Type: ClockStart}, true
+	case OSCContinue:
+		return ClockEvent{Type: ClockContinue}, true
+	case OSCStop:
+		return ClockEvent{Type: ClockStop}, true
+	case OSCStep:
+		return ClockEvent{Type: ClockStep}, true
+	}
+	if len(msg.Args) != 1 {
+		return ClockEvent{}, false
+	}
+
+	switch v := msg.Args[0].(type) {
+	case int32:
+		switch msg.Address {
+		case OSCPosition:
+			return ClockEvent{Type: ClockPosition, Step: int(v)}, true
+		case OSCTempo:
+			return ClockEvent{Type: ClockTempo, Tempo: float32(v)}, true
+		}
+	case float32:
+		if msg.Address == OSCTempo {
+			return ClockEvent{Type: ClockTempo, Tempo: v}, true
+		}
+	}
+
+	return ClockEvent{}, false
+}
+
+// OSCSender sends OSC messages to a UDP address. Its Step and Trigger methods
+// can be used as the callbacks of a Player, so other players can follow it
+// with an OSCClock.
+type OSCSender struct {
+	conn net.Conn
+}
+
+// DialOSC creates a sender sending to the UDP address addr
+func DialOSC(addr string) (*OSCSender, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OSCSender{conn: conn}, nil
+}
+
+// Send sends a message
+func (sender *OSCSender) Send(msg OSCMessage) error {
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	_, err = sender.conn.Write(data)
+	return err
+}
+
+// Step sends /drum/step, errors are ignored. Use it as Player.OnStep.
+func (sender *OSCSender) Step(step int) {
+	sender.Send(OSCMessage{Address: OSCStep})
+}
+
+// Trigger sends /drum/trigger with the step, track ID and track name, errors
+// are ignored. Use it as Player.OnTrigger.
+func (sender *OSCSender) Trigger(step int, track *Track) {
+	sender.Send(OSCMessage{Address: OSCTrigger, Args: []interface{}{step, track.ID, track.Name}})
+}
+
+// Close closes the connection
+func (sender *OSCSender) Close() error {
+	return sender.conn.Close()
+}
@@ -0,0 +1,96 @@
+package drum
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOSCMessage(t *testing.T) {
+	msg := OSCMessage{Address: OSCTempo, Args: []interface{}{float32(120)}}
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		t.Fatalf("something went wrong encoding - %v", err)
+	}
+	expected := []byte("/drum/tempo\x00,f\x00\x00\x42\xf0\x00\x00")
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("expected %q, got %q", expected, data)
+	}
+
+	msg = OSCMessage{Address: OSCTrigger, Args: []interface{}{int32(4), int32(-1), "hh-open"}}
+	data, err = msg.MarshalBinary()
+	if err != nil {
+		t.Fatalf("something went wrong encoding - %v", err)
+	}
+	var decoded OSCMessage
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("something went wrong decoding - %v", err)
+	}
+	if decoded.Address != msg.Address || len(decoded.Args) != len(msg.Args) {
+		t.Fatalf("expected %v, got %v", msg, decoded)
+	}
+	for i := range msg.Args {
+		if decoded.Args[i] != msg.Args[i] {
+			t.Fatalf("expected %v, got %v", msg, decoded)
+		}
+	}
+
+	invalid := [][]byte{
+		[]byte("/drum/step"),
+		[]byte("drum\x00\x00\x00\x00,\x00\x00\x00"),
+		[]byte("/drum/step\x00\x00,i\x00\x00"),
+		[]byte("/drum/step\x00\x00,b\x00\x00"),
+	}
+	for _, data := range invalid {
+		if err := decoded.UnmarshalBinary(data); !errors.Is(err, ErrInvalidOSC) {
+			t.Fatalf("%q: expected ErrInvalidOSC, got %v", data, err)
+		}
+	}
+	if _, err := (OSCMessage{Address: OSCStep, Args: []interface{}{true}}).MarshalBinary(); !errors.Is(err, ErrInvalidOSC) {
+		t.Fatalf("expected ErrInvalidOSC, got %v", err)
+	}
+}
+
+func TestOSCClock(t *testing.T) {
+	clock, err := ListenOSC("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("something went wrong listening - %v", err)
+	}
+	sender, err := DialOSC(clock.Addr().String())
+	if err != nil {
+		t.Fatalf("something went wrong dialing - %v", err)
+	}
+	defer sender.Close()
+
+	sender.Send(OSCMessage{Address: OSCStart})
+	sender.Trigger(0, &Track{ID: 1, Name: "kick"})
+	sender.Step(0)
+	sender.Send(OSCMessage{Address: OSCTempo, Args: []interface{}{int32(90)}})
+	sender.Send(OSCMessage{Address: OSCPosition, Args: []interface{}{int32(12)}})
+	sender.Send(OSCMessage{Address: OSCStop})
+
+	// Triggers aren't clock events
+	expected := []ClockEvent{
+		{Type: ClockStart},
+		{Type: ClockStep},
+		{Type: ClockTempo, Tempo: 90},
+		{Type: ClockPosition, Step: 12},
+		{Type: ClockStop},
+	}
+	for _, exp := range expected {
+		select {
+		case event := <-clock.Events():
+			if event != exp {
+				t.Fatalf("expected %v, got %v", exp, event)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected %v, got nothing", exp)
+		}
+	}
+
+	clock.Close()
+	if _, ok := <-clock.Events(); ok {
+		t.Fatalf("expected the events to be closed")
+	}
+}
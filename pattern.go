@@ -0,0 +1,557 @@
+package drum
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tempo range supported by playback and rendering
+const (
+	MinTempo = 20.0
+	MaxTempo = 999.0
+)
+
+// NewPatternFromTracks creates a pattern from the given version, tempo and
+// tracks and validates the result.
+func NewPatternFromTracks(version string, tempo float32, tracks ...*Track) (*Pattern, error) {
+	pattern := &Pattern{
+		Version: version,
+		Tempo:   tempo,
+		Tracks:  tracks,
+	}
+
+	if err := pattern.Validate(); err != nil {
+		return nil, err
+	}
+
+	return pattern, nil
+}
+
+// Validate checks if the pattern can be encoded into a .splice file.
+// The first problem found is returned.
+func (pattern *Pattern) Validate() error {
+	if err := validateVersion(pattern.Version); err != nil {
+		return err
+	}
+	if err := validateTempo(pattern.Tempo); err != nil {
+		return err
+	}
+
+	ids := make(map[int]bool)
+	for _, track := range pattern.Tracks {
+		if track == nil {
+			return ErrNilTrack
+		}
+		if err := track.Validate(); err != nil {
+			return err
+		}
+		if ids[track.ID] {
+			return fmt.Errorf("%w: %d", ErrDuplicateTrackID, track.ID)
+		}
+		ids[track.ID] = true
+		if pattern.Extended() {
+			if err := track.validateExtended(); err != nil {
+				return err
+			}
+		}
+		if track.Length() != pattern.Tracks[0].Length() {
+			return fmt.Errorf("%w: track %d has %d steps, expected %d",
+				ErrStepCountMismatch, track.ID, track.Length(), pattern.Tracks[0].Length())
+		}
+	}
+
+	return nil
+}
+
+// TrackIndex returns the position of the track with the given ID in the
+// Tracks slice.
+func (pattern *Pattern) TrackIndex(id int) (int, bool) {
+	for i, track := range pattern.Tracks {
+		if track.ID == id {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// FindTrackByID returns the track with the given ID or nil if the pattern
+// doesn't contain it.
+func (pattern *Pattern) FindTrackByID(id int) *Track {
+	i, ok := pattern.TrackIndex(id)
+	if !ok {
+		return nil
+	}
+
+	return pattern.Tracks[i]
+}
+
+// Clone returns a deep copy of the pattern
+func (pattern *Pattern) Clone() *Pattern {
+	clone := &Pattern{
+		Version: pattern.Version,
+		Tempo:   pattern.Tempo,
+		Tracks:  make([]*Track, 0, len(pattern.Tracks)),
+	}
+	for _, track := range pattern.Tracks {
+		clone.Tracks = append(clone.Tracks, track.Clone())
+	}
+
+	return clone
+}
+
+// Reduce returns a copy of the pattern which only contains the tracks with
+// the given IDs, in the order of ids.
+func (pattern *Pattern) Reduce(ids []int) (*Pattern, error) {
+	reduced := &Pattern{
+		Version: pattern.Version,
+		Tempo:   pattern.Tempo,
+		Tracks:  make([]*Track, 0, len(ids)),
+	}
+	for _, id := range ids {
+		track := pattern.FindTrackByID(id)
+		if track == nil {
+			return nil, fmt.Errorf("%w: %d", ErrTrackNotFound, id)
+		}
+		reduced.Tracks = append(reduced.Tracks, track.Clone())
+	}
+
+	return reduced, nil
+}
+
+// Extend appends copies of the tracks of other whose IDs are not yet used in
+// the pattern. Version and tempo are left untouched.
+func (pattern *Pattern) Extend(other *Pattern) error {
+	if other == nil {
+		return ErrNilPattern
+	}
+
+	for _, track := range other.Tracks {
+		if _, ok := pattern.TrackIndex(track.ID); ok {
+			continue
+		}
+		pattern.Tracks = append(pattern.Tracks, track.Clone())
+	}
+
+	return nil
+}
+
+// TempoChange returns a copy of the pattern with the tempo set to newTempo.
+// It accepts the same tempos as Validate.
+func (pattern *Pattern) TempoChange(newTempo float32) (*Pattern, error) {
+	if err := validateTempo(newTempo); err != nil {
+		return nil, err
+	}
+
+	clone := pattern.Clone()
+	clone.Tempo = newTempo
+
+	return clone, nil
+}
+
+// validateTempo checks the tempo rule shared by Validate, SetTempo and
+// TempoChange: any positive, finite tempo.
+func validateTempo(tempo float32) error {
+	if tempo <= 0 || math.IsInf(float64(tempo), 0) || math.IsNaN(float64(tempo)) {
+		return fmt.Errorf("%w: %g", ErrInvalidTempo, tempo)
+	}
+
+	return nil
+}
+
+// validatePlaybackTempo checks that the tempo is within MinTempo and MaxTempo,
+// slower or faster patterns can't be played or rendered.
+func validatePlaybackTempo(tempo float32) error {
+	// Written this way so NaN is rejected as well
+	if !(tempo >= MinTempo && tempo <= MaxTempo) {
+		return fmt.Errorf("%w: %g", ErrInvalidTempo, tempo)
+	}
+
+	return nil
+}
+
+// Length returns the number of steps of the pattern, which is the length of
+// its first track. Patterns without tracks have StepCount steps.
+func (pattern *Pattern) Length() int {
+	if len(pattern.Tracks) == 0 || pattern.Tracks[0] == nil {
+		return StepCount
+	}
+
+	return pattern.Tracks[0].Length()
+}
+
+// SetLength changes the number of steps of all tracks, see Track.SetLength
+func (pattern *Pattern) SetLength(length int) error {
+	if !supportedStepCount(length) {
+		return fmt.Errorf("%w: %d", ErrInvalidStepCount, length)
+	}
+	for _, track := range pattern.Tracks {
+		track.SetLength(length)
+	}
+
+	return nil
+}
+
+// BeatCount returns the number of whole beats in the pattern
+func (pattern *Pattern) BeatCount() int {
+	beats := pattern.Length() / StepsPerBeat
+	if beats < 1 {
+		return 1
+	}
+
+	return beats
+}
+
+// BarDuration returns the time it takes to play one bar of StepCount steps at
+// the tempo of the pattern. Longer patterns take Length() / StepCount bars.
+func (pattern *Pattern) BarDuration() time.Duration {
+	beats := float64(StepCount) / StepsPerBeat
+	return time.Duration(beats * 60 / float64(pattern.Tempo) * float64(time.Second))
+}
+
+// CountActiveStepsPerBeat returns the number of active steps of all tracks
+// combined for every beat, BeatCount beats in total.
+func (pattern *Pattern) CountActiveStepsPerBeat() []int {
+	counts := make([]int, pattern.BeatCount())
+	for _, track := range pattern.Tracks {
+		for i, step := range track.AllSteps() {
+			if step {
+				counts[i/StepsPerBeat]++
+			}
+		}
+	}
+
+	return counts
+}
+
+// MostActiveTrack returns the track with the most active steps and its count.
+// Ties are resolved by picking the track with the lowest ID.
+func (pattern *Pattern) MostActiveTrack() (*Track, int) {
+	return pattern.selectTrackByActivity(func(count, best int) bool { return count > best })
+}
+
+// LeastActiveTrack returns the track with the fewest active steps and its
+// count. Ties are resolved by picking the track with the lowest ID.
+func (pattern *Pattern) LeastActiveTrack() (*Track, int) {
+	return pattern.selectTrackByActivity(func(count, best int) bool { return count < best })
+}
+
+func (pattern *Pattern) selectTrackByActivity(better func(count, best int) bool) (*Track, int) {
+	var selected *Track
+	best := 0
+	for _, track := range pattern.Tracks {
+		count := track.ActiveStepCount()
+		if selected == nil || better(count, best) || (count == best && track.ID < selected.ID) {
+			selected = track
+			best = count
+		}
+	}
+
+	return selected, best
+}
+
+// Normalize returns a copy of the pattern with the tracks sorted by ID and
+// renumbered starting from 1.
+func (pattern *Pattern) Normalize() *Pattern {
+	normalized := pattern.Clone()
+	sort.SliceStable(normalized.Tracks, func(i, j int) bool {
+		return normalized.Tracks[i].ID < normalized.Tracks[j].ID
+	})
+	for i, track := range normalized.Tracks {
+		track.ID = i + 1
+	}
+
+	return normalized
+}
+
+// Template returns a copy of the pattern where the steps of every track are
+// replaced by the steps of the track in other with the same name, including
+// its extra steps. Tracks without a match in other keep their steps.
+func (pattern *Pattern) Template(other *Pattern) *Pattern {
+	templated := pattern.Clone()
+	for _, template := range other.Tracks {
+		for _, track := range templated.Tracks {
+			if track.Name == template.Name {
+				track.CopyStepsFrom(template)
+			}
+		}
+	}
+
+	return templated
+}
+
+// SetTempo validates and sets the tempo of the pattern. It accepts the same
+// tempos as Validate.
+func (pattern *Pattern) SetTempo(tempo float32) error {
+	if err := validateTempo(tempo); err != nil {
+		return err
+	}
+	pattern.Tempo = tempo
+
+	return nil
+}
+
+// AddTrack appends a track with the given name and no active steps to the
+// pattern. It gets the lowest ID which is not used by another track and the
+// length of the pattern.
+func (pattern *Pattern) AddTrack(name string) (*Track, error) {
+	ids := make(map[int]bool)
+	for _, track := range pattern.Tracks {
+		ids[track.ID] = true
+	}
+
+	track, err := NewTrack(freeID(ids), name, [StepCount]bool{})
+	if err != nil {
+		return nil, err
+	}
+	track.SetLength(pattern.Length())
+	pattern.Tracks = append(pattern.Tracks, track)
+
+	return track, nil
+}
+
+// RemoveTrack removes the track with the given ID from the pattern
+func (pattern *Pattern) RemoveTrack(id int) error {
+	i, ok := pattern.TrackIndex(id)
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrTrackNotFound, id)
+	}
+	pattern.Tracks = append(pattern.Tracks[:i], pattern.Tracks[i+1:]...)
+
+	return nil
+}
+
+// BPM returns the tempo of the pattern in beats per minute. It is the same
+// value as the Tempo field.
+func (pattern *Pattern) BPM() float32 {
+	return pattern.Tempo
+}
+
+// SetBPM sets the tempo of the pattern in beats per minute. It is an alias
+// for SetTempo.
+func (pattern *Pattern) SetBPM(bpm float32) error {
+	return pattern.SetTempo(bpm)
+}
+
+// TrackCount returns the number of tracks in the pattern
+func (pattern *Pattern) TrackCount() int {
+	return len(pattern.Tracks)
+}
+
+// ActiveTrackCount returns the number of tracks with at least one active step
+func (pattern *Pattern) ActiveTrackCount() int {
+	return pattern.TrackCount() - pattern.SilentTrackCount()
+}
+
+// SilentTrackCount returns the number of tracks without active steps
+func (pattern *Pattern) SilentTrackCount() int {
+	count := 0
+	for _, track := range pattern.Tracks {
+		if track.IsEmpty() {
+			count++
+		}
+	}
+
+	return count
+}
+
+// ForEachTrack calls fn for every track in order and stops at the first
+// error returned by fn.
+func (pattern *Pattern) ForEachTrack(fn func(*Track) error) error {
+	for _, track := range pattern.Tracks {
+		if err := fn(track); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MapTracks returns a new pattern with every track replaced by the result of
+// fn. Tracks for which fn returns nil are left out. fn receives copies of the
+// tracks, so the pattern itself is never modified.
+func (pattern *Pattern) MapTracks(fn func(*Track) *Track) *Pattern {
+	mapped := &Pattern{
+		Version: pattern.Version,
+		Tempo:   pattern.Tempo,
+		Tracks:  make([]*Track, 0, len(pattern.Tracks)),
+	}
+	for _, track := range pattern.Tracks {
+		if result := fn(track.Clone()); result != nil {
+			mapped.Tracks = append(mapped.Tracks, result)
+		}
+	}
+
+	return mapped
+}
+
+// FilterTracks returns a copy of the pattern containing only the tracks for
+// which fn returns true.
+func (pattern *Pattern) FilterTracks(fn func(*Track) bool) *Pattern {
+	filtered := &Pattern{
+		Version: pattern.Version,
+		Tempo:   pattern.Tempo,
+		Tracks:  make([]*Track, 0, len(pattern.Tracks)),
+	}
+	for _, track := range pattern.Tracks {
+		if fn(track) {
+			filtered.Tracks = append(filtered.Tracks, track.Clone())
+		}
+	}
+
+	return filtered
+}
+
+// Transpose shifts the MIDI note of every track with a note by the given
+// number of semitones. Tracks without a note (0) are left alone. Notes
+// leaving the MIDI range are clamped instead of failing, and the IDs of the
+// clamped tracks are returned as a warning. The lowest note is 1 rather than
+// 0, since a MIDINote of 0 means "no note" and would unassign the track.
+func (pattern *Pattern) Transpose(semitones int) (clamped []int, err error) {
+	assigned := false
+	for _, track := range pattern.Tracks {
+		if track.MIDINote != 0 {
+			assigned = true
+			break
+		}
+	}
+	if !assigned {
+		return nil, ErrMIDINoteNotSet
+	}
+
+	for _, track := range pattern.Tracks {
+		if track.MIDINote == 0 {
+			continue
+		}
+		note := track.MIDINote + semitones
+		track.MIDINote = clamp(note, 1, 127)
+		if track.MIDINote != note {
+			clamped = append(clamped, track.ID)
+		}
+	}
+
+	return clamped, nil
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+
+	return v
+}
+
+// timeSignatures lists the time signatures recognized by DetectTimeSignature
+// with the steps their strong beats fall on.
+var timeSignatures = []struct {
+	numerator, denominator int
+	strong                 []int
+}{
+	{4, 4, []int{0, 4, 8, 12}},
+	{3, 4, []int{0, 5, 10}},
+	{6, 8, []int{0, 6, 12}},
+}
+
+// DetectTimeSignature guesses the time signature of the pattern from the
+// positions of its active steps. This is a heuristic, not a guarantee: every
+// candidate is scored by how much more often its strong beats are hit than
+// the other steps, and 4/4 is returned unless another candidate scores
+// better. The bars of longer patterns are added up.
+func (pattern *Pattern) DetectTimeSignature() (numerator, denominator int) {
+	var hits [StepCount]int
+	for _, track := range pattern.Tracks {
+		for i, step := range track.AllSteps() {
+			if step {
+				hits[i%StepCount]++
+			}
+		}
+	}
+
+	best := -1
+	var bestScore float64
+	for i, signature := range timeSignatures {
+		strong := 0
+		for _, step := range signature.strong {
+			strong += hits[step]
+		}
+		other := -strong
+		for _, count := range hits {
+			other += count
+		}
+
+		score := float64(strong)/float64(len(signature.strong)) -
+			float64(other)/float64(StepCount-len(signature.strong))
+		if best < 0 || score > bestScore {
+			best = i
+			bestScore = score
+		}
+	}
+
+	return timeSignatures[best].numerator, timeSignatures[best].denominator
+}
+
+// Shuffle returns a copy of the pattern with the tracks in a random order
+// determined by seed.
+func (pattern *Pattern) Shuffle(seed int64) *Pattern {
+	shuffled := pattern.Clone()
+	r := rand.New(rand.NewSource(seed))
+	for i := len(shuffled.Tracks) - 1; i > 0; i-- {
+		j := r.Intn(i + 1)
+		shuffled.Tracks[i], shuffled.Tracks[j] = shuffled.Tracks[j], shuffled.Tracks[i]
+	}
+
+	return shuffled
+}
+
+// WithTracks returns a copy of the pattern with its tracks replaced by copies
+// of the given tracks.
+func (pattern *Pattern) WithTracks(tracks ...*Track) (*Pattern, error) {
+	replaced := &Pattern{
+		Version: pattern.Version,
+		Tempo:   pattern.Tempo,
+		Tracks:  make([]*Track, 0, len(tracks)),
+	}
+
+	ids := make(map[int]bool)
+	for _, track := range tracks {
+		if track == nil {
+			return nil, ErrNilTrack
+		}
+		if ids[track.ID] {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateTrackID, track.ID)
+		}
+		ids[track.ID] = true
+		replaced.Tracks = append(replaced.Tracks, track.Clone())
+	}
+
+	return replaced, nil
+}
+
+// SetVersion validates and sets the version of the pattern. The version is
+// stored in a null-padded field of 32 bytes.
+func (pattern *Pattern) SetVersion(v string) error {
+	if err := validateVersion(v); err != nil {
+		return err
+	}
+	pattern.Version = v
+
+	return nil
+}
+
+func validateVersion(v string) error {
+	if len(v) > 32 {
+		return ErrVersionTooLong
+	}
+	if strings.ContainsRune(v, 0) {
+		return ErrInvalidVersionString
+	}
+
+	return nil
+}
@@ -0,0 +1,604 @@
+package drum
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewPatternFromTracks(t *testing.T) {
+	pattern, err := NewPatternFromTracks("0.808-alpha", 120,
+		&Track{ID: 1, Name: "kick"},
+		&Track{ID: 2, Name: "snare"},
+	)
+	if err != nil {
+		t.Fatalf("something went wrong creating the pattern - %v", err)
+	}
+	if len(pattern.Tracks) != 2 {
+		t.Fatalf("expected 2 tracks, got %d", len(pattern.Tracks))
+	}
+
+	pattern, err = NewPatternFromTracks("0.808-alpha", 120,
+		&Track{ID: 1, Name: "kick"},
+		&Track{ID: 1, Name: "snare"},
+	)
+	if !errors.Is(err, ErrDuplicateTrackID) {
+		t.Fatalf("expected ErrDuplicateTrackID, got %v", err)
+	}
+	if pattern != nil {
+		t.Fatalf("expected no pattern, got %v", pattern)
+	}
+}
+
+func testPattern() *Pattern {
+	return &Pattern{
+		Version: "0.808-alpha",
+		Tempo:   120,
+		Tracks: []*Track{
+			&Track{ID: 0, Name: "kick", Steps: [16]bool{0: true, 4: true, 8: true, 12: true}},
+			&Track{ID: 1, Name: "snare", Steps: [16]bool{4: true, 12: true}},
+			&Track{ID: 3, Name: "hh-open", Steps: [16]bool{2: true, 6: true, 8: true, 10: true, 14: true}},
+			&Track{ID: 5, Name: "cowbell"},
+		},
+	}
+}
+
+func TestTrackIndex(t *testing.T) {
+	pattern := testPattern()
+
+	tData := []struct {
+		id    int
+		index int
+		found bool
+	}{
+		{0, 0, true},
+		{1, 1, true},
+		{5, 3, true},
+		{2, 0, false},
+	}
+
+	for _, exp := range tData {
+		index, found := pattern.TrackIndex(exp.id)
+		if index != exp.index || found != exp.found {
+			t.Fatalf("TrackIndex(%d): expected (%d, %v), got (%d, %v)",
+				exp.id, exp.index, exp.found, index, found)
+		}
+	}
+}
+
+func TestReduce(t *testing.T) {
+	pattern := testPattern()
+
+	reduced, err := pattern.Reduce([]int{1, 0})
+	if err != nil {
+		t.Fatalf("something went wrong reducing - %v", err)
+	}
+	if len(reduced.Tracks) != 2 || reduced.Tracks[0].Name != "snare" || reduced.Tracks[1].Name != "kick" {
+		t.Fatalf("unexpected tracks after reducing:\n%s", reduced)
+	}
+	reduced.Tracks[0].Steps[0] = true
+	if pattern.Tracks[1].Steps[0] {
+		t.Fatalf("reducing didn't copy the tracks")
+	}
+
+	reduced, err = pattern.Reduce(nil)
+	if err != nil {
+		t.Fatalf("something went wrong reducing - %v", err)
+	}
+	if len(reduced.Tracks) != 0 || reduced.Validate() != nil {
+		t.Fatalf("expected an empty valid pattern, got:\n%s", reduced)
+	}
+
+	if _, err := pattern.Reduce([]int{42}); !errors.Is(err, ErrTrackNotFound) {
+		t.Fatalf("expected ErrTrackNotFound, got %v", err)
+	}
+}
+
+func TestExtend(t *testing.T) {
+	pattern := testPattern()
+	expected := pattern.String()
+
+	if err := pattern.Extend(testPattern()); err != nil {
+		t.Fatalf("something went wrong extending - %v", err)
+	}
+	if pattern.String() != expected {
+		t.Fatalf("extending with the same tracks changed the pattern:\n%s", pattern)
+	}
+
+	other := &Pattern{Version: "0.909", Tempo: 98, Tracks: []*Track{
+		&Track{ID: 1, Name: "clap"},
+		&Track{ID: 7, Name: "rim"},
+	}}
+	if err := pattern.Extend(other); err != nil {
+		t.Fatalf("something went wrong extending - %v", err)
+	}
+	if len(pattern.Tracks) != 5 || pattern.Tracks[4].Name != "rim" || pattern.Tracks[1].Name != "snare" {
+		t.Fatalf("unexpected tracks after extending:\n%s", pattern)
+	}
+	if pattern.Version != "0.808-alpha" || pattern.Tempo != 120 {
+		t.Fatalf("extending changed version or tempo:\n%s", pattern)
+	}
+}
+
+func TestTempoChange(t *testing.T) {
+	pattern := testPattern()
+
+	changed, err := pattern.TempoChange(98.4)
+	if err != nil {
+		t.Fatalf("something went wrong changing the tempo - %v", err)
+	}
+	if changed.Tempo != 98.4 || pattern.Tempo != 120 {
+		t.Fatalf("expected tempo 98.4 on the copy and 120 on the original, got %g and %g",
+			changed.Tempo, pattern.Tempo)
+	}
+	if changed.Tracks[0] == pattern.Tracks[0] {
+		t.Fatalf("tracks weren't copied")
+	}
+
+	for _, tempo := range []float32{0, -1, float32(math.NaN()), float32(math.Inf(1))} {
+		if _, err := pattern.TempoChange(tempo); !errors.Is(err, ErrInvalidTempo) {
+			t.Fatalf("TempoChange(%g): expected ErrInvalidTempo, got %v", tempo, err)
+		}
+	}
+
+	// Decoded patterns can have any positive tempo, which Validate accepts
+	pattern.Tempo = 1200
+	if err := pattern.Validate(); err != nil {
+		t.Fatalf("expected tempo 1200 to be valid, got %v", err)
+	}
+}
+
+func TestCountActiveStepsPerBeat(t *testing.T) {
+	pattern := &Pattern{Tracks: []*Track{
+		&Track{ID: 1, Name: "kick", Steps: [16]bool{0: true, 2: true}},
+		&Track{ID: 2, Name: "hihat", Steps: [16]bool{0: true, 1: true, 2: true, 3: true}},
+	}}
+
+	if counts := pattern.CountActiveStepsPerBeat(); fmt.Sprint(counts) != "[6 0 0 0]" {
+		t.Fatalf("expected [6 0 0 0], got %v", counts)
+	}
+
+	pattern.SetLength(32)
+	pattern.Tracks[0].SetStep(20, true)
+	if counts := pattern.CountActiveStepsPerBeat(); fmt.Sprint(counts) != "[6 0 0 0 0 1 0 0]" {
+		t.Fatalf("expected [6 0 0 0 0 1 0 0], got %v", counts)
+	}
+}
+
+func TestMostLeastActiveTrack(t *testing.T) {
+	pattern := testPattern()
+
+	if track, count := pattern.MostActiveTrack(); track.Name != "hh-open" || count != 5 {
+		t.Fatalf("expected hh-open with 5 steps, got %v with %d", track, count)
+	}
+	if track, count := pattern.LeastActiveTrack(); track.Name != "cowbell" || count != 0 {
+		t.Fatalf("expected cowbell with 0 steps, got %v with %d", track, count)
+	}
+
+	// Ties are resolved by the lowest ID
+	pattern.Tracks[3].Steps = [16]bool{1: true, 3: true, 5: true, 7: true, 9: true}
+	pattern.Tracks[3].ID = 2
+	if track, _ := pattern.MostActiveTrack(); track.Name != "cowbell" {
+		t.Fatalf("expected cowbell, got %v", track)
+	}
+	pattern.Tracks[0].Steps = [16]bool{0: true, 8: true}
+	if track, _ := pattern.LeastActiveTrack(); track.Name != "kick" {
+		t.Fatalf("expected kick, got %v", track)
+	}
+
+	single := &Pattern{Tracks: []*Track{&Track{ID: 1, Name: "kick"}}}
+	if track, count := single.MostActiveTrack(); track != single.Tracks[0] || count != 0 {
+		t.Fatalf("expected the only track, got %v", track)
+	}
+	if track, count := single.LeastActiveTrack(); track != single.Tracks[0] || count != 0 {
+		t.Fatalf("expected the only track, got %v", track)
+	}
+
+	empty := &Pattern{}
+	if track, count := empty.MostActiveTrack(); track != nil || count != 0 {
+		t.Fatalf("expected no track for an empty pattern, got %v", track)
+	}
+	if track, count := empty.LeastActiveTrack(); track != nil || count != 0 {
+		t.Fatalf("expected no track for an empty pattern, got %v", track)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 120, Tracks: []*Track{
+		&Track{ID: 42, Name: "snare"},
+		&Track{ID: 7, Name: "kick"},
+		&Track{ID: 105, Name: "clap"},
+	}}
+
+	normalized := pattern.Normalize()
+	for i, name := range []string{"kick", "snare", "clap"} {
+		track := normalized.Tracks[i]
+		if track.ID != i+1 || track.Name != name {
+			t.Fatalf("expected track (%d) %s, got %v", i+1, name, track)
+		}
+	}
+	if pattern.Tracks[0].ID != 42 {
+		t.Fatalf("normalizing modified the original pattern")
+	}
+
+	if again := normalized.Normalize(); again.String() != normalized.String() {
+		t.Fatalf("normalizing isn't idempotent:\n%s\n%s", normalized, again)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	pattern := testPattern()
+	template := &Pattern{Tracks: []*Track{
+		&Track{ID: 9, Name: "snare", Steps: [16]bool{2: true, 10: true}},
+		&Track{ID: 10, Name: "rim", Steps: [16]bool{1: true}},
+	}}
+
+	templated := pattern.Template(template)
+	if templated.Tracks[1].Steps != template.Tracks[0].Steps {
+		t.Fatalf("snare steps weren't replaced:\n%s", templated)
+	}
+	if templated.Tracks[1].ID != 1 || templated.Tracks[0].Steps != pattern.Tracks[0].Steps {
+		t.Fatalf("unmatched data was modified:\n%s", templated)
+	}
+	if pattern.Tracks[1].Steps == template.Tracks[0].Steps {
+		t.Fatalf("the original pattern was modified")
+	}
+}
+
+func TestBPM(t *testing.T) {
+	pattern := testPattern()
+	if pattern.BPM() != pattern.Tempo {
+		t.Fatalf("expected BPM %g, got %g", pattern.Tempo, pattern.BPM())
+	}
+
+	if err := pattern.SetBPM(98.4); err != nil || pattern.Tempo != 98.4 || pattern.BPM() != pattern.Tempo {
+		t.Fatalf("expected tempo 98.4, got %g (%v)", pattern.Tempo, err)
+	}
+	if err := pattern.SetBPM(0); !errors.Is(err, ErrInvalidTempo) || pattern.Tempo != 98.4 {
+		t.Fatalf("expected ErrInvalidTempo and an unchanged tempo, got %g (%v)", pattern.Tempo, err)
+	}
+}
+
+func TestTempoRules(t *testing.T) {
+	tempos := []float32{0, -1, 0.5, 15, 19.9, 120, 999.1, 1200,
+		float32(math.NaN()), float32(math.Inf(1)), float32(math.Inf(-1))}
+
+	for _, tempo := range tempos {
+		pattern := testPattern()
+		pattern.Tempo = tempo
+		valid := pattern.Validate() == nil
+
+		pattern = testPattern()
+		if ok := pattern.SetTempo(tempo) == nil; ok != valid {
+			t.Fatalf("%g: Validate accepts it: %t, SetTempo accepts it: %t", tempo, valid, ok)
+		}
+		if ok := pattern.SetBPM(tempo) == nil; ok != valid {
+			t.Fatalf("%g: Validate accepts it: %t, SetBPM accepts it: %t", tempo, valid, ok)
+		}
+		if _, err := pattern.TempoChange(tempo); (err == nil) != valid {
+			t.Fatalf("%g: Validate accepts it: %t, TempoChange returned %v", tempo, valid, err)
+		}
+	}
+
+	// Decoded patterns can set their own tempo again
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding - %v", err)
+	}
+	if err := pattern.SetTempo(pattern.Tempo); err != nil {
+		t.Fatalf("something went wrong setting the decoded tempo - %v", err)
+	}
+}
+
+func TestActiveSilentTrackCount(t *testing.T) {
+	tData := []struct {
+		pattern        *Pattern
+		active, silent int
+	}{
+		{&Pattern{}, 0, 0},
+		{&Pattern{Tracks: []*Track{&Track{ID: 1}, &Track{ID: 2}}}, 0, 2},
+		{&Pattern{Tracks: testPattern().Tracks[:3]}, 3, 0},
+		{testPattern(), 3, 1},
+	}
+
+	for _, exp := range tData {
+		if active := exp.pattern.ActiveTrackCount(); active != exp.active {
+			t.Fatalf("expected %d active tracks, got %d", exp.active, active)
+		}
+		if silent := exp.pattern.SilentTrackCount(); silent != exp.silent {
+			t.Fatalf("expected %d silent tracks, got %d", exp.silent, silent)
+		}
+	}
+}
+
+func TestForEachTrack(t *testing.T) {
+	pattern := testPattern()
+	stop := errors.New("stop")
+
+	visited := 0
+	err := pattern.ForEachTrack(func(track *Track) error {
+		visited++
+		track.Steps[15] = true
+		if visited == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("expected the error returned by fn, got %v", err)
+	}
+	if visited != 2 {
+		t.Fatalf("expected iteration to stop after 2 tracks, visited %d", visited)
+	}
+	if !pattern.Tracks[0].Steps[15] || !pattern.Tracks[1].Steps[15] || pattern.Tracks[2].Steps[15] {
+		t.Fatalf("mutations weren't reflected in the pattern:\n%s", pattern)
+	}
+}
+
+func TestMapTracks(t *testing.T) {
+	pattern := testPattern()
+
+	mapped := pattern.MapTracks(func(track *Track) *Track {
+		track.Name += "-2"
+		return track
+	})
+	if len(mapped.Tracks) != 4 || mapped.Tracks[0].Name != "kick-2" || pattern.Tracks[0].Name != "kick" {
+		t.Fatalf("unexpected mapped pattern:\n%s", mapped)
+	}
+
+	empty := pattern.MapTracks(func(*Track) *Track { return nil })
+	if len(empty.Tracks) != 0 || empty.Tempo != pattern.Tempo {
+		t.Fatalf("expected an empty pattern, got:\n%s", empty)
+	}
+}
+
+func TestFilterTracks(t *testing.T) {
+	pattern := testPattern()
+
+	filtered := pattern.FilterTracks(func(track *Track) bool { return !track.IsEmpty() })
+	if len(filtered.Tracks) != 3 || filtered.FindTrackByID(5) != nil {
+		t.Fatalf("unexpected filtered pattern:\n%s", filtered)
+	}
+	if filtered.Tracks[0] == pattern.Tracks[0] {
+		t.Fatalf("filtered tracks weren't copied")
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	pattern := testPattern()
+	if _, err := pattern.Transpose(2); err != ErrMIDINoteNotSet {
+		t.Fatalf("expected ErrMIDINoteNotSet, got %v", err)
+	}
+
+	pattern.Tracks[0].MIDINote = 36
+	pattern.Tracks[1].MIDINote = 125
+	pattern.Tracks[2].MIDINote = 1
+	clamped, err := pattern.Transpose(3)
+	if err != nil {
+		t.Fatalf("something went wrong transposing - %v", err)
+	}
+	if fmt.Sprint(clamped) != "[1]" {
+		t.Fatalf("expected track 1 to be clamped, got %v", clamped)
+	}
+	// Track 5 has no note and is left alone
+	for i, note := range []int{39, 127, 4, 0} {
+		if pattern.Tracks[i].MIDINote != note {
+			t.Fatalf("track %d: expected note %d, got %d", i, note, pattern.Tracks[i].MIDINote)
+		}
+	}
+
+	clamped, err = pattern.Transpose(-10)
+	if err != nil {
+		t.Fatalf("something went wrong transposing - %v", err)
+	}
+	if fmt.Sprint(clamped) != "[3]" {
+		t.Fatalf("expected track 3 to be clamped, got %v", clamped)
+	}
+	for i, note := range []int{29, 117, 1, 0} {
+		if pattern.Tracks[i].MIDINote != note {
+			t.Fatalf("track %d: expected note %d, got %d", i, note, pattern.Tracks[i].MIDINote)
+		}
+	}
+}
+
+func TestDetectTimeSignature(t *testing.T) {
+	tData := []struct {
+		steps                  [16]bool
+		numerator, denominator int
+	}{
+		{[16]bool{}, 4, 4},
+		{[16]bool{0: true, 4: true, 8: true, 12: true}, 4, 4},
+		{[16]bool{0: true, 5: true, 10: true}, 3, 4},
+		{[16]bool{0: true, 6: true, 12: true}, 6, 8},
+	}
+
+	for _, exp := range tData {
+		pattern := &Pattern{Tracks: []*Track{&Track{Steps: exp.steps}}}
+		numerator, denominator := pattern.DetectTimeSignature()
+		if numerator != exp.numerator || denominator != exp.denominator {
+			t.Fatalf("%v: expected %d/%d, got %d/%d", exp.steps, exp.numerator, exp.denominator, numerator, denominator)
+		}
+	}
+
+	// Only the second bar of a 32 step pattern has active steps
+	track := &Track{}
+	track.setStepString("---------------- x----x----x-----")
+	pattern := &Pattern{Tracks: []*Track{track}}
+	if numerator, denominator := pattern.DetectTimeSignature(); numerator != 3 || denominator != 4 {
+		t.Fatalf("expected 3/4, got %d/%d", numerator, denominator)
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding - %v", err)
+	}
+	original := pattern.String()
+
+	order := func(p *Pattern) string {
+		return fmt.Sprint(p.Tracks)
+	}
+
+	first := pattern.Shuffle(42)
+	if order(first) != order(pattern.Shuffle(42)) {
+		t.Fatalf("shuffling with the same seed isn't deterministic")
+	}
+	if order(first) == order(pattern.Shuffle(7)) {
+		t.Fatalf("shuffling with different seeds produced the same order")
+	}
+	if pattern.String() != original {
+		t.Fatalf("shuffling modified the original pattern")
+	}
+	if len(first.Tracks) != len(pattern.Tracks) || first.Normalize().String() != pattern.Normalize().String() {
+		t.Fatalf("shuffling changed the tracks:\n%s", first)
+	}
+}
+
+func TestSetVersion(t *testing.T) {
+	tData := []struct {
+		version string
+		err     error
+	}{
+		{"0.808-alpha", nil},
+		{strings.Repeat("v", 32), nil},
+		{strings.Repeat("v", 33), ErrVersionTooLong},
+		{"0.808\x00alpha", ErrInvalidVersionString},
+	}
+
+	for _, exp := range tData {
+		pattern := testPattern()
+		err := pattern.SetVersion(exp.version)
+		if err != exp.err {
+			t.Fatalf("SetVersion(%q): expected %v, got %v", exp.version, exp.err, err)
+		}
+		if err == nil && pattern.Version != exp.version {
+			t.Fatalf("SetVersion(%q): version is %q", exp.version, pattern.Version)
+		}
+		if err != nil && pattern.Version != "0.808-alpha" {
+			t.Fatalf("SetVersion(%q): version changed to %q", exp.version, pattern.Version)
+		}
+	}
+}
+
+func TestBarDuration(t *testing.T) {
+	tests := []struct {
+		tempo    float32
+		expected time.Duration
+	}{
+		{120, 2 * time.Second},
+		{60, 4 * time.Second},
+		{98.4, 2439024390 * time.Nanosecond},
+	}
+
+	for _, test := range tests {
+		pattern := &Pattern{Tempo: test.tempo}
+		if duration := pattern.BarDuration(); duration.Round(time.Millisecond) != test.expected.Round(time.Millisecond) {
+			t.Fatalf("expected a bar at %g BPM to take %v, got %v", test.tempo, test.expected, duration)
+		}
+	}
+
+	// A bar of a 32 step pattern has 16 steps too
+	pattern := testPattern()
+	pattern.SetLength(32)
+	if duration := pattern.BarDuration(); duration != 2*time.Second {
+		t.Fatalf("expected a 32 step pattern at 120 BPM to have 2s bars, got %v", duration)
+	}
+}
+
+func TestAddRemoveTrack(t *testing.T) {
+	pattern := testPattern()
+
+	track, err := pattern.AddTrack("clap")
+	if err != nil {
+		t.Fatalf("something went wrong adding a track - %v", err)
+	}
+	if track.ID != 2 || pattern.Tracks[4] != track {
+		t.Fatalf("expected clap to be appended with ID 2, got:\n%s", pattern)
+	}
+	if _, err := pattern.AddTrack(strings.Repeat("a", 128)); err != ErrTrackNameTooLong {
+		t.Fatalf("expected ErrTrackNameTooLong, got %v", err)
+	}
+	if _, err := pattern.AddTrack(""); err != ErrEmptyTrackName {
+		t.Fatalf("expected ErrEmptyTrackName, got %v", err)
+	}
+
+	if err := pattern.RemoveTrack(1); err != nil {
+		t.Fatalf("something went wrong removing a track - %v", err)
+	}
+	if pattern.FindTrackByID(1) != nil || len(pattern.Tracks) != 4 {
+		t.Fatalf("expected snare to be removed, got:\n%s", pattern)
+	}
+	if err := pattern.RemoveTrack(1); !errors.Is(err, ErrTrackNotFound) {
+		t.Fatalf("expected ErrTrackNotFound, got %v", err)
+	}
+	if err := pattern.Validate(); err != nil {
+		t.Fatalf("edited pattern is invalid - %v", err)
+	}
+}
+
+func TestSetLength(t *testing.T) {
+	pattern := testPattern()
+	if err := pattern.SetLength(32); err != nil {
+		t.Fatalf("something went wrong setting the length - %v", err)
+	}
+	if err := pattern.Tracks[0].SetStep(31, true); err != nil {
+		t.Fatalf("something went wrong setting step 31 - %v", err)
+	}
+	if err := pattern.Tracks[0].SetStep(32, true); !errors.Is(err, ErrStepOutOfRange) {
+		t.Fatalf("expected ErrStepOutOfRange, got %v", err)
+	}
+	if track, _ := pattern.AddTrack("clap"); track.Length() != 32 {
+		t.Fatalf("expected the added track to have 32 steps, got %d", track.Length())
+	}
+	if err := pattern.Validate(); err != nil {
+		t.Fatalf("32 step pattern is invalid - %v", err)
+	}
+
+	pattern.Tracks[1].SetLength(64)
+	if err := pattern.Validate(); !errors.Is(err, ErrStepCountMismatch) {
+		t.Fatalf("expected ErrStepCountMismatch, got %v", err)
+	}
+	if err := pattern.SetLength(48); !errors.Is(err, ErrInvalidStepCount) {
+		t.Fatalf("expected ErrInvalidStepCount, got %v", err)
+	}
+
+	pattern.SetLength(16)
+	if pattern.Tracks[0].ExtraSteps != nil || pattern.String() != testPattern().String()+"(2) clap\t|----|----|----|----|\n" {
+		t.Fatalf("unexpected pattern after shortening:\n%s", pattern)
+	}
+}
+
+func TestWithTracks(t *testing.T) {
+	pattern := testPattern()
+	clap := &Track{ID: 7, Name: "clap", Steps: [16]bool{4: true}}
+	clap.SetLength(32)
+
+	replaced, err := pattern.WithTracks(clap)
+	if err != nil {
+		t.Fatalf("something went wrong replacing the tracks - %v", err)
+	}
+	if replaced.TrackCount() != 1 || replaced.Tempo != pattern.Tempo || pattern.TrackCount() != 4 {
+		t.Fatalf("expected a copy with only the clap track, got\n%s", replaced)
+	}
+
+	// The tracks are deep copies
+	clap.Name = "snap"
+	clap.SetStep(0, true)
+	clap.SetStep(20, true)
+	if track := replaced.Tracks[0]; track == clap || track.Name != "clap" ||
+		formatSteps(track.AllSteps()) != "----x---------------------------" {
+		t.Fatalf("changing the input track changed the result, got %s", track)
+	}
+
+	if _, err := pattern.WithTracks(clap, &Track{ID: 7, Name: "clap"}); !errors.Is(err, ErrDuplicateTrackID) {
+		t.Fatalf("expected ErrDuplicateTrackID, got %v", err)
+	}
+	if _, err := pattern.WithTracks(clap, nil); !errors.Is(err, ErrNilTrack) {
+		t.Fatalf("expected ErrNilTrack, got %v", err)
+	}
+}
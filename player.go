@@ -0,0 +1,334 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+// Sample is decoded audio, with the channels interleaved and values between
+// -1 and 1.
+type Sample struct {
+	SampleRate int
+	Channels   int
+	Data       []float32
+}
+
+// SampleBank holds the samples played for the tracks of a pattern, by track
+// ID.
+type SampleBank struct {
+	mu      sync.RWMutex
+	samples map[int]*Sample
+}
+
+// NewSampleBank creates an empty sample bank
+func NewSampleBank() *SampleBank {
+	return &SampleBank{samples: make(map[int]*Sample)}
+}
+
+// Add sets the sample played for the track with the given ID
+func (bank *SampleBank) Add(id int, sample *Sample) {
+	bank.mu.Lock()
+	defer bank.mu.Unlock()
+
+	bank.samples[id] = sample
+}
+
+// Sample returns the sample played for the track with the given ID
+func (bank *SampleBank) Sample(id int) (*Sample, bool) {
+	bank.mu.RLock()
+	defer bank.mu.RUnlock()
+
+	sample, ok := bank.samples[id]
+	return sample, ok
+}
+
+// LoadWAV decodes the WAV file at path and sets it as the sample played for
+// the track with the given ID.
+func (bank *SampleBank) LoadWAV(id int, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sample, err := DecodeWAV(f)
+	if err != nil {
+		return err
+	}
+	bank.Add(id, sample)
+
+	return nil
+}
+
+// DecodeWAV decodes a WAV file with 8, 16 or 24 bit PCM or 32 bit float
+// samples.
+func DecodeWAV(r io.Reader) (*Sample, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if string(header[:4]) != "RIFF" || string(header[8:]) != "WAVE" {
+		return nil, fmt.Errorf("%w: not a WAV file", ErrInvalidFormat)
+	}
+
+	var format struct {
+		AudioFormat   uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+	}
+	haveFormat := false
+	for {
+		var chunk struct {
+			ID   [4]byte
+			Size uint32
+		}
+		if err := binary.Read(r, binary.LittleEndian, &chunk); err != nil {
+			if err == io.EOF {
+				err = fmt.Errorf("%w: WAV file without data", ErrInvalidFormat)
+			}
+			return nil, err
+		}
+		// Chunks are padded to an even size
+		size := int64(chunk.Size)
+		padding := size % 2
+
+		switch string(chunk.ID[:]) {
+		case "fmt ":
+			data, err := readChunk(r, size+padding)
+			if err != nil {
+				return nil, err
+			}
+			if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &format); err != nil {
+				return nil, fmt.Errorf("%w: invalid WAV format chunk", ErrInvalidFormat)
+			}
+			haveFormat = true
+		case "data":
+			if !haveFormat {
+				return nil, fmt.Errorf("%w: WAV data before format", ErrInvalidFormat)
+			}
+			data, err := readChunk(r, size)
+			if err != nil {
+				return nil, err
+			}
+			values, err := decodeWAVData(data, format.AudioFormat, format.BitsPerSample)
+			if err != nil {
+				return nil, err
+			}
+			return &Sample{SampleRate: int(format.SampleRate), Channels: int(format.Channels), Data: values}, nil
+		default:
+			if _, err := io.CopyN(io.Discard, r, size+padding); err != nil {
+				return nil, unexpectedEOF(err)
+			}
+		}
+	}
+}
+
+// readChunk reads the n bytes of a chunk. The buffer grows with the bytes
+// which are read, so a corrupt size doesn't allocate its memory up front.
+func readChunk(r io.Reader, n int64) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if _, err := io.CopyN(buf, r, n); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// unexpectedEOF returns io.ErrUnexpectedEOF for io.EOF, the end of the file
+// in the middle of a chunk
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
+
+// decodeWAVData converts the little endian sample data to float32 values
+func decodeWAVData(data []byte, audioFormat, bits uint16) ([]float32, error) {
+	const (
+		pcm       = 1
+		ieeeFloat = 3
+	)
+
+	size := int(bits / 8)
+	switch {
+	case audioFormat == pcm && (bits == 8 || bits == 16 || bits == 24):
+	case audioFormat == ieeeFloat && bits == 32:
+	default:
+		return nil, fmt.Errorf("%w: unsupported WAV encoding %d with %d bits", ErrInvalidFormat, audioFormat, bits)
+	}
+
+	values := make([]float32, len(data)/size)
+	for i := range values {
+		b := data[i*size:]
+		switch {
+		case audioFormat == ieeeFloat:
+			values[i] = math.Float32frombits(binary.LittleEndian.Uint32(b))
+		case bits == 8:
+			// 8 bit samples are unsigned
+			values[i] = float32(int(b[0])-128) / 128
+		case bits == 16:
+			values[i] = float32(int16(binary.LittleEndian.Uint16(b))) / 32768
+		case bits == 24:
+			v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+			values[i] = float32(v) / 8388608
+		}
+	}
+
+	return values, nil
+}
+
+// AudioOutput plays the samples triggered by a Player. The package doesn't
+// include an audio backend, implementations can use any audio library.
+type AudioOutput interface {
+	Play(sample *Sample)
+}
+
+// Player plays a pattern in real time, looping over all its steps at the
+// pattern tempo. For every active step the sample of the track is sent to Output.
+//
+// The callbacks run on the playback goroutine and must not call Start, Pause
+// or Stop. The pattern must not be modified while playing.
+type Player struct {
+	// Samples holds the samples of the tracks, tracks without a sample only
+	// trigger OnTrigger.
+	Samples *SampleBank
+	// Output plays the samples, if set
+	Output AudioOutput
+	// OnStep is called for every step, before its samples are played
+	OnStep func(step int)
+	// OnTrigger is called for every active step of a track
+	OnTrigger func(step int, track *Track)
+	// OnTempo is called with the tempo of the clock source the player is
+	// synced to, when it changes
+	OnTempo func(bpm float32)
+
+	pattern *Pattern
+	mu      sync.Mutex
+	step    int
+	stop    chan struct{}
+	done    chan struct{}
+	// sync is set while the player follows a clock source, running while
+	// the source has started the player
+	sync    *syncState
+	running bool
+}
+
+// NewPlayer creates a stopped player for the pattern with an empty sample
+// bank.
+func NewPlayer(pattern *Pattern) *Player {
+	return &Player{Samples: NewSampleBank(), pattern: pattern}
+}
+
+// Start starts or resumes playback from the current step. Starting a player
+// which is already playing does nothing, a player which is synced to a clock
+// source can't be started.
+func (player *Player) Start() error {
+	player.mu.Lock()
+	defer player.mu.Unlock()
+
+	if player.sync != nil {
+		return ErrPlayerSynced
+	}
+	if player.stop != nil {
+		return nil
+	}
+	if err := validatePlaybackTempo(player.pattern.Tempo); err != nil {
+		return err
+	}
+
+	player.stop = make(chan struct{})
+	player.done = make(chan struct{})
+	go player.run(player.stop, player.done, player.pattern.BarDuration()/StepCount)
+
+	return nil
+}
+
+// Pause stops playback, keeping the current step so Start resumes from it
+func (player *Player) Pause() {
+	player.mu.Lock()
+	stop, done := player.stop, player.done
+	player.stop, player.done = nil, nil
+	player.running = false
+	player.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// Stop stops playback and rewinds to the first step
+func (player *Player) Stop() {
+	player.Pause()
+
+	player.mu.Lock()
+	player.step = 0
+	player.mu.Unlock()
+}
+
+// Playing returns whether the player is playing
+func (player *Player) Playing() bool {
+	player.mu.Lock()
+	defer player.mu.Unlock()
+
+	return player.stop != nil || player.running
+}
+
+// Step returns the step which is played next
+func (player *Player) Step() int {
+	player.mu.Lock()
+	defer player.mu.Unlock()
+
+	return player.step
+}
+
+// run plays a step on every tick until stop is closed
+func (player *Player) run(stop, done chan struct{}, interval time.Duration) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		player.playStep()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// playStep triggers the current step and advances to the next one
+func (player *Player) playStep() {
+	player.mu.Lock()
+	step := player.step
+	player.step = (step + 1) % player.pattern.Length()
+	player.mu.Unlock()
+
+	if player.OnStep != nil {
+		player.OnStep(step)
+	}
+	for _, track := range player.pattern.Tracks {
+		if on, err := track.step(step); err != nil || !*on {
+			continue
+		}
+		if player.OnTrigger != nil {
+			player.OnTrigger(step, track)
+		}
+		if sample, ok := player.Samples.Sample(track.ID); ok && player.Output != nil {
+			player.Output.Play(sample)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+type recordingOutput struct {
+	played chan *Sample
+}
+
+func (output *recordingOutput) Play(sample *Sample) {
+	output.played <- sample
+}
+
+func TestPlayer(t *testing.T) {
+	pattern := &Pattern{Version: "0.808-alpha", Tempo: 999, Tracks: []*Track{
+		&Track{ID: 0, Name: "kick", Steps: [StepCount]bool{0: true, 8: true}},
+		&Track{ID: 1, Name: "snare", Steps: [StepCount]bool{4: true, 12: true}},
+	}}
+	kick := &Sample{SampleRate: 44100, Channels: 1, Data: []float32{1}}
+
+	steps := make(chan int, StepCount)
+	output := &recordingOutput{played: make(chan *Sample, StepCount)}
+	player := NewPlayer(pattern)
+	player.Samples.Add(0, kick)
+	player.Output = output
+	player.OnStep = func(step int) {
+		steps <- step
+	}
+	var triggered []string
+	player.OnTrigger = func(step int, track *Track) {
+		triggered = append(triggered, track.Name)
+	}
+
+	if err := player.Start(); err != nil {
+		t.Fatalf("something went wrong starting - %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if step := <-steps; step != i {
+			t.Fatalf("expected step %d, got %d", i, step)
+		}
+	}
+	player.Pause()
+	if player.Playing() {
+		t.Fatalf("expected player to be paused")
+	}
+	if step := player.Step(); step != 10 {
+		t.Fatalf("expected to be paused at step 10, got %d", step)
+	}
+
+	expected := []string{"kick", "snare", "kick"}
+	if len(triggered) != len(expected) {
+		t.Fatalf("expected triggers %v, got %v", expected, triggered)
+	}
+	for i := range expected {
+		if triggered[i] != expected[i] {
+			t.Fatalf("expected triggers %v, got %v", expected, triggered)
+		}
+	}
+	// Only the kick has a sample
+	if len(output.played) != 2 {
+		t.Fatalf("expected 2 played samples, got %d", len(output.played))
+	}
+
+	if err := player.Start(); err != nil {
+		t.Fatalf("something went wrong resuming - %v", err)
+	}
+	if step := <-steps; step != 10 {
+		t.Fatalf("expected to resume at step 10, got %d", step)
+	}
+	player.Stop()
+	if step := player.Step(); step != 0 {
+		t.Fatalf("expected to be rewound to step 0, got %d", step)
+	}
+}
+
+func TestPlayerInvalidTempo(t *testing.T) {
+	player := NewPlayer(&Pattern{Tempo: 0})
+	if err := player.Start(); !errors.Is(err, ErrInvalidTempo) {
+		t.Fatalf("expected ErrInvalidTempo, got %v", err)
+	}
+	if player.Playing() {
+		t.Fatalf("expected player not to be playing")
+	}
+}
+
+func TestDecodeWAV(t *testing.T) {
+	samples := []int16{0, 16384, -32768}
+	data := new(bytes.Buffer)
+	binary.Write(data, binary.LittleEndian, samples)
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(4+8+16+8+data.Len()))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(buf, binary.LittleEndian, struct {
+		Size                      uint32
+		AudioFormat, Channels     uint16
+		SampleRate, ByteRate      uint32
+		BlockAlign, BitsPerSample uint16
+	}{16, 1, 1, 22050, 44100, 2, 16})
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint32(data.Len()))
+	buf.Write(data.Bytes())
+
+	sample, err := DecodeWAV(buf)
+	if err != nil {
+		t.Fatalf("something went wrong decoding - %v", err)
+	}
+	if sample.SampleRate != 22050 || sample.Channels != 1 {
+		t.Fatalf("expected 22050 Hz mono, got %d Hz with %d channels", sample.SampleRate, sample.Channels)
+	}
+	expected := []float32{0, 0.5, -1}
+	if len(sample.Data) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, sample.Data)
+	}
+	for i := range expected {
+		if sample.Data[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, sample.Data)
+		}
+	}
+
+	if _, err := DecodeWAV(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00AVI "))); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("expected ErrInvalidFormat, got %v", err)
+	}
+}
+
+func TestDecodeWAVChunkSize(t *testing.T) {
+	inputs := []string{
+		"RIFF\x0c\x00\x00\x00WAVELIST\xff\xff\xff\xff",
+		"RIFF\x0c\x00\x00\x00WAVEfmt \xff\xff\xff\xff",
+		"RIFF\x0c\x00\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00\x22\x56\x00\x00\x44\xac\x00\x00\x02\x00\x10\x00data\xff\xff\xff\xff",
+	}
+	for _, input := range inputs {
+		if _, err := DecodeWAV(bytes.NewReader([]byte(input))); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("expected io.ErrUnexpectedEOF for %q, got %v", input, err)
+		}
+	}
+}
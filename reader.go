@@ -0,0 +1,53 @@
+package drum
+
+import (
+	"bytes"
+	"io"
+)
+
+// PatternReader reads the binary .splice representation of a pattern.
+// The pattern is validated and encoded into memory on the first call to Read
+// or Seek, later changes to the pattern are not seen by the reader. Read and
+// Seek return the error of MarshalBinary if the pattern can't be encoded, so
+// unlike Bytes and WriteTo, which skip Validate, the reader rejects invalid
+// patterns such as one without a positive tempo. NewPatternReader itself
+// never fails.
+type PatternReader struct {
+	pattern *Pattern
+	reader  *bytes.Reader
+	err     error
+}
+
+// NewPatternReader returns a PatternReader for the given pattern
+func NewPatternReader(p *Pattern) *PatternReader {
+	return &PatternReader{pattern: p}
+}
+
+func (r *PatternReader) init() error {
+	if r.reader == nil && r.err == nil {
+		var data []byte
+		if data, r.err = r.pattern.MarshalBinary(); r.err == nil {
+			r.reader = bytes.NewReader(data)
+		}
+	}
+
+	return r.err
+}
+
+// Read implements io.Reader
+func (r *PatternReader) Read(p []byte) (int, error) {
+	if err := r.init(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+// Seek implements io.Seeker
+func (r *PatternReader) Seek(offset int64, whence int) (int64, error) {
+	if err := r.init(); err != nil {
+		return 0, err
+	}
+	return r.reader.Seek(offset, whence)
+}
+
+var _ io.ReadSeeker = (*PatternReader)(nil)
@@ -0,0 +1,57 @@
+package drum
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestPatternReader(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding - %v", err)
+	}
+
+	data, err := io.ReadAll(NewPatternReader(pattern))
+	if err != nil {
+		t.Fatalf("something went wrong reading - %v", err)
+	}
+	if !bytes.Equal(data, pattern.Bytes()) {
+		t.Fatalf("read data doesn't match the encoded pattern")
+	}
+
+	r := NewPatternReader(pattern)
+	if _, err := r.Seek(6, io.SeekStart); err != nil {
+		t.Fatalf("something went wrong seeking - %v", err)
+	}
+	data, _ = io.ReadAll(r)
+	if !bytes.Equal(data, pattern.Bytes()[6:]) {
+		t.Fatalf("read data after seek doesn't match the encoded pattern")
+	}
+}
+
+func TestPatternReaderInvalid(t *testing.T) {
+	pattern := testPattern()
+	pattern.Version = strings.Repeat("v", 33)
+
+	r := NewPatternReader(pattern)
+	if _, err := io.ReadAll(r); !errors.Is(err, ErrVersionTooLong) {
+		t.Fatalf("expected ErrVersionTooLong, got %v", err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); !errors.Is(err, ErrVersionTooLong) {
+		t.Fatalf("expected ErrVersionTooLong seeking, got %v", err)
+	}
+
+	// Bytes encodes without validating, the reader validates
+	pattern = testPattern()
+	pattern.Tempo = 0
+	if len(pattern.Bytes()) == 0 {
+		t.Fatalf("expected Bytes to encode a pattern without a tempo")
+	}
+	if _, err := io.ReadAll(NewPatternReader(pattern)); !errors.Is(err, ErrInvalidTempo) {
+		t.Fatalf("expected ErrInvalidTempo, got %v", err)
+	}
+}
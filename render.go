@@ -0,0 +1,201 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+)
+
+// RenderSampleRate is the sample rate of rendered patterns
+const RenderSampleRate = 44100
+
+// RenderOptions configures Render
+type RenderOptions struct {
+	// Loops is the number of times the pattern is played, once if 0
+	Loops int
+	// Gain is the gain of the tracks by ID, 1 for tracks which aren't in it
+	Gain map[int]float32
+	// Tail renders the samples triggered at the end of the last loop
+	// completely, instead of cutting them off at the end of the loop
+	Tail bool
+}
+
+// Render mixes the samples of the tracks in bank according to the steps and
+// tempo of the pattern, without a real time audio device. The result is a
+// 16 bit stereo WAV file at RenderSampleRate. Samples are scaled by the gain
+// of their track and the velocity of the step. Tracks without a sample are
+// silent.
+func Render(pattern *Pattern, bank *SampleBank, opts RenderOptions) (io.Reader, error) {
+	mix, err := renderMix(pattern, bank, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := EncodeWAV(buf, mix); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// RenderFile renders the pattern like Render and writes the WAV file to
+// path.
+func RenderFile(path string, pattern *Pattern, bank *SampleBank, opts RenderOptions) error {
+	r, err := Render(pattern, bank, opts)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// renderMix mixes the samples of the pattern into a stereo sample
+func renderMix(pattern *Pattern, bank *SampleBank, opts RenderOptions) (*Sample, error) {
+	if pattern == nil {
+		return nil, ErrNilPattern
+	}
+	if err := validatePlaybackTempo(pattern.Tempo); err != nil {
+		return nil, err
+	}
+	if bank == nil {
+		bank = NewSampleBank()
+	}
+	loops := opts.Loops
+	if loops < 1 {
+		loops = 1
+	}
+
+	steps := pattern.Length()
+	stepFrames := pattern.BarDuration().Seconds() / StepCount * RenderSampleRate
+	mix := make([]float32, 2*int(math.Round(float64(loops*steps)*stepFrames)))
+	resampled := make(map[*Sample][]float32)
+	for n := 0; n < loops*steps; n++ {
+		start := 2 * int(math.Round(float64(n)*stepFrames))
+		for _, track := range pattern.Tracks {
+			step, err := track.Step(n % steps)
+			if err != nil || !step.On {
+				continue
+			}
+			sample, ok := bank.Sample(track.ID)
+			if !ok {
+				continue
+			}
+			if _, ok := resampled[sample]; !ok {
+				resampled[sample] = renderStereo(sample)
+			}
+			data := resampled[sample]
+
+			gain := float32(1)
+			if g, ok := opts.Gain[track.ID]; ok {
+				gain = g
+			}
+			gain *= float32(step.Velocity) / DefaultVelocity
+
+			if end := start + len(data); opts.Tail && end > len(mix) {
+				mix = append(mix, make([]float32, end-len(mix))...)
+			}
+			for i, v := range data {
+				if start+i >= len(mix) {
+					break
+				}
+				mix[start+i] += v * gain
+			}
+		}
+	}
+
+	return &Sample{SampleRate: RenderSampleRate, Channels: 2, Data: mix}, nil
+}
+
+// renderStereo converts the sample to interleaved stereo at RenderSampleRate
+// with linear interpolation. Mono samples are played on both channels, only
+// the first two channels of other samples are used.
+func renderStereo(sample *Sample) []float32 {
+	channels := sample.Channels
+	if channels < 1 {
+		channels = 1
+	}
+	frames := len(sample.Data) / channels
+	if frames == 0 || sample.SampleRate <= 0 {
+		return nil
+	}
+
+	ratio := float64(sample.SampleRate) / RenderSampleRate
+	stereo := make([]float32, 2*int(float64(frames)/ratio))
+	for i := 0; i < len(stereo)/2; i++ {
+		pos := float64(i) * ratio
+		j := int(pos)
+		frac := float32(pos - float64(j))
+		for c := 0; c < 2; c++ {
+			channel := c
+			if channel >= channels {
+				channel = channels - 1
+			}
+			a := sample.Data[j*channels+channel]
+			b := a
+			if j+1 < frames {
+				b = sample.Data[(j+1)*channels+channel]
+			}
+			stereo[2*i+c] = a + (b-a)*frac
+		}
+	}
+
+	return stereo
+}
+
+// EncodeWAV writes the sample as a 16 bit PCM WAV file. Values outside -1 to
+// 1 are clipped.
+func EncodeWAV(w io.Writer, sample *Sample) error {
+	data := make([]int16, len(sample.Data))
+	for i, v := range sample.Data {
+		data[i] = int16(math.Round(math.Max(-1, math.Min(1, float64(v))) * math.MaxInt16))
+	}
+
+	size := uint32(2 * len(data))
+	header := struct {
+		RIFF          [4]byte
+		Size          uint32
+		WAVE          [4]byte
+		Fmt           [4]byte
+		FmtSize       uint32
+		AudioFormat   uint16
+		Channels      uint16
+		SampleRate    uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+		Data          [4]byte
+		DataSize      uint32
+	}{
+		RIFF:          [4]byte{'R', 'I', 'F', 'F'},
+		Size:          36 + size,
+		WAVE:          [4]byte{'W', 'A', 'V', 'E'},
+		Fmt:           [4]byte{'f', 'm', 't', ' '},
+		FmtSize:       16,
+		AudioFormat:   1,
+		Channels:      uint16(sample.Channels),
+		SampleRate:    uint32(sample.SampleRate),
+		ByteRate:      uint32(sample.SampleRate * sample.Channels * 2),
+		BlockAlign:    uint16(sample.Channels * 2),
+		BitsPerSample: 16,
+		Data:          [4]byte{'d', 'a', 't', 'a'},
+		DataSize:      size,
+	}
+
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, header)
+	binary.Write(buf, binary.LittleEndian, data)
+	_, err := buf.WriteTo(w)
+
+	return err
+}
@@ -0,0 +1,121 @@
+package drum
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"path"
+	"testing"
+)
+
+func TestEncodeWAV(t *testing.T) {
+	sample := &Sample{SampleRate: 22050, Channels: 2, Data: []float32{0, 0.5, -0.5, 1, 2, -2}}
+	buf := new(bytes.Buffer)
+	if err := EncodeWAV(buf, sample); err != nil {
+		t.Fatalf("something went wrong encoding - %v", err)
+	}
+
+	decoded, err := DecodeWAV(buf)
+	if err != nil {
+		t.Fatalf("something went wrong decoding - %v", err)
+	}
+	expected := []float32{0, 0.5, -0.5, 1, 1, -1}
+	if decoded.SampleRate != 22050 || decoded.Channels != 2 || len(decoded.Data) != len(expected) {
+		t.Fatalf("expected %d values at 22050 Hz in stereo, got %+v", len(expected), decoded)
+	}
+	for i, v := range expected {
+		if math.Abs(float64(decoded.Data[i]-v)) > 1.0/16384 {
+			t.Fatalf("expected %v, got %v", expected, decoded.Data)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	// At 120 BPM a step is 5512.5 frames
+	pattern := testPattern()
+	bank := NewSampleBank()
+	bank.Add(0, &Sample{SampleRate: RenderSampleRate, Channels: 1, Data: []float32{1}})
+	bank.Add(3, &Sample{SampleRate: RenderSampleRate / 2, Channels: 2, Data: []float32{0.2, -0.2, 0.4, -0.4}})
+
+	r, err := Render(pattern, bank, RenderOptions{Loops: 2, Gain: map[int]float32{0: 0.5}})
+	if err != nil {
+		t.Fatalf("something went wrong rendering - %v", err)
+	}
+	rendered, err := DecodeWAV(r)
+	if err != nil {
+		t.Fatalf("something went wrong decoding the rendering - %v", err)
+	}
+	if rendered.SampleRate != RenderSampleRate || rendered.Channels != 2 || len(rendered.Data) != 2*2*16*5512.5 {
+		t.Fatalf("expected two bars of stereo at %d Hz, got %d values", RenderSampleRate, len(rendered.Data))
+	}
+
+	frame := func(step float64, offset int) [2]float32 {
+		i := 2 * (int(math.Round(step*5512.5)) + offset)
+		return [2]float32{rendered.Data[i], rendered.Data[i+1]}
+	}
+	near := func(got [2]float32, left, right float32) bool {
+		return math.Abs(float64(got[0]-left)) < 0.001 && math.Abs(float64(got[1]-right)) < 0.001
+	}
+	tData := []struct {
+		step        float64
+		offset      int
+		left, right float32
+	}{
+		// The kick on steps 0, 4, 8 and 12 at half gain, the snare on step 4
+		// has no sample
+		{0, 0, 0.5, 0.5},
+		{0, 1, 0, 0},
+		{20, 0, 0.5, 0.5},
+		// Mixed with the hi-hat on step 8
+		{24, 0, 0.7, 0.3},
+		// The resampled hi-hat
+		{2, 0, 0.2, -0.2},
+		{2, 1, 0.3, -0.3},
+		{18, 2, 0.4, -0.4},
+		{18, 4, 0, 0},
+		// Nothing is played on step 1
+		{1, 0, 0, 0},
+	}
+	for _, exp := range tData {
+		if got := frame(exp.step, exp.offset); !near(got, exp.left, exp.right) {
+			t.Fatalf("step %g frame %d: expected %g %g, got %v", exp.step, exp.offset, exp.left, exp.right, got)
+		}
+	}
+}
+
+func TestRenderTail(t *testing.T) {
+	pattern := testPattern()
+	bank := NewSampleBank()
+	// A second long sample on the last step of the hi-hat
+	bank.Add(3, &Sample{SampleRate: RenderSampleRate, Channels: 1, Data: make([]float32, RenderSampleRate)})
+	pattern.Tracks[2].SetStep(15, true)
+
+	for _, tail := range []bool{false, true} {
+		name := path.Join(t.TempDir(), "bounce.wav")
+		if err := RenderFile(name, pattern, bank, RenderOptions{Tail: tail}); err != nil {
+			t.Fatalf("something went wrong rendering - %v", err)
+		}
+		rendered := NewSampleBank()
+		if err := rendered.LoadWAV(0, name); err != nil {
+			t.Fatalf("something went wrong loading the rendering - %v", err)
+		}
+		sample, _ := rendered.Sample(0)
+
+		frames := int(math.Round(16 * 5512.5))
+		if tail {
+			frames = int(math.Round(15*5512.5)) + RenderSampleRate
+		}
+		if len(sample.Data) != 2*frames {
+			t.Fatalf("tail %v: expected %d frames, got %d", tail, frames, len(sample.Data)/2)
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	if _, err := Render(nil, nil, RenderOptions{}); !errors.Is(err, ErrNilPattern) {
+		t.Fatalf("expected ErrNilPattern, got %v", err)
+	}
+	if _, err := Render(&Pattern{}, nil, RenderOptions{}); !errors.Is(err, ErrInvalidTempo) {
+		t.Fatalf("expected ErrInvalidTempo, got %v", err)
+	}
+}
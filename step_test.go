@@ -0,0 +1,105 @@
+package drum
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"testing"
+)
+
+func TestStep(t *testing.T) {
+	pattern, err := DecodeFile(path.Join("fixtures", "pattern_1.splice"))
+	if err != nil {
+		t.Fatalf("something went wrong decoding %v", err)
+	}
+	if pattern.Extended() {
+		t.Fatalf("expected a classic pattern")
+	}
+
+	kick := pattern.Tracks[0]
+	tData := []struct {
+		i    int
+		step Step
+	}{
+		{0, Step{On: true, Velocity: 127}},
+		{1, Step{On: false, Velocity: 127}},
+	}
+	for _, exp := range tData {
+		step, err := kick.Step(exp.i)
+		if err != nil {
+			t.Fatalf("something went wrong getting step %d - %v", exp.i, err)
+		}
+		if step != exp.step {
+			t.Fatalf("step %d: expected %+v, got %+v", exp.i, exp.step, step)
+		}
+	}
+	if _, err := kick.Step(16); !errors.Is(err, ErrStepOutOfRange) {
+		t.Fatalf("expected ErrStepOutOfRange, got %v", err)
+	}
+
+	if err := kick.SetStepValue(1, Step{On: true, Velocity: 64, Probability: 0.6}); err != nil {
+		t.Fatalf("something went wrong setting step 1 - %v", err)
+	}
+	if step, _ := kick.Step(1); step != (Step{On: true, Velocity: 64, Probability: 0.6}) {
+		t.Fatalf("unexpected step 1 %+v", step)
+	}
+	if err := kick.SetStepValue(1, Step{On: true, Velocity: 128}); !errors.Is(err, ErrInvalidVelocity) {
+		t.Fatalf("expected ErrInvalidVelocity, got %v", err)
+	}
+	if err := kick.SetStepValue(1, Step{On: true, Probability: 1.5}); !errors.Is(err, ErrInvalidProbability) {
+		t.Fatalf("expected ErrInvalidProbability, got %v", err)
+	}
+}
+
+func TestExtendedFormat(t *testing.T) {
+	pattern := testPattern()
+	pattern.Version = "0.909" + ExtendedVersionSuffix
+	kick := pattern.Tracks[0]
+	kick.SetStepValue(0, Step{On: true, Velocity: 100, Probability: 1})
+	kick.SetStepValue(2, Step{On: false, Probability: 0.6})
+
+	data := pattern.Bytes()
+	// Two bytes per step instead of one
+	if len(data) != len(testPattern().Bytes())+4*StepCount {
+		t.Fatalf("unexpected size %d", len(data))
+	}
+
+	decoded, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("something went wrong decoding - %v", err)
+	}
+	if !decoded.Extended() || decoded.String() != pattern.String() {
+		t.Fatalf("expected:\n%s\ngot:\n%s", pattern, decoded)
+	}
+	expected := []Step{
+		{On: true, Velocity: 100, Probability: 1},
+		{On: false, Velocity: 127},
+		{On: false, Velocity: 127, Probability: 0.6},
+		{On: false, Velocity: 127},
+		{On: true, Velocity: 127},
+	}
+	for i, exp := range expected {
+		if step, _ := decoded.Tracks[0].Step(i); step != exp {
+			t.Fatalf("step %d: expected %+v, got %+v", i, exp, step)
+		}
+	}
+
+	strict, err := NewDecoder(bytes.NewReader(data), DecoderOptions{Strict: true}).Decode()
+	if err != nil || strict.String() != pattern.String() {
+		t.Fatalf("unexpected result decoding with a Decoder:\n%s - %v", strict, err)
+	}
+
+	pattern.Tracks[1].Velocities[3] = 200
+	if err := pattern.Validate(); !errors.Is(err, ErrInvalidVelocity) {
+		t.Fatalf("expected ErrInvalidVelocity, got %v", err)
+	}
+
+	// Classic tracks in an extended file don't fill the content
+	classic := testPattern()
+	classic.Version = "0.909"
+	data = classic.Bytes()
+	copy(data[14:], "0.909-ext")
+	if _, err := Decode(bytes.NewReader(data)); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("expected ErrInvalidFormat, got %v", err)
+	}
+}
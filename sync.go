@@ -0,0 +1,222 @@
+package drum
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"time"
+)
+
+// ClockEventType is the type of a ClockEvent
+type ClockEventType int
+
+// Types of clock events
+const (
+	// ClockStart starts playback from the first step
+	ClockStart ClockEventType = iota
+	// ClockContinue resumes playback from the current step
+	ClockContinue
+	// ClockStop stops playback, keeping the current step
+	ClockStop
+	// ClockStep plays the current step and advances to the next one
+	ClockStep
+	// ClockPosition moves to ClockEvent.Step
+	ClockPosition
+	// ClockTempo reports the tempo of the source in ClockEvent.Tempo
+	ClockTempo
+)
+
+// ClockEvent is sent by a ClockSource to control a synced Player
+type ClockEvent struct {
+	Type  ClockEventType
+	Step  int
+	Tempo float32
+}
+
+// ClockSource is an external clock a Player can follow with SyncTo. The
+// source closes the channel when it has no more events.
+type ClockSource interface {
+	Events() <-chan ClockEvent
+}
+
+// syncState is the goroutine following a clock source
+type syncState struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// SyncTo makes the player follow the events of source instead of its own
+// clock, stopping internal playback. The player waits for a ClockStart or
+// ClockContinue event before playing steps. SyncTo(nil) ends the sync,
+// after which the player can be started again.
+func (player *Player) SyncTo(source ClockSource) {
+	player.Pause()
+
+	player.mu.Lock()
+	previous := player.sync
+	player.sync = nil
+	player.mu.Unlock()
+	if previous != nil {
+		close(previous.stop)
+		<-previous.done
+	}
+	if source == nil {
+		return
+	}
+
+	state := &syncState{stop: make(chan struct{}), done: make(chan struct{})}
+	player.mu.Lock()
+	player.sync = state
+	player.mu.Unlock()
+	go player.follow(source.Events(), state)
+}
+
+// follow handles the events until the source is done or the sync is ended
+func (player *Player) follow(events <-chan ClockEvent, state *syncState) {
+	defer close(state.done)
+
+	for {
+		var event ClockEvent
+		select {
+		case <-state.stop:
+			return
+		case e, ok := <-events:
+			if !ok {
+				player.mu.Lock()
+				player.running = false
+				player.mu.Unlock()
+				return
+			}
+			event = e
+		}
+
+		player.mu.Lock()
+		running := player.running
+		switch event.Type {
+		case ClockStart:
+			player.step = 0
+			player.running = true
+		case ClockContinue:
+			player.running = true
+		case ClockStop:
+			player.running = false
+		case ClockPosition:
+			length := player.pattern.Length()
+			player.step = (event.Step%length + length) % length
+		}
+		player.mu.Unlock()
+
+		switch {
+		case event.Type == ClockStep && running:
+			player.playStep()
+		case event.Type == ClockTempo && player.OnTempo != nil:
+			player.OnTempo(event.Tempo)
+		}
+	}
+}
+
+// MIDI clock messages
+const (
+	midiClock          = 0xF8
+	midiStart          = 0xFA
+	midiContinue       = 0xFB
+	midiStop           = 0xFC
+	midiSongPosition   = 0xF2
+	midiPulsesPerBeat  = 24
+	midiPulsesPerStep  = midiPulsesPerBeat * 4 / StepCount
+	midiRealtimeStatus = 0xF8
+)
+
+// MIDIClock is a ClockSource reading MIDI clock messages from a stream, like
+// a raw MIDI device. A step is played every 6 of the 24 clock pulses per beat
+// and the tempo is measured every beat. Other MIDI messages are ignored.
+type MIDIClock struct {
+	events chan ClockEvent
+	err    error
+}
+
+// NewMIDIClock starts reading MIDI messages from r until it returns an error
+func NewMIDIClock(r io.Reader) *MIDIClock {
+	return newMIDIClock(r, time.Now)
+}
+
+// newMIDIClock creates a MIDIClock measuring the tempo with now
+func newMIDIClock(r io.Reader, now func() time.Time) *MIDIClock {
+	clock := &MIDIClock{events: make(chan ClockEvent, midiPulsesPerBeat)}
+	go clock.read(bufio.NewReader(r), now)
+
+	return clock
+}
+
+// Events implements ClockSource. The channel is closed when the stream ends.
+func (clock *MIDIClock) Events() <-chan ClockEvent {
+	return clock.events
+}
+
+// Err returns the error which ended the stream, nil for io.EOF. It is only
+// set once the events are closed.
+func (clock *MIDIClock) Err() error {
+	return clock.err
+}
+
+// read parses the stream and sends its events
+func (clock *MIDIClock) read(r io.ByteReader, now func() time.Time) {
+	defer close(clock.events)
+
+	var pulse int
+	var beat time.Time
+	var tempo float32
+	// position holds the data bytes of a song position pointer
+	var position []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err != io.EOF {
+				clock.err = err
+			}
+			return
+		}
+
+		switch {
+		case b == midiClock:
+			if pulse%midiPulsesPerStep == 0 {
+				clock.events <- ClockEvent{Type: ClockStep}
+			}
+			if pulse%midiPulsesPerBeat == 0 {
+				t := now()
+				if !beat.IsZero() {
+					bpm := float32(math.Round(60/t.Sub(beat).Seconds()*10) / 10)
+					if bpm != tempo {
+						tempo = bpm
+						clock.events <- ClockEvent{Type: ClockTempo, Tempo: bpm}
+					}
+				}
+				beat = t
+			}
+			pulse++
+		case b == midiStart:
+			pulse, beat = 0, time.Time{}
+			clock.events <- ClockEvent{Type: ClockStart}
+		case b == midiContinue:
+			beat = time.Time{}
+			clock.events <- ClockEvent{Type: ClockContinue}
+		case b == midiStop:
+			clock.events <- ClockEvent{Type: ClockStop}
+		case b >= midiRealtimeStatus:
+			// Other real time messages can be sent in between other messages
+		case b == midiSongPosition:
+			position = []byte{}
+		case b&0x80 != 0:
+			position = nil
+		case position != nil:
+			position = append(position, b)
+			if len(position) == 2 {
+				// The position counts sixteenth notes, which are steps
+				step := int(position[0]) | int(position[1])<<7
+				pulse, beat = step*midiPulsesPerStep, time.Time{}
+				position = nil
+				clock.events <- ClockEvent{Type: ClockPosition, Step: step}
+			}
+		}
+	}
+}
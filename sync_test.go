@@ -0,0 +1,115 @@
+package drum
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testClock chan ClockEvent
+
+func (clock testClock) Events() <-chan ClockEvent {
+	return clock
+}
+
+func TestPlayerSyncTo(t *testing.T) {
+	steps := make(chan int, StepCount)
+	tempos := make(chan float32, 1)
+	player := NewPlayer(testPattern())
+	player.OnStep = func(step int) {
+		steps <- step
+	}
+	player.OnTempo = func(bpm float32) {
+		tempos <- bpm
+	}
+
+	clock := make(testClock)
+	player.SyncTo(clock)
+	if err := player.Start(); !errors.Is(err, ErrPlayerSynced) {
+		t.Fatalf("expected ErrPlayerSynced, got %v", err)
+	}
+
+	// Steps are ignored until the clock starts the player
+	clock <- ClockEvent{Type: ClockStep}
+	clock <- ClockEvent{Type: ClockStart}
+	for i := 0; i < 3; i++ {
+		clock <- ClockEvent{Type: ClockStep}
+	}
+	clock <- ClockEvent{Type: ClockPosition, Step: 24}
+	clock <- ClockEvent{Type: ClockStep}
+	clock <- ClockEvent{Type: ClockStop}
+	clock <- ClockEvent{Type: ClockStep}
+	clock <- ClockEvent{Type: ClockTempo, Tempo: 98.5}
+
+	if bpm := <-tempos; bpm != 98.5 {
+		t.Fatalf("expected tempo 98.5, got %g", bpm)
+	}
+	expected := []int{0, 1, 2, 8}
+	if len(steps) != len(expected) {
+		t.Fatalf("expected steps %v, got %d steps", expected, len(steps))
+	}
+	for _, exp := range expected {
+		if step := <-steps; step != exp {
+			t.Fatalf("expected step %d, got %d", exp, step)
+		}
+	}
+	if player.Playing() {
+		t.Fatalf("expected player to be stopped by the clock")
+	}
+
+	clock <- ClockEvent{Type: ClockContinue}
+	clock <- ClockEvent{Type: ClockStep}
+	if step := <-steps; step != 9 {
+		t.Fatalf("expected to continue at step 9, got %d", step)
+	}
+	if !player.Playing() {
+		t.Fatalf("expected player to be playing")
+	}
+
+	player.SyncTo(nil)
+	if err := player.Start(); err != nil {
+		t.Fatalf("something went wrong starting after the sync - %v", err)
+	}
+	player.Stop()
+}
+
+func TestMIDIClock(t *testing.T) {
+	var data []byte
+	data = append(data, midiStart)
+	// Two beats and the first pulse of the third
+	data = append(data, bytes.Repeat([]byte{midiClock}, 2*midiPulsesPerBeat+1)...)
+	// A note on, a song position pointer to step 8 and stop
+	data = append(data, 0x99, 0x24, 0x7F, midiSongPosition, 0x08, 0x00, midiStop)
+
+	beat := time.Now()
+	clock := newMIDIClock(bytes.NewReader(data), func() time.Time {
+		beat = beat.Add(500 * time.Millisecond)
+		return beat
+	})
+
+	var events []ClockEvent
+	for event := range clock.Events() {
+		events = append(events, event)
+	}
+	if err := clock.Err(); err != nil {
+		t.Fatalf("something went wrong reading - %v", err)
+	}
+
+	expected := []ClockEvent{{Type: ClockStart}}
+	for i := 0; i < 9; i++ {
+		expected = append(expected, ClockEvent{Type: ClockStep})
+		if i == 4 {
+			expected = append(expected, ClockEvent{Type: ClockTempo, Tempo: 120})
+		}
+	}
+	expected = append(expected, ClockEvent{Type: ClockPosition, Step: 8}, ClockEvent{Type: ClockStop})
+	if len(events) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Fatalf("expected events %v, got %v", expected, events)
+		}
+	}
+}
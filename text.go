@@ -0,0 +1,113 @@
+package drum
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ParseText parses a pattern written as text, like the output of String:
+//
+//	# Lines starting with # are comments
+//	version: 0.808-alpha
+//	tempo: 120
+//	kick	x---|x---|x---|x---
+//	(7) hh open	x-x-x-x-x-x-x-x-
+//
+// "Saved with HW Version:" can be used instead of "version:". A track line is
+// an optional (ID), the name and the steps, as in Track steps can be
+// separated by | and spaces. Tracks without an ID get the lowest ID which is
+// not used by the tracks before them. The pattern is validated.
+func ParseText(r io.Reader) (*Pattern, error) {
+	builder := NewPattern()
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		if err := parseTextLine(builder, strings.TrimSpace(scanner.Text())); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrSyntax, n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return builder.Build()
+}
+
+// ParseTextFile parses the text pattern in the file at path, see ParseText
+func ParseTextFile(path string) (*Pattern, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseText(f)
+}
+
+// parseTextLine adds a line of a text pattern to builder
+func parseTextLine(builder *PatternBuilder, line string) error {
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+
+	if key, value, ok := strings.Cut(line, ":"); ok {
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "version", "saved with hw version":
+			if err := validateVersion(value); err != nil {
+				return err
+			}
+			builder.WithVersion(value)
+			return nil
+		case "tempo":
+			tempo, err := strconv.ParseFloat(value, 32)
+			if err != nil {
+				return fmt.Errorf("invalid tempo %q", value)
+			}
+			builder.WithTempo(float32(tempo))
+			return nil
+		}
+	}
+
+	fields := strings.Fields(line)
+	id := builder.nextID()
+	if field := fields[0]; strings.HasPrefix(field, "(") && strings.HasSuffix(field, ")") {
+		n, err := strconv.Atoi(field[1 : len(field)-1])
+		if err != nil {
+			return fmt.Errorf("invalid track ID %q", field)
+		}
+		id = n
+		fields = fields[1:]
+	}
+
+	// The steps are the longest run of step fields at the end, the fields
+	// before them are the name
+	start := len(fields)
+	for start > 1 {
+		if _, err := parseStepSlice(fields[start-1]); err != nil {
+			break
+		}
+		start--
+	}
+	if start == len(fields) {
+		return fmt.Errorf("expected a track name and steps in %q", line)
+	}
+	name, steps := strings.Join(fields[:start], " "), strings.Join(fields[start:], "")
+
+	track := &Track{ID: id, Name: name}
+	if err := track.setStepString(steps); err != nil {
+		return err
+	}
+
+	for _, existing := range builder.tracks {
+		if existing.ID == id {
+			return fmt.Errorf("%w: %d", ErrDuplicateTrackID, id)
+		}
+	}
+	builder.AddTrack(track)
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package drum
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseText(t *testing.T) {
+	for name, pattern := range fixtures(t) {
+		parsed, err := ParseText(strings.NewReader(pattern.String()))
+		if err != nil {
+			t.Fatalf("something went wrong parsing %s - %v", name, err)
+		}
+		if !Equal(pattern, parsed) {
+			t.Fatalf("%s: parsed pattern differs:\n%s", name, Diff(pattern, parsed))
+		}
+	}
+
+	text := `# A long pattern
+version: 0.909
+Tempo: 98.5
+
+kick     x---x---x---x---x---x---x---x---
+(7) hh open  x-x- x-x- x-x- x-x- x-x- x-x- x-x- x-x-
+snare    ----|x---|----|x---|----|x---|----|x---
+`
+	pattern, err := ParseText(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("something went wrong parsing - %v", err)
+	}
+	if pattern.Version != "0.909" || pattern.Tempo != 98.5 || pattern.Length() != 32 {
+		t.Fatalf("expected version 0.909, tempo 98.5 and 32 steps, got:\n%s", pattern)
+	}
+	expected := []struct {
+		id    int
+		name  string
+		steps int
+	}{{0, "kick", 8}, {7, "hh open", 16}, {1, "snare", 4}}
+	for i, exp := range expected {
+		track := pattern.Tracks[i]
+		if track.ID != exp.id || track.Name != exp.name || track.ActiveStepCount() != exp.steps {
+			t.Fatalf("expected track (%d) %s with %d active steps, got %s", exp.id, exp.name, exp.steps, track)
+		}
+	}
+
+	tData := []struct {
+		text string
+		err  error
+		line string
+	}{
+		{"tempo: 120\nkick\n", ErrSyntax, "line 2"},
+		{"tempo: fast\n", ErrSyntax, "line 1"},
+		{"tempo: 120\n(x) kick x---x---x---x---\n", ErrSyntax, "line 2"},
+		{"tempo: 120\nkick x---x---\n", ErrInvalidStepCount, "line 2"},
+		{"tempo: 120\n(1) kick x---x---x---x---\n(1) snare ----x-------x---\n", ErrDuplicateTrackID, "line 3"},
+		{"kick x---x---x---x---\n", ErrInvalidTempo, ""},
+	}
+	for _, exp := range tData {
+		_, err := ParseText(strings.NewReader(exp.text))
+		if !errors.Is(err, exp.err) || !strings.Contains(err.Error(), exp.line) {
+			t.Fatalf("%q: expected %v on %s, got %v", exp.text, exp.err, exp.line, err)
+		}
+	}
+}
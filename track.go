@@ -0,0 +1,526 @@
+package drum
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+)
+
+// NewTrack creates a track with the given ID, name and steps and validates
+// the result.
+func NewTrack(id int, name string, steps [16]bool) (*Track, error) {
+	track := &Track{
+		ID:    id,
+		Name:  name,
+		Steps: steps,
+	}
+
+	if err := track.Validate(); err != nil {
+		return nil, err
+	}
+
+	return track, nil
+}
+
+// Validate checks if the track can be encoded into a .splice file.
+func (track *Track) Validate() error {
+	if track.ID < 0 || track.ID > math.MaxInt32 {
+		return ErrInvalidTrackID
+	}
+	if len(track.Name) == 0 {
+		return ErrEmptyTrackName
+	}
+	if len(track.Name) > math.MaxInt8 {
+		return ErrTrackNameTooLong
+	}
+	if !supportedStepCount(track.Length()) {
+		return fmt.Errorf("%w: %d", ErrInvalidStepCount, track.Length())
+	}
+
+	return nil
+}
+
+// Clone returns a copy of the track
+func (track *Track) Clone() *Track {
+	clone := *track
+	clone.ExtraSteps = append([]bool(nil), track.ExtraSteps...)
+	return &clone
+}
+
+// Length returns the number of steps of the track
+func (track *Track) Length() int {
+	return StepCount + len(track.ExtraSteps)
+}
+
+// AllSteps returns a copy of all steps of the track, including the extra
+// steps of patterns longer than StepCount steps.
+func (track *Track) AllSteps() []bool {
+	return append(track.Steps[:], track.ExtraSteps...)
+}
+
+// SetLength changes the number of steps of the track. New steps are
+// inactive, steps past length are dropped.
+func (track *Track) SetLength(length int) error {
+	if !supportedStepCount(length) {
+		return fmt.Errorf("%w: %d", ErrInvalidStepCount, length)
+	}
+
+	extra := make([]bool, length-StepCount)
+	copy(extra, track.ExtraSteps)
+	if len(extra) == 0 {
+		extra = nil
+	}
+	track.ExtraSteps = extra
+
+	return nil
+}
+
+// supportedStepCount returns whether n is one of SupportedStepCounts
+func supportedStepCount(n int) bool {
+	for _, count := range SupportedStepCounts {
+		if n == count {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HammingDistance returns the number of steps which differ between the two
+// tracks. Steps past the end of the shorter track count as inactive.
+func (track *Track) HammingDistance(other *Track) int {
+	distance := 0
+	for _, step := range track.XOR(other) {
+		if step {
+			distance++
+		}
+	}
+
+	return distance
+}
+
+// XOR returns the steps which differ between the two tracks
+func (track *Track) XOR(other *Track) []bool {
+	return combineSteps(track, other, func(a, b bool) bool { return a != b })
+}
+
+// OR returns the steps which are active in either track
+func (track *Track) OR(other *Track) []bool {
+	return combineSteps(track, other, func(a, b bool) bool { return a || b })
+}
+
+// AND returns the steps which are active in both tracks
+func (track *Track) AND(other *Track) []bool {
+	return combineSteps(track, other, func(a, b bool) bool { return a && b })
+}
+
+// combineSteps combines all steps of both tracks with fn. The result has the
+// length of the longer track, steps past the end of the shorter track count
+// as inactive.
+func combineSteps(track, other *Track, fn func(a, b bool) bool) []bool {
+	a, b := track.AllSteps(), other.AllSteps()
+	steps := make([]bool, max(len(a), len(b)))
+	for i := range steps {
+		steps[i] = fn(i < len(a) && a[i], i < len(b) && b[i])
+	}
+
+	return steps
+}
+
+// ActiveStepCount returns the number of active steps
+func (track *Track) ActiveStepCount() int {
+	count := 0
+	for _, step := range track.AllSteps() {
+		if step {
+			count++
+		}
+	}
+
+	return count
+}
+
+// formatSteps returns the steps as a string like "x---x---x---x---", the
+// format read by parseSteps.
+func formatSteps(steps []bool) string {
+	s := make([]byte, len(steps))
+	for i, step := range steps {
+		if step {
+			s[i] = 'x'
+		} else {
+			s[i] = '-'
+		}
+	}
+
+	return string(s)
+}
+
+// parseSteps parses a step string like "x---x---x---x---". An x marks an
+// active step and a - or . an inactive one, bar separators (|) and spaces are
+// ignored.
+func parseSteps(s string) ([16]bool, error) {
+	var steps [16]bool
+	values, err := parseStepSlice(s)
+	if err != nil {
+		return steps, err
+	}
+	if len(values) != len(steps) {
+		return steps, fmt.Errorf("%w: %q has %d steps, expected %d", ErrInvalidSteps, s, len(values), StepCount)
+	}
+	copy(steps[:], values)
+
+	return steps, nil
+}
+
+// parseStepSlice parses a step string like parseSteps, without checking the
+// number of steps.
+func parseStepSlice(s string) ([]bool, error) {
+	var steps []bool
+	for _, c := range s {
+		switch c {
+		case '|', ' ':
+			continue
+		case 'x', 'X', '-', '.':
+			steps = append(steps, c == 'x' || c == 'X')
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q in %q", ErrInvalidSteps, c, s)
+		}
+	}
+
+	return steps, nil
+}
+
+// setStepString sets all steps of the track from a step string like
+// parseSteps, changing the length of the track to the number of steps.
+func (track *Track) setStepString(s string) error {
+	steps, err := parseStepSlice(s)
+	if err != nil {
+		return err
+	}
+	if err := track.SetLength(len(steps)); err != nil {
+		return err
+	}
+	track.setAllSteps(steps)
+
+	return nil
+}
+
+// NthActiveStep returns the index of the n-th (0-based) active step
+func (track *Track) NthActiveStep(n int) (int, error) {
+	if n >= 0 {
+		for i, step := range track.AllSteps() {
+			if !step {
+				continue
+			}
+			if n == 0 {
+				return i, nil
+			}
+			n--
+		}
+	}
+
+	return 0, ErrStepNotFound
+}
+
+// FirstActiveStep returns the index of the first active step
+func (track *Track) FirstActiveStep() (int, bool) {
+	for i, step := range track.AllSteps() {
+		if step {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// LastActiveStep returns the index of the last active step
+func (track *Track) LastActiveStep() (int, bool) {
+	steps := track.AllSteps()
+	for i := len(steps) - 1; i >= 0; i-- {
+		if steps[i] {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// IsEmpty returns true if the track has no active steps
+func (track *Track) IsEmpty() bool {
+	return track.ActiveStepCount() == 0
+}
+
+// CopyStepsFrom copies the steps of other into the track, including its
+// extra steps, so the track gets the length of other.
+func (track *Track) CopyStepsFrom(other *Track) error {
+	if other == nil {
+		return ErrNilTrack
+	}
+	track.Steps = other.Steps
+	track.ExtraSteps = append([]bool(nil), other.ExtraSteps...)
+
+	return nil
+}
+
+// SetStep activates or silences step i, which can be one of the extra steps
+func (track *Track) SetStep(i int, on bool) error {
+	step, err := track.step(i)
+	if err != nil {
+		return err
+	}
+	*step = on
+
+	return nil
+}
+
+// ToggleStep flips step i, which can be one of the extra steps
+func (track *Track) ToggleStep(i int) error {
+	step, err := track.step(i)
+	if err != nil {
+		return err
+	}
+	*step = !*step
+
+	return nil
+}
+
+// step returns a pointer to step i in Steps or ExtraSteps
+func (track *Track) step(i int) (*bool, error) {
+	switch {
+	case i < 0 || i >= track.Length():
+		return nil, fmt.Errorf("%w: %d", ErrStepOutOfRange, i)
+	case i < StepCount:
+		return &track.Steps[i], nil
+	}
+
+	return &track.ExtraSteps[i-StepCount], nil
+}
+
+// ApplyMask silences every step which is not set in mask. Only the first
+// StepCount steps are masked, the extra steps of longer tracks are kept.
+func (track *Track) ApplyMask(mask [16]bool) {
+	for i := range track.Steps {
+		track.Steps[i] = track.Steps[i] && mask[i]
+	}
+}
+
+// ApplyGate activates every step which is set in gate. Only the first
+// StepCount steps are gated, the extra steps of longer tracks are kept.
+func (track *Track) ApplyGate(gate [16]bool) {
+	for i := range track.Steps {
+		track.Steps[i] = track.Steps[i] || gate[i]
+	}
+}
+
+// RepeatsEvery returns true if the steps consist of identical repetitions of
+// the first n steps. n has to divide the number of steps.
+func (track *Track) RepeatsEvery(n int) bool {
+	steps := track.AllSteps()
+	if n <= 0 || len(steps)%n != 0 {
+		return false
+	}
+	for i := n; i < len(steps); i++ {
+		if steps[i] != steps[i%n] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RepeatingUnit returns the length of the shortest unit the steps are a
+// repetition of. It returns the length of the track and false if the steps
+// don't repeat.
+func (track *Track) RepeatingUnit() (int, bool) {
+	for n := 1; n < track.Length(); n *= 2 {
+		if track.RepeatsEvery(n) {
+			return n, true
+		}
+	}
+
+	return track.Length(), false
+}
+
+// OnsetIntervals returns the number of steps between consecutive active
+// steps, wrapping around from the last active step to the first one. It
+// returns nil if no steps are active.
+func (track *Track) OnsetIntervals() []int {
+	steps := track.AllSteps()
+	var onsets []int
+	for i, step := range steps {
+		if step {
+			onsets = append(onsets, i)
+		}
+	}
+	if len(onsets) == 0 {
+		return nil
+	}
+
+	intervals := make([]int, len(onsets))
+	for i, onset := range onsets {
+		next := onsets[(i+1)%len(onsets)]
+		intervals[i] = (next-onset+len(steps)-1)%len(steps) + 1
+	}
+
+	return intervals
+}
+
+// SymmetryScore returns the fraction of the rotations by 1 to length-1 steps
+// which leave the steps unchanged. Tracks with all steps active or inactive
+// score 1.
+func (track *Track) SymmetryScore() float64 {
+	steps := track.AllSteps()
+	matches := 0
+	for n := 1; n < len(steps); n++ {
+		if slices.Equal(rotateSteps(steps, n), steps) {
+			matches++
+		}
+	}
+
+	return float64(matches) / float64(len(steps)-1)
+}
+
+// Rotate rotates all steps of the track right by n steps, wrapping around.
+// Negative values rotate left.
+func (track *Track) Rotate(n int) {
+	track.setAllSteps(rotateSteps(track.AllSteps(), n))
+}
+
+// PhaseShift shifts the track by a fractional number of steps. The steps are
+// rotated by the nearest whole number of steps and the remaining fraction is
+// kept in PhaseOffset, so consecutive shifts add up.
+func (track *Track) PhaseShift(steps float32) {
+	total := float64(track.PhaseOffset) + float64(steps)
+	whole := math.RoundToEven(total)
+
+	track.Rotate(int(whole))
+	track.PhaseOffset = float32(total - whole)
+}
+
+// rotateSteps returns the steps rotated right by n steps, wrapping around
+func rotateSteps(steps []bool, n int) []bool {
+	rotated := make([]bool, len(steps))
+	for i, step := range steps {
+		j := ((i+n)%len(steps) + len(steps)) % len(steps)
+		rotated[j] = step
+	}
+
+	return rotated
+}
+
+// metricWeights are the Longuet-Higgins and Lee metrical weights of the steps
+// of a 4/4 bar: 0 for the downbeat, down to -4 for the 16th note off-beats.
+// Every bar of longer tracks uses the same weights.
+var metricWeights = [StepCount]int{0, -4, -3, -4, -2, -4, -3, -4, -1, -4, -3, -4, -2, -4, -3, -4}
+
+// maxSyncopation is the highest possible syncopation of a bar, which is
+// reached when only the 16th note off-beats are active.
+var maxSyncopation = syncopation([]bool{1: true, 3: true, 5: true, 7: true, 9: true, 11: true, 13: true, 15: true})
+
+// SyncopationScore returns the Longuet-Higgins and Lee (1982) syncopation of
+// the track, normalized to [0, 1]. Every active step followed by silence on a
+// metrically stronger step before the next active step adds the difference
+// between their weights. The track is treated as a loop.
+func (track *Track) SyncopationScore() float64 {
+	bars := track.Length() / StepCount
+	return float64(syncopation(track.AllSteps())) / float64(bars*maxSyncopation)
+}
+
+func syncopation(steps []bool) int {
+	var onsets []int
+	for i, step := range steps {
+		if step {
+			onsets = append(onsets, i)
+		}
+	}
+
+	total := 0
+	for i, onset := range onsets {
+		gap := (onsets[(i+1)%len(onsets)]-onset+len(steps)-1)%len(steps) + 1
+
+		strongest := metricWeights[onset%StepCount]
+		for j := 1; j < gap; j++ {
+			if weight := metricWeights[(onset+j)%len(steps)%StepCount]; weight > strongest {
+				strongest = weight
+			}
+		}
+		total += strongest - metricWeights[onset%StepCount]
+	}
+
+	return total
+}
+
+// StepRunLengths returns the lengths of the runs of consecutive active and
+// inactive steps, in the order they appear.
+func (track *Track) StepRunLengths() (activeRuns, inactiveRuns []int) {
+	steps := track.AllSteps()
+	for i, step := range steps {
+		if i > 0 && step == steps[i-1] {
+			if step {
+				activeRuns[len(activeRuns)-1]++
+			} else {
+				inactiveRuns[len(inactiveRuns)-1]++
+			}
+			continue
+		}
+
+		if step {
+			activeRuns = append(activeRuns, 1)
+		} else {
+			inactiveRuns = append(inactiveRuns, 1)
+		}
+	}
+
+	return activeRuns, inactiveRuns
+}
+
+// Density returns the fraction of active steps, from 0 to 1
+func (track *Track) Density() float64 {
+	return float64(track.ActiveStepCount()) / float64(track.Length())
+}
+
+// Materialize returns a copy of the track where every step is active with
+// the probability in ProbabilitySteps, using a random generator seeded with
+// seed. Longer tracks repeat the probabilities of the first bar.
+func (track *Track) Materialize(seed int64) Track {
+	materialized := *track.Clone()
+	r := rand.New(rand.NewSource(seed))
+	steps := make([]bool, track.Length())
+	for i := range steps {
+		steps[i] = r.Float32() < track.ProbabilitySteps[i%StepCount]
+	}
+	materialized.setAllSteps(steps)
+
+	return materialized
+}
+
+// QuantizeToScale snaps the MIDI note of the track to the nearest note of the
+// scale, preferring the lower note on a tie, and returns the steps playing
+// the quantized note. A track has a single note, so all active steps snap to
+// the same scale note.
+func (track *Track) QuantizeToScale(scale []int) ([StepCount]bool, error) {
+	if track.MIDINote == 0 {
+		return [StepCount]bool{}, ErrMIDINoteNotSet
+	}
+	if len(scale) == 0 {
+		return [StepCount]bool{}, ErrEmptyScale
+	}
+
+	nearest := scale[0]
+	for _, note := range scale[1:] {
+		distance, best := abs(note-track.MIDINote), abs(nearest-track.MIDINote)
+		if distance < best || distance == best && note < nearest {
+			nearest = note
+		}
+	}
+	track.MIDINote = nearest
+
+	return track.Steps, nil
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,527 @@
+package drum
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNewTrack(t *testing.T) {
+	tData := []struct {
+		id   int
+		name string
+		err  error
+	}{
+		{0, "kick", nil},
+		{-1, "kick", ErrInvalidTrackID},
+		{1, "", ErrEmptyTrackName},
+		{1, strings.Repeat("a", 127), nil},
+		{1, strings.Repeat("a", 128), ErrTrackNameTooLong},
+	}
+
+	for _, exp := range tData {
+		track, err := NewTrack(exp.id, exp.name, [16]bool{})
+		if err != exp.err {
+			t.Fatalf("NewTrack(%d, %q): expected error %v, got %v", exp.id, exp.name, exp.err, err)
+		}
+		if err == nil && (track.ID != exp.id || track.Name != exp.name) {
+			t.Fatalf("NewTrack(%d, %q): got track %v", exp.id, exp.name, track)
+		}
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	kick := &Track{Steps: [16]bool{0: true, 4: true, 8: true, 12: true}}
+	snare := &Track{Steps: [16]bool{4: true, 12: true}}
+
+	var full Track
+	for i := range full.Steps {
+		full.Steps[i] = true
+	}
+
+	tData := []struct {
+		a, b     *Track
+		distance int
+	}{
+		{kick, kick, 0},
+		{kick, snare, 2},
+		{&Track{}, &full, 16},
+	}
+
+	for _, exp := range tData {
+		if distance := exp.a.HammingDistance(exp.b); distance != exp.distance {
+			t.Fatalf("expected distance %d, got %d", exp.distance, distance)
+		}
+	}
+}
+
+func TestXOR(t *testing.T) {
+	kick := &Track{Steps: [16]bool{0: true, 4: true, 8: true, 12: true}}
+	snare := &Track{Steps: [16]bool{4: true, 12: true}}
+
+	if steps := formatSteps(kick.XOR(kick)); steps != "----------------" {
+		t.Fatalf("expected no differences, got %s", steps)
+	}
+	if steps := formatSteps(kick.XOR(snare)); steps != "x-------x-------" {
+		t.Fatalf("expected differences at 0 and 8, got %s", steps)
+	}
+}
+
+func TestORAND(t *testing.T) {
+	// Steps 0-3 cover the combinations (false, false), (false, true),
+	// (true, false) and (true, true)
+	a := &Track{Steps: [16]bool{2: true, 3: true}}
+	b := &Track{Steps: [16]bool{1: true, 3: true}}
+
+	if steps := formatSteps(a.OR(b)); steps != "-xxx------------" {
+		t.Fatalf("unexpected OR result %s", steps)
+	}
+	if steps := formatSteps(a.AND(b)); steps != "---x------------" {
+		t.Fatalf("unexpected AND result %s", steps)
+	}
+	if formatSteps(a.OR(a)) != formatSteps(a.Steps[:]) || formatSteps(a.AND(a)) != formatSteps(a.Steps[:]) {
+		t.Fatalf("combining a track with itself should return its steps")
+	}
+}
+
+func TestNthActiveStep(t *testing.T) {
+	track := &Track{Steps: [16]bool{2: true, 6: true, 8: true, 10: true, 14: true}}
+
+	tData := []struct {
+		n     int
+		index int
+		err   error
+	}{
+		{0, 2, nil},
+		{2, 8, nil},
+		{4, 14, nil},
+		{5, 0, ErrStepNotFound},
+		{-1, 0, ErrStepNotFound},
+	}
+
+	for _, exp := range tData {
+		index, err := track.NthActiveStep(exp.n)
+		if index != exp.index || err != exp.err {
+			t.Fatalf("NthActiveStep(%d): expected (%d, %v), got (%d, %v)", exp.n, exp.index, exp.err, index, err)
+		}
+	}
+}
+
+func TestFirstLastActiveStep(t *testing.T) {
+	var allOn [16]bool
+	for i := range allOn {
+		allOn[i] = true
+	}
+
+	tData := []struct {
+		steps       [16]bool
+		first, last int
+		found       bool
+	}{
+		{[16]bool{}, 0, 0, false},
+		{allOn, 0, 15, true},
+		{[16]bool{5: true}, 5, 5, true},
+	}
+
+	for _, exp := range tData {
+		track := &Track{Steps: exp.steps}
+		if first, found := track.FirstActiveStep(); first != exp.first || found != exp.found {
+			t.Fatalf("%v: expected first (%d, %v), got (%d, %v)", exp.steps, exp.first, exp.found, first, found)
+		}
+		if last, found := track.LastActiveStep(); last != exp.last || found != exp.found {
+			t.Fatalf("%v: expected last (%d, %v), got (%d, %v)", exp.steps, exp.last, exp.found, last, found)
+		}
+	}
+}
+
+func TestRepeatingUnit(t *testing.T) {
+	tData := []struct {
+		steps string
+		unit  int
+		found bool
+	}{
+		{"----------------", 1, true},
+		{"x-x-x-x-x-x-x-x-", 2, true},
+		{"x---x---x---x---", 4, true},
+		{"x--x-x--x--x-x--", 8, true},
+		{"x---x---x---x--x", 16, false},
+	}
+
+	for _, exp := range tData {
+		steps, err := parseSteps(exp.steps)
+		if err != nil {
+			t.Fatalf("invalid steps %q - %v", exp.steps, err)
+		}
+		track := &Track{Steps: steps}
+		if unit, found := track.RepeatingUnit(); unit != exp.unit || found != exp.found {
+			t.Fatalf("%s: expected (%d, %v), got (%d, %v)", exp.steps, exp.unit, exp.found, unit, found)
+		}
+	}
+}
+
+func TestOnsetIntervals(t *testing.T) {
+	tData := []struct {
+		steps     string
+		intervals []int
+	}{
+		{"----------------", nil},
+		{"x---x---x-------", []int{4, 4, 8}},
+		{"--x-------x----x", []int{8, 5, 3}},
+		{"-----x----------", []int{16}},
+		{"xx--------------", []int{1, 15}},
+	}
+
+	for _, exp := range tData {
+		steps, _ := parseSteps(exp.steps)
+		track := &Track{Steps: steps}
+		intervals := track.OnsetIntervals()
+		if fmt.Sprint(intervals) != fmt.Sprint(exp.intervals) || (intervals == nil) != (exp.intervals == nil) {
+			t.Fatalf("%s: expected %v, got %v", exp.steps, exp.intervals, intervals)
+		}
+	}
+}
+
+func TestSymmetryScore(t *testing.T) {
+	tData := []struct {
+		steps string
+		score float64
+	}{
+		{"xxxxxxxxxxxxxxxx", 1},
+		{"----------------", 1},
+		{"x---------------", 0},
+		{"x-x-x-x-x-x-x-x-", 7.0 / 15},
+		{"x---x---x---x---", 3.0 / 15},
+	}
+
+	for _, exp := range tData {
+		steps, _ := parseSteps(exp.steps)
+		track := &Track{Steps: steps}
+		if score := track.SymmetryScore(); score != exp.score {
+			t.Fatalf("%s: expected %g, got %g", exp.steps, exp.score, score)
+		}
+	}
+}
+
+func TestSyncopationScore(t *testing.T) {
+	tData := []struct {
+		steps string
+		score float64
+	}{
+		{"----------------", 0},
+		{"x---x---x---x---", 0},
+		{"-x-x-x-x-x-x-x-x", 1},
+		{"--x---x---x---x-", 7.0 / 15},
+	}
+
+	for _, exp := range tData {
+		steps, _ := parseSteps(exp.steps)
+		track := &Track{Steps: steps}
+		if score := track.SyncopationScore(); score != exp.score {
+			t.Fatalf("%s: expected %g, got %g", exp.steps, exp.score, score)
+		}
+	}
+
+	// Every possible pattern stays within [0, 1]
+	for bits := 0; bits < 1<<16; bits++ {
+		var track Track
+		for i := range track.Steps {
+			track.Steps[i] = bits&(1<<uint(i)) != 0
+		}
+		if score := track.SyncopationScore(); score < 0 || score > 1 {
+			t.Fatalf("%s: score %g out of range", &track, score)
+		}
+	}
+}
+
+func TestStepRunLengths(t *testing.T) {
+	tData := []struct {
+		steps            string
+		active, inactive []int
+	}{
+		{"x-xx-x----------", []int{1, 2, 1}, []int{1, 1, 10}},
+		{"----xxxx--------", []int{4}, []int{4, 8}},
+		{"xxxxxxxxxxxxxxxx", []int{16}, nil},
+	}
+
+	for _, exp := range tData {
+		steps, _ := parseSteps(exp.steps)
+		track := &Track{Steps: steps}
+		active, inactive := track.StepRunLengths()
+		if fmt.Sprint(active) != fmt.Sprint(exp.active) || fmt.Sprint(inactive) != fmt.Sprint(exp.inactive) {
+			t.Fatalf("%s: expected %v and %v, got %v and %v", exp.steps, exp.active, exp.inactive, active, inactive)
+		}
+	}
+}
+
+func TestMaterialize(t *testing.T) {
+	track := &Track{ID: 1, Name: "hh"}
+	for i := range track.ProbabilitySteps {
+		track.ProbabilitySteps[i] = 1
+	}
+	for seed := int64(0); seed < 10; seed++ {
+		if materialized := track.Materialize(seed); materialized.ActiveStepCount() != StepCount {
+			t.Fatalf("expected all steps to be active with seed %d, got %s", seed, formatSteps(materialized.Steps[:]))
+		}
+	}
+
+	track.ProbabilitySteps = [16]float32{0: 1, 1: 0.5}
+	first, second := track.Materialize(42), track.Materialize(42)
+	if first.Steps != second.Steps {
+		t.Fatalf("expected the same steps for the same seed, got %s and %s", formatSteps(first.Steps[:]), formatSteps(second.Steps[:]))
+	}
+	if !first.Steps[0] || first.ActiveStepCount() > 2 {
+		t.Fatalf("unexpected steps %s", formatSteps(first.Steps[:]))
+	}
+	if first.Name != "hh" || track.ActiveStepCount() != 0 {
+		t.Fatalf("expected a copy of the track")
+	}
+}
+
+func TestQuantizeToScale(t *testing.T) {
+	pentatonic := []int{36, 38, 40, 43, 45, 48}
+	tests := []struct {
+		note     int
+		expected int
+	}{
+		{37, 36},
+		{39, 38},
+		{42, 43},
+		{46, 45},
+		{60, 48},
+		{30, 36},
+	}
+
+	for _, test := range tests {
+		track := &Track{ID: 1, Name: "rim", MIDINote: test.note, Steps: [16]bool{4: true, 12: true}}
+		steps, err := track.QuantizeToScale(pentatonic)
+		if err != nil {
+			t.Fatalf("something went wrong quantizing %d - %v", test.note, err)
+		}
+		if track.MIDINote != test.expected {
+			t.Fatalf("expected %d to snap to %d, got %d", test.note, test.expected, track.MIDINote)
+		}
+		if steps != track.Steps {
+			t.Fatalf("expected steps %s, got %s", formatSteps(track.Steps[:]), formatSteps(steps[:]))
+		}
+	}
+
+	if _, err := (&Track{Name: "rim"}).QuantizeToScale(pentatonic); err != ErrMIDINoteNotSet {
+		t.Fatalf("expected ErrMIDINoteNotSet, got %v", err)
+	}
+	if _, err := (&Track{Name: "rim", MIDINote: 37}).QuantizeToScale(nil); err != ErrEmptyScale {
+		t.Fatalf("expected ErrEmptyScale, got %v", err)
+	}
+}
+
+func TestPhaseShift(t *testing.T) {
+	steps := [16]bool{0: true, 3: true, 8: true}
+	tests := []struct {
+		shifts   []float32
+		expected string
+		offset   float32
+	}{
+		{[]float32{0}, "x--x----x-------", 0},
+		{[]float32{0.25}, "x--x----x-------", 0.25},
+		{[]float32{1}, "-x--x----x------", 0},
+		{[]float32{-1.25}, "--x----x-------x", -0.25},
+		{[]float32{0.25, 0.5}, "-x--x----x------", -0.25},
+		{[]float32{0.5, 0.5}, "-x--x----x------", 0},
+	}
+
+	for _, test := range tests {
+		track := &Track{ID: 1, Name: "kick", Steps: steps}
+		for _, shift := range test.shifts {
+			track.PhaseShift(shift)
+		}
+		if formatSteps(track.Steps[:]) != test.expected || track.PhaseOffset != test.offset {
+			t.Fatalf("shifting by %v: expected %s with offset %g, got %s with offset %g",
+				test.shifts, test.expected, test.offset, formatSteps(track.Steps[:]), track.PhaseOffset)
+		}
+	}
+}
+
+func TestSetToggleStep(t *testing.T) {
+	track := &Track{ID: 0, Name: "kick"}
+
+	if err := track.SetStep(4, true); err != nil {
+		t.Fatalf("something went wrong setting a step - %v", err)
+	}
+	if err := track.ToggleStep(0); err != nil {
+		t.Fatalf("something went wrong toggling a step - %v", err)
+	}
+	if err := track.ToggleStep(4); err != nil {
+		t.Fatalf("something went wrong toggling a step - %v", err)
+	}
+	if steps := formatSteps(track.Steps[:]); steps != "x---------------" {
+		t.Fatalf("expected x---------------, got %s", steps)
+	}
+
+	for _, i := range []int{-1, 16} {
+		if err := track.SetStep(i, true); !errors.Is(err, ErrStepOutOfRange) {
+			t.Fatalf("SetStep(%d): expected ErrStepOutOfRange, got %v", i, err)
+		}
+		if err := track.ToggleStep(i); !errors.Is(err, ErrStepOutOfRange) {
+			t.Fatalf("ToggleStep(%d): expected ErrStepOutOfRange, got %v", i, err)
+		}
+	}
+}
+
+func TestCopyStepsFrom(t *testing.T) {
+	source := &Track{ID: 1, Name: "kick", Steps: [16]bool{0: true, 8: true}}
+	track := &Track{ID: 2, Name: "snare", Steps: [16]bool{4: true}}
+
+	if err := track.CopyStepsFrom(source); err != nil {
+		t.Fatalf("something went wrong copying steps - %v", err)
+	}
+	if track.Steps != source.Steps || track.ID != 2 || track.Name != "snare" {
+		t.Fatalf("expected only the steps to be copied, got %s", track)
+	}
+
+	// The steps are copied by value
+	source.SetStep(4, true)
+	if track.Steps[4] {
+		t.Fatalf("changing the source changed the copied steps")
+	}
+
+	if err := track.CopyStepsFrom(nil); !errors.Is(err, ErrNilTrack) {
+		t.Fatalf("expected ErrNilTrack, got %v", err)
+	}
+}
+
+func TestLongTrackSteps(t *testing.T) {
+	track := &Track{Steps: [16]bool{0: true}}
+	track.SetLength(32)
+	track.SetStep(31, true)
+	if track.ActiveStepCount() != 2 || track.Density() != 2.0/32 || track.IsEmpty() {
+		t.Fatalf("expected 2 of 32 active steps, got %d (%g)", track.ActiveStepCount(), track.Density())
+	}
+
+	track.Rotate(1)
+	if steps := formatSteps(track.AllSteps()); steps != "xx------------------------------" {
+		t.Fatalf("expected step 31 to wrap around to step 0, got %s", steps)
+	}
+
+	copied := &Track{}
+	if err := copied.CopyStepsFrom(track); err != nil {
+		t.Fatalf("something went wrong copying steps - %v", err)
+	}
+	copied.SetStep(20, true)
+	if copied.Length() != 32 || track.ExtraSteps[4] {
+		t.Fatalf("expected a copy of the 32 steps, got %s", copied)
+	}
+}
+
+func TestLongTrackHelpers(t *testing.T) {
+	long := func(s string) *Track {
+		track := &Track{}
+		if err := track.setStepString(s); err != nil {
+			t.Fatalf("something went wrong parsing %s - %v", s, err)
+		}
+		return track
+	}
+	track := long("x--------------- ----x-----------")
+	short := long("x---------------")
+
+	if distance := track.HammingDistance(short); distance != 1 {
+		t.Fatalf("expected distance 1, got %d", distance)
+	}
+	if steps := formatSteps(track.XOR(short)); steps != "--------------------x-----------" {
+		t.Fatalf("unexpected XOR result %s", steps)
+	}
+	if steps := formatSteps(short.OR(track)); steps != "x-------------------x-----------" {
+		t.Fatalf("unexpected OR result %s", steps)
+	}
+	if steps := formatSteps(track.AND(short)); steps != "x-------------------------------" {
+		t.Fatalf("unexpected AND result %s", steps)
+	}
+
+	if step, err := track.NthActiveStep(1); err != nil || step != 20 {
+		t.Fatalf("expected step 20 to be the second active step, got %d (%v)", step, err)
+	}
+	if step, ok := track.FirstActiveStep(); !ok || step != 0 {
+		t.Fatalf("expected the first active step at 0, got %d", step)
+	}
+	if step, ok := track.LastActiveStep(); !ok || step != 20 {
+		t.Fatalf("expected the last active step at 20, got %d", step)
+	}
+	if intervals := track.OnsetIntervals(); fmt.Sprint(intervals) != "[20 12]" {
+		t.Fatalf("expected intervals [20 12], got %v", intervals)
+	}
+	if active, inactive := track.StepRunLengths(); fmt.Sprint(active, inactive) != "[1 1] [19 11]" {
+		t.Fatalf("expected runs [1 1] and [19 11], got %v and %v", active, inactive)
+	}
+
+	if track.RepeatsEvery(16) {
+		t.Fatalf("expected step 20 to break the repetition")
+	}
+	if n, ok := track.RepeatingUnit(); ok || n != 32 {
+		t.Fatalf("expected no repeating unit, got %d", n)
+	}
+	repeated := long("x--------------- x---------------")
+	if n, ok := repeated.RepeatingUnit(); !ok || n != 16 {
+		t.Fatalf("expected a repeating unit of 16 steps, got %d", n)
+	}
+	if score := repeated.SymmetryScore(); score != 1.0/31 {
+		t.Fatalf("expected symmetry score 1/31, got %g", score)
+	}
+
+	if score := long("-x-x-x-x-x-x-x-x -x-x-x-x-x-x-x-x").SyncopationScore(); score != 1 {
+		t.Fatalf("expected syncopation score 1, got %g", score)
+	}
+	if score := long("-x-x-x-x-x-x-x-x ----------------").SyncopationScore(); score != 0.5 {
+		t.Fatalf("expected syncopation score 0.5, got %g", score)
+	}
+
+	for i := range track.ProbabilitySteps {
+		track.ProbabilitySteps[i] = 1
+	}
+	if materialized := track.Materialize(42); materialized.ActiveStepCount() != 32 {
+		t.Fatalf("expected all 32 steps to be active, got %s", &materialized)
+	}
+}
+
+func TestApplyMaskGate(t *testing.T) {
+	tData := []struct {
+		steps, mask, masked, gated string
+	}{
+		{"xx--xx--xx--xx--", "x-x-x-x-x-x-x-x-", "x---x---x---x---", "xxx-xxx-xxx-xxx-"},
+		{"xx--xx--xx--xx--", "----------------", "----------------", "xx--xx--xx--xx--"},
+		{"xx--xx--xx--xx--", "xxxxxxxxxxxxxxxx", "xx--xx--xx--xx--", "xxxxxxxxxxxxxxxx"},
+		{"----------------", "xxxxxxxxxxxxxxxx", "----------------", "xxxxxxxxxxxxxxxx"},
+	}
+
+	for _, exp := range tData {
+		steps, _ := parseSteps(exp.steps)
+		mask, _ := parseSteps(exp.mask)
+
+		masked := &Track{Steps: steps}
+		masked.ApplyMask(mask)
+		if s := formatSteps(masked.Steps[:]); s != exp.masked {
+			t.Fatalf("%s masked with %s: expected %s, got %s", exp.steps, exp.mask, exp.masked, s)
+		}
+		gated := &Track{Steps: steps}
+		gated.ApplyGate(mask)
+		if s := formatSteps(gated.Steps[:]); s != exp.gated {
+			t.Fatalf("%s gated with %s: expected %s, got %s", exp.steps, exp.mask, exp.gated, s)
+		}
+	}
+
+	// The extra steps of longer tracks are kept
+	track := &Track{}
+	track.SetLength(32)
+	track.SetStep(20, true)
+	track.ApplyMask([16]bool{})
+	track.ApplyGate([16]bool{0: true})
+	if steps := formatSteps(track.AllSteps()); steps != "x-------------------x-----------" {
+		t.Fatalf("expected step 0 and 20 to be active, got %s", steps)
+	}
+
+	var mask [16]bool
+	if allocs := testing.AllocsPerRun(100, func() {
+		track.ApplyMask(mask)
+		track.ApplyGate(mask)
+	}); allocs != 0 {
+		t.Fatalf("expected no allocations, got %g", allocs)
+	}
+}
@@ -0,0 +1,106 @@
+package drum
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// The transforms in this file work on all steps of a track, including the
+// extra steps of patterns longer than StepCount steps.
+
+// setAllSteps sets the steps and extra steps of the track from steps, which
+// has the length of the track.
+func (track *Track) setAllSteps(steps []bool) {
+	copy(track.Steps[:], steps)
+	copy(track.ExtraSteps, steps[StepCount:])
+}
+
+// Shift moves the steps n steps later, or earlier for a negative n. Unlike
+// Rotate, steps moved past the start or end are dropped.
+func (track *Track) Shift(n int) {
+	steps := track.AllSteps()
+	shifted := make([]bool, len(steps))
+	for i, step := range steps {
+		if j := i + n; j >= 0 && j < len(shifted) {
+			shifted[j] = step
+		}
+	}
+
+	track.setAllSteps(shifted)
+}
+
+// Reverse reverses the order of the steps
+func (track *Track) Reverse() {
+	steps := track.AllSteps()
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+
+	track.setAllSteps(steps)
+}
+
+// Invert activates the inactive steps and silences the active ones
+func (track *Track) Invert() {
+	steps := track.AllSteps()
+	for i := range steps {
+		steps[i] = !steps[i]
+	}
+
+	track.setAllSteps(steps)
+}
+
+// FillEuclidean replaces the steps with an euclidean rhythm of hits active
+// steps, see Euclidean.
+func (track *Track) FillEuclidean(hits int) error {
+	steps, err := Euclidean(hits, track.Length())
+	if err != nil {
+		return err
+	}
+	track.setAllSteps(steps)
+
+	return nil
+}
+
+// Euclidean returns the euclidean rhythm distributing hits active steps as
+// evenly as possible over steps, starting with an active step. For example
+// Euclidean(3, 8) is x--x--x-.
+func Euclidean(hits, steps int) ([]bool, error) {
+	if steps <= 0 || hits < 0 || hits > steps {
+		return nil, fmt.Errorf("%w: %d hits in %d steps", ErrStepOutOfRange, hits, steps)
+	}
+
+	rhythm := make([]bool, steps)
+	for i := range rhythm {
+		rhythm[i] = i*hits%steps < hits
+	}
+
+	return rhythm, nil
+}
+
+// Humanize returns a copy of the pattern where every active step is moved to
+// the previous or next step with probability prob, if that step is
+// inactive. The same seed gives the same result.
+func (pattern *Pattern) Humanize(seed int64, prob float64) *Pattern {
+	humanized := pattern.Clone()
+	r := rand.New(rand.NewSource(seed))
+	for _, track := range humanized.Tracks {
+		// Decide on the original steps, so moved steps aren't moved again
+		steps := track.AllSteps()
+		for i, step := range track.AllSteps() {
+			if !step || r.Float64() >= prob {
+				continue
+			}
+
+			j := i - 1
+			if r.Intn(2) == 1 {
+				j = i + 1
+			}
+			if j >= 0 && j < len(steps) && !steps[j] {
+				steps[i], steps[j] = false, true
+			}
+		}
+		track.setAllSteps(steps)
+	}
+
+	return humanized
+}
@@ -0,0 +1,125 @@
+package drum
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestShift(t *testing.T) {
+	tData := []struct {
+		n     int
+		steps string
+	}{
+		{0, "x---x---x---x--x"},
+		{1, "-x---x---x---x--"},
+		{-1, "---x---x---x--x-"},
+		{4, "----x---x---x---"},
+		{16, "----------------"},
+	}
+
+	for _, exp := range tData {
+		track := &Track{Steps: [16]bool{0: true, 4: true, 8: true, 12: true, 15: true}}
+		track.Shift(exp.n)
+		if steps := formatSteps(track.Steps[:]); steps != exp.steps {
+			t.Fatalf("Shift(%d): expected %s, got %s", exp.n, exp.steps, steps)
+		}
+	}
+
+	// Steps move between Steps and ExtraSteps
+	track := &Track{Steps: [16]bool{15: true}}
+	track.SetLength(32)
+	track.Shift(2)
+	if track.Steps[15] || !track.ExtraSteps[1] {
+		t.Fatalf("expected step 15 to move to step 17, got %s", track)
+	}
+}
+
+func TestReverseInvert(t *testing.T) {
+	track := &Track{Steps: [16]bool{0: true, 1: true, 6: true}}
+
+	track.Reverse()
+	if steps := formatSteps(track.Steps[:]); steps != "---------x----xx" {
+		t.Fatalf("expected ---------x----xx, got %s", steps)
+	}
+	track.Invert()
+	if steps := formatSteps(track.Steps[:]); steps != "xxxxxxxxx-xxxx--" {
+		t.Fatalf("expected xxxxxxxxx-xxxx--, got %s", steps)
+	}
+}
+
+func TestEuclidean(t *testing.T) {
+	tData := []struct {
+		hits, steps int
+		rhythm      string
+	}{
+		{3, 8, "x--x--x-"},
+		{4, 16, "x---x---x---x---"},
+		{5, 16, "x---x--x--x--x--"},
+		{0, 4, "----"},
+		{4, 4, "xxxx"},
+	}
+
+	for _, exp := range tData {
+		rhythm, err := Euclidean(exp.hits, exp.steps)
+		if err != nil {
+			t.Fatalf("Euclidean(%d, %d): something went wrong - %v", exp.hits, exp.steps, err)
+		}
+		s := ""
+		for _, step := range rhythm {
+			if step {
+				s += "x"
+			} else {
+				s += "-"
+			}
+		}
+		if s != exp.rhythm {
+			t.Fatalf("Euclidean(%d, %d): expected %s, got %s", exp.hits, exp.steps, exp.rhythm, s)
+		}
+	}
+
+	for _, args := range [][2]int{{5, 4}, {-1, 4}, {0, 0}} {
+		if _, err := Euclidean(args[0], args[1]); !errors.Is(err, ErrStepOutOfRange) {
+			t.Fatalf("Euclidean(%d, %d): expected ErrStepOutOfRange, got %v", args[0], args[1], err)
+		}
+	}
+
+	track := &Track{ID: 0, Name: "kick"}
+	if err := track.FillEuclidean(4); err != nil {
+		t.Fatalf("something went wrong filling - %v", err)
+	}
+	if steps := formatSteps(track.Steps[:]); steps != "x---x---x---x---" {
+		t.Fatalf("expected x---x---x---x---, got %s", steps)
+	}
+}
+
+func TestHumanize(t *testing.T) {
+	pattern := testPattern()
+
+	if humanized := pattern.Humanize(1, 0); humanized.String() != pattern.String() {
+		t.Fatalf("expected no changes with probability 0, got:\n%s", humanized)
+	}
+
+	humanized := pattern.Humanize(1, 1)
+	if humanized.String() == pattern.String() {
+		t.Fatalf("expected changes with probability 1")
+	}
+	if humanized.String() != pattern.Humanize(1, 1).String() {
+		t.Fatalf("expected the same result for the same seed")
+	}
+	if pattern.String() != testPattern().String() {
+		t.Fatalf("humanizing changed the original pattern")
+	}
+	for i, track := range humanized.Tracks {
+		if track.ActiveStepCount() != pattern.Tracks[i].ActiveStepCount() {
+			t.Fatalf("expected humanizing to keep the number of active steps, got:\n%s", humanized)
+		}
+		for j, step := range track.Steps {
+			moved := pattern.Tracks[i].Steps[j] ||
+				j > 0 && pattern.Tracks[i].Steps[j-1] ||
+				j < StepCount-1 && pattern.Tracks[i].Steps[j+1]
+			if step && !moved {
+				t.Fatalf("step %d of %s moved more than one step", j, track.Name)
+			}
+		}
+	}
+}
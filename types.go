@@ -1,16 +1,45 @@
 package drum
 
+// StepCount is the number of steps in a track. Longer patterns store the
+// steps after the first StepCount steps in Track.ExtraSteps.
+const StepCount = 16
+
+// SupportedStepCounts are the step counts of the patterns the drum machine
+// can store, every track of a pattern has the same step count.
+var SupportedStepCounts = []int{16, 32, 64}
+
+// StepsPerBeat is the number of steps in a single beat (16th notes)
+const StepsPerBeat = 4
+
 // Pattern is the high level representation of the
 // drum pattern contained in a .splice file.
 type Pattern struct {
 	Version string
-	Tempo   float32
-	Tracks  []*Track
+	// Tempo in beats per minute, also available through BPM and SetBPM.
+	Tempo  float32
+	Tracks []*Track
 }
 
 // Track is a representation of a single track in a pattern
 type Track struct {
 	ID    int
 	Name  string
-	Steps [16]bool
+	Steps [StepCount]bool
+	// ExtraSteps holds the steps following Steps in patterns longer than
+	// StepCount steps, it is empty for 16 step patterns. Functions taking or
+	// returning a [StepCount]bool, like ApplyMask, only use Steps.
+	ExtraSteps []bool
+	// MIDINote is the MIDI note played by the track, 0 means unassigned.
+	// It is not stored in .splice files.
+	MIDINote int
+	// Velocities holds an optional MIDI velocity (1-127) per step, 0 means
+	// the exporter default. It is only stored in extended .splice files.
+	Velocities [StepCount]uint8
+	// ProbabilitySteps holds the probability (0.0-1.0) of every step to fire
+	// when the track is materialized. It is only stored in extended .splice
+	// files, as a uint8 per step.
+	ProbabilitySteps [StepCount]float32
+	// PhaseOffset is the fractional step offset (-0.5 to 0.5) left over by
+	// PhaseShift. It is not stored in .splice files.
+	PhaseOffset float32
 }